@@ -0,0 +1,19 @@
+-- Zero-knowledge mode: the CLI encrypts values before pushing them
+ALTER TABLE "vaults" ADD COLUMN IF NOT EXISTS "e2e_enabled" boolean DEFAULT false NOT NULL;
+
+-- Per-member copies of the vault key, sealed to each member's public key
+CREATE TABLE IF NOT EXISTS "vault_keys" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+  "vault_id" uuid NOT NULL REFERENCES "vaults"("id") ON DELETE CASCADE,
+  "user_id" uuid NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "public_key" text NOT NULL,
+  "wrapped_key" text,
+  "created_at" timestamp DEFAULT now() NOT NULL,
+  "updated_at" timestamp DEFAULT now() NOT NULL,
+  CONSTRAINT "vault_keys_vault_user_unique" UNIQUE("vault_id", "user_id")
+);
+
+CREATE INDEX IF NOT EXISTS "vault_keys_vault_id_idx" ON "vault_keys" ("vault_id");
+
+-- Audit trail for turning zero-knowledge mode on
+ALTER TYPE "activity_action" ADD VALUE IF NOT EXISTS 'vault_e2e_enabled';
@@ -892,6 +892,13 @@ export async function integrationsRoutes(fastify: FastifyInstance) {
         repo
       );
 
+      // The server only holds ciphertext for zero-knowledge vaults
+      if (vault.e2eEnabled) {
+        throw new BadRequestError(
+          "Provider sync is not available for vaults with end-to-end encryption"
+        );
+      }
+
       // Check environment-level permission and cross-environment protection
       const role = await getUserRoleWithApp(
         `${owner}/${repo}`,
@@ -1,3 +1,4 @@
+import crypto from "crypto";
 import { FastifyInstance } from "fastify";
 import { z } from "zod";
 import {
@@ -25,6 +26,7 @@ import {
   type PullSource,
 } from "../../../services/security.service";
 import { canWriteToVault } from "../../../services/usage.service";
+import { assertEncryptedValues } from "../../../services/vaultKey.service";
 import { repoFullNameSchema } from "../../../types";
 import type { RecordAccessContext, SecretAccessRecord } from "../../../services";
 
@@ -114,6 +116,15 @@ function toEnvFormat(secretsMap: Record<string, string>): string {
     .join("\n");
 }
 
+/**
+ * Compute an opaque revision for a set of secrets.
+ * Changes whenever a secret is created, updated or trashed, without revealing values.
+ */
+function computeRevision(rows: { id: string; updatedAt: Date }[]): string {
+  const parts = rows.map((s) => `${s.id}:${s.updatedAt.toISOString()}`).sort();
+  return crypto.createHash("sha256").update(parts.join("\n")).digest("hex").slice(0, 16);
+}
+
 /**
  * Secrets routes (CLI/MCP-focused)
  * POST /api/v1/secrets/push - Push secrets from CLI
@@ -172,6 +183,10 @@ export async function secretsRoutes(fastify: FastifyInstance) {
         );
       }
 
+      // Zero-knowledge vaults only accept values encrypted by the CLI, so an
+      // outdated client can't silently store plaintext
+      assertEncryptedValues(vault, body.secrets);
+
       const secretEntries = Object.entries(body.secrets);
 
       fastify.log.info(
@@ -328,6 +343,14 @@ export async function secretsRoutes(fastify: FastifyInstance) {
         throw new NotFoundError(`No secrets found for environment: ${environment}`);
       }
 
+      // Clients that already hold this revision get 304 without decryption or
+      // audit noise, which keeps `keyway pull --watch` polling cheap
+      const revision = computeRevision(envSecrets);
+      reply.header("ETag", `"${revision}"`);
+      if (request.headers["if-none-match"] === `"${revision}"`) {
+        return reply.code(304).send();
+      }
+
       // Log warning for large unpaginated pulls
       if (!query.limit && envSecrets.length > 100) {
         fastify.log.warn(
@@ -427,7 +450,11 @@ export async function secretsRoutes(fastify: FastifyInstance) {
         );
       }
 
-      return sendData(reply, { content }, { requestId: request.id });
+      return sendData(
+        reply,
+        { content, revision, e2eEnabled: vault.e2eEnabled },
+        { requestId: request.id }
+      );
     }
   );
 
@@ -39,6 +39,7 @@ import {
   NotFoundError,
   ForbiddenError,
   ConflictError,
+  BadRequestError,
   PlanLimitError,
   buildPaginationMeta,
   parsePagination,
@@ -58,6 +59,7 @@ import {
   getSecretById,
   getSecretValue,
   logActivity,
+  getActivityForVault,
   extractRequestInfo,
   detectPlatform,
   checkVaultCreationAllowed,
@@ -68,10 +70,14 @@ import {
   getTrashedSecrets,
   getTrashedSecretsCount,
   getTrashedSecretById,
+  getTrashedSecretValue,
   restoreSecret,
   permanentlyDeleteSecret,
   emptyTrash,
   recordSecretAccess,
+  getVaultKeys,
+  registerVaultPublicKey,
+  grantVaultKeys,
   type RecordAccessContext,
 } from "../../../services";
 import {
@@ -79,6 +85,7 @@ import {
   getSecretVersionValue,
   restoreSecretVersion,
 } from "../../../services/secretVersion.service";
+import { assertEncryptedValues, findPlaintextValues } from "../../../services/vaultKey.service";
 import { generateDeviceId } from "../../../services/security.service";
 import {
   getRepoInfoWithApp,
@@ -164,6 +171,30 @@ const UpdateEnvironmentTypeSchema = z.object({
   type: z.enum(["protected", "standard", "development"]),
 });
 
+// Query filters of the activity feed (keyway audit)
+const ActivityFilterSchema = z.object({
+  environment: z.string().min(1).optional(),
+  actor: z.string().min(1).optional(),
+  since: z.coerce.date().optional(),
+});
+
+// Base64 X25519 public key (32 bytes)
+const RegisterPublicKeySchema = z.object({
+  publicKey: z.string().regex(/^[A-Za-z0-9+/]{43}=$/, "Invalid public key"),
+});
+
+const GrantVaultKeysSchema = z.object({
+  enable: z.boolean().optional(),
+  wrappedKeys: z
+    .array(
+      z.object({
+        userId: z.string().uuid(),
+        wrappedKey: z.string().min(1).max(512),
+      })
+    )
+    .max(1000),
+});
+
 /**
  * Vault routes
  * GET    /v1/vaults              - List vaults
@@ -591,6 +622,9 @@ export async function vaultsRoutes(fastify: FastifyInstance) {
         throw new PlanLimitError(writeCheck.reason!);
       }
 
+      // Zero-knowledge vaults only accept values encrypted by the CLI
+      assertEncryptedValues(vault, { [body.key]: body.value });
+
       // Check secret count limit for private vaults (only for new secrets, not updates)
       if (vault.isPrivate) {
         const exists = await secretExists(vault.id, body.key, body.environment);
@@ -690,6 +724,17 @@ export async function vaultsRoutes(fastify: FastifyInstance) {
         throw new PlanLimitError(writeCheck.reason!);
       }
 
+      // Zero-knowledge vaults only accept values encrypted by the CLI, and
+      // those are bound to their key name so they can't be renamed here
+      if (vault.e2eEnabled && body.name && body.name !== existingSecret.key) {
+        throw new BadRequestError(
+          "Secrets of a vault with end-to-end encryption can't be renamed: push the value under the new name with the Keyway CLI, then delete the old one"
+        );
+      }
+      if (body.value !== undefined) {
+        assertEncryptedValues(vault, { [existingSecret.key]: body.value });
+      }
+
       const updatedSecret = await updateSecret(params.secretId, vault.id, {
         key: body.name,
         value: body.value,
@@ -1072,6 +1117,19 @@ export async function vaultsRoutes(fastify: FastifyInstance) {
         throw new PlanLimitError(writeCheck.reason!);
       }
 
+      // Versions saved before end-to-end encryption was enabled hold plaintext
+      if (vault.e2eEnabled) {
+        const version = await getSecretVersionValue(params.versionId, params.secretId, vault.id);
+        if (!version) {
+          throw new NotFoundError("Version not found");
+        }
+        if (findPlaintextValues({ [secret.key]: version.value }).length > 0) {
+          throw new BadRequestError(
+            "This version was saved before end-to-end encryption was enabled and can't be restored"
+          );
+        }
+      }
+
       const result = await restoreSecretVersion(
         params.versionId,
         params.secretId,
@@ -1202,6 +1260,16 @@ export async function vaultsRoutes(fastify: FastifyInstance) {
         throw new PlanLimitError(writeCheck.reason!);
       }
 
+      // Secrets trashed before end-to-end encryption was enabled hold plaintext
+      if (vault.e2eEnabled) {
+        const value = await getTrashedSecretValue(params.secretId, vault.id);
+        if (value === null || findPlaintextValues({ [trashedSecret.key]: value }).length > 0) {
+          throw new BadRequestError(
+            "This secret was deleted before end-to-end encryption was enabled and can't be restored"
+          );
+        }
+      }
+
       try {
         const restoredSecret = await restoreSecret(params.secretId, vault.id);
         if (!restoredSecret) {
@@ -1516,6 +1584,13 @@ export async function vaultsRoutes(fastify: FastifyInstance) {
         throw new NotFoundError("Vault not found");
       }
 
+      // End-to-end encrypted values are bound to their environment name
+      if (vault.e2eEnabled) {
+        throw new BadRequestError(
+          "Environments of a vault with end-to-end encryption can't be renamed: copy it with keyway env clone, then delete the old one"
+        );
+      }
+
       // Get current environments from table
       const currentEnvs = await getVaultEnvironments(vault.id);
       const currentEnvNames = currentEnvs.map((e) => e.name);
@@ -1924,6 +1999,171 @@ export async function vaultsRoutes(fastify: FastifyInstance) {
     }
   );
 
+  // ============================================
+  // Activity routes
+  // ============================================
+
+  /**
+   * GET /:owner/:repo/activity
+   * List activity logs for a vault (all collaborators, newest first).
+   * Filtered by ?environment=, ?actor= (username) and ?since= (ISO date)
+   */
+  fastify.get(
+    "/:owner/:repo/activity",
+    {
+      preHandler: [authenticateGitHub, requireApiKeyScope("read:secrets")],
+    },
+    async (request, reply) => {
+      const params = request.params as { owner: string; repo: string };
+      const repoFullName = `${params.owner}/${params.repo}`;
+      const vcsUser = request.vcsUser || request.githubUser!;
+      const pagination = parsePagination(request.query);
+      const filter = ActivityFilterSchema.parse(request.query);
+
+      const vault = await getVaultByRepoInternal(repoFullName);
+      if (!vault) {
+        throw new NotFoundError("Vault not found");
+      }
+
+      const role = await getUserRoleWithApp(vault.repoFullName, vcsUser.username);
+      if (!role) {
+        throw new ForbiddenError("You do not have access to this vault");
+      }
+
+      const { activities, total } = await getActivityForVault(vault.id, pagination, filter);
+
+      return sendPaginatedData(
+        reply,
+        activities,
+        buildPaginationMeta(pagination, total, activities.length),
+        { requestId: request.id }
+      );
+    }
+  );
+
+  // ============================================
+  // End-to-end encryption routes
+  // ============================================
+
+  /**
+   * GET /:owner/:repo/keys
+   * Zero-knowledge state of a vault: whether it is enabled, the vault key
+   * sealed to the caller, and which members registered a public key
+   */
+  fastify.get(
+    "/:owner/:repo/keys",
+    {
+      preHandler: [authenticateGitHub, requireApiKeyScope("read:secrets")],
+    },
+    async (request, reply) => {
+      const params = request.params as { owner: string; repo: string };
+      const repoFullName = `${params.owner}/${params.repo}`;
+      const vcsUser = (request.vcsUser || request.githubUser) as VcsUser;
+
+      const vault = await getVaultByRepoInternal(repoFullName);
+      if (!vault) {
+        throw new NotFoundError("Vault not found");
+      }
+
+      const role = await getUserRoleWithApp(vault.repoFullName, vcsUser.username);
+      if (!role) {
+        throw new ForbiddenError("You do not have access to this vault");
+      }
+
+      const user = await getOrThrowUser(vcsUser);
+      const keys = await getVaultKeys(vault.id, vault.e2eEnabled, user.id);
+
+      return sendData(reply, keys, { requestId: request.id });
+    }
+  );
+
+  /**
+   * PUT /:owner/:repo/keys/me
+   * Register the caller's public key, so a member holding the vault key can
+   * seal it to them
+   */
+  fastify.put(
+    "/:owner/:repo/keys/me",
+    {
+      preHandler: [authenticateGitHub, requireApiKeyScope("read:secrets")],
+    },
+    async (request, reply) => {
+      const params = request.params as { owner: string; repo: string };
+      const repoFullName = `${params.owner}/${params.repo}`;
+      const vcsUser = (request.vcsUser || request.githubUser) as VcsUser;
+      const body = RegisterPublicKeySchema.parse(request.body);
+
+      const vault = await getVaultByRepoInternal(repoFullName);
+      if (!vault) {
+        throw new NotFoundError("Vault not found");
+      }
+
+      const role = await getUserRoleWithApp(vault.repoFullName, vcsUser.username);
+      if (!role) {
+        throw new ForbiddenError("You do not have access to this vault");
+      }
+
+      const user = await getOrThrowUser(vcsUser);
+      await registerVaultPublicKey(vault.id, user.id, body.publicKey);
+
+      return sendNoContent(reply);
+    }
+  );
+
+  /**
+   * PUT /:owner/:repo/keys
+   * Store the vault key sealed to members' public keys. Requires write access;
+   * turning zero-knowledge mode on (enable: true) requires admin access.
+   */
+  fastify.put(
+    "/:owner/:repo/keys",
+    {
+      preHandler: [authenticateGitHub, requireApiKeyScope("write:secrets")],
+    },
+    async (request, reply) => {
+      const params = request.params as { owner: string; repo: string };
+      const repoFullName = `${params.owner}/${params.repo}`;
+      const vcsUser = (request.vcsUser || request.githubUser) as VcsUser;
+      const body = GrantVaultKeysSchema.parse(request.body);
+
+      const vault = await getVaultByRepoInternal(repoFullName);
+      if (!vault) {
+        throw new NotFoundError("Vault not found");
+      }
+
+      const role = await getUserRoleWithApp(vault.repoFullName, vcsUser.username);
+      if (!role || !["write", "maintain", "admin"].includes(role)) {
+        throw new ForbiddenError("Write access is required to share the vault key");
+      }
+      if (body.enable && role !== "admin") {
+        throw new ForbiddenError("Only repository admins can enable end-to-end encryption");
+      }
+      if (!body.enable && !vault.e2eEnabled) {
+        throw new ConflictError("End-to-end encryption is not enabled for this vault");
+      }
+
+      const user = await getOrThrowUser(vcsUser);
+      const skipped = await grantVaultKeys(vault.id, body.wrappedKeys, body.enable === true);
+
+      if (body.enable && !vault.e2eEnabled) {
+        await logActivity({
+          userId: user.id,
+          action: "vault_e2e_enabled",
+          platform: detectPlatform(request),
+          vaultId: vault.id,
+          metadata: { repoFullName },
+          ...extractRequestInfo(request),
+        });
+      }
+
+      return sendData(
+        reply,
+        { granted: body.wrappedKeys.length - skipped.length, skipped },
+        { requestId: request.id }
+      );
+    }
+  );
+
   // ============================================
   // Security routes
   // ============================================
@@ -45,6 +45,7 @@ export const environmentTypeEnum = pgEnum("environment_type", [
 export const activityActionEnum = pgEnum("activity_action", [
   "vault_created",
   "vault_deleted",
+  "vault_e2e_enabled",
   "secrets_pushed",
   "secrets_pulled",
   "secret_created",
@@ -181,6 +182,8 @@ export const vaults = pgTable(
     orgId: uuid("org_id"),
     // Whether the repo is private (fetched from VCS API during creation)
     isPrivate: boolean("is_private").notNull().default(false),
+    // Zero-knowledge mode: values are encrypted by the CLI and only ciphertext is accepted
+    e2eEnabled: boolean("e2e_enabled").notNull().default(false),
     // List of environments for this vault (dynamic, user-managed)
     environments: text("environments")
       .array()
@@ -218,6 +221,27 @@ export const vaultEnvironments = pgTable(
   ]
 );
 
+// Per-member copies of a vault's end-to-end encryption key.
+// Members register a public key; wrappedKey is the vault key sealed to it by a
+// member who already has it (null until granted). The server never sees the key.
+export const vaultKeys = pgTable(
+  "vault_keys",
+  {
+    id: uuid("id").primaryKey().defaultRandom(),
+    vaultId: uuid("vault_id")
+      .notNull()
+      .references(() => vaults.id, { onDelete: "cascade" }),
+    userId: uuid("user_id")
+      .notNull()
+      .references(() => users.id, { onDelete: "cascade" }),
+    publicKey: text("public_key").notNull(),
+    wrappedKey: text("wrapped_key"),
+    createdAt: timestamp("created_at").notNull().defaultNow(),
+    updatedAt: timestamp("updated_at").notNull().defaultNow(),
+  },
+  (table) => [unique("vault_keys_vault_user_unique").on(table.vaultId, table.userId)]
+);
+
 // Individual secrets (key-value pairs)
 export const secrets = pgTable("secrets", {
   id: uuid("id").primaryKey().defaultRandom(),
@@ -690,6 +714,7 @@ export const vaultsRelations = relations(vaults, ({ one, many }) => ({
   }),
   secrets: many(secrets),
   vaultEnvironments: many(vaultEnvironments),
+  vaultKeys: many(vaultKeys),
   environmentPermissions: many(environmentPermissions),
   permissionOverrides: many(permissionOverrides),
   activityLogs: many(activityLogs),
@@ -707,6 +732,17 @@ export const vaultEnvironmentsRelations = relations(vaultEnvironments, ({ one })
   }),
 }));
 
+export const vaultKeysRelations = relations(vaultKeys, ({ one }) => ({
+  vault: one(vaults, {
+    fields: [vaultKeys.vaultId],
+    references: [vaults.id],
+  }),
+  user: one(users, {
+    fields: [vaultKeys.userId],
+    references: [users.id],
+  }),
+}));
+
 export const secretsRelations = relations(secrets, ({ one, many }) => ({
   vault: one(vaults, {
     fields: [secrets.vaultId],
@@ -919,6 +955,8 @@ export type Vault = typeof vaults.$inferSelect;
 export type NewVault = typeof vaults.$inferInsert;
 export type VaultEnvironment = typeof vaultEnvironments.$inferSelect;
 export type NewVaultEnvironment = typeof vaultEnvironments.$inferInsert;
+export type VaultKey = typeof vaultKeys.$inferSelect;
+export type NewVaultKey = typeof vaultKeys.$inferInsert;
 export type EnvironmentType = (typeof environmentTypeEnum.enumValues)[number];
 export type Secret = typeof secrets.$inferSelect;
 export type NewSecret = typeof secrets.$inferInsert;
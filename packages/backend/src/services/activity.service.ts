@@ -1,5 +1,5 @@
-import { db, activityLogs } from "../db";
-import { eq, desc, count } from "drizzle-orm";
+import { db, activityLogs, users, vaults } from "../db";
+import { eq, desc, count, and, gt, sql } from "drizzle-orm";
 import type { ActivityAction, ActivityPlatform } from "../db/schema";
 import type { PaginationQuery } from "../lib/pagination";
 
@@ -69,31 +69,84 @@ export async function getActivityForUser(
     offset: pagination.offset,
   });
 
-  const activities: ActivityLogItem[] = logs.map((log) => {
-    const metadata = log.metadata ? JSON.parse(log.metadata) : null;
-    return {
-      id: log.id,
-      action: log.action,
-      vaultId: log.vaultId,
-      repoFullName: log.vault?.repoFullName || metadata?.repoFullName || null,
-      actor: log.user
-        ? {
-            id: log.user.id,
-            username: log.user.username,
-            avatarUrl: log.user.avatarUrl,
-          }
-        : {
-            id: log.userId || "deleted",
-            username: metadata?.username || "Deleted User",
-            avatarUrl: null,
-          },
-      platform: log.platform,
-      metadata,
-      timestamp: log.createdAt.toISOString(),
-    };
+  return { activities: logs.map(toActivityLogItem), total };
+}
+
+export interface VaultActivityFilter {
+  /** Only events about this environment */
+  environment?: string;
+  /** Only events by this user (case-insensitive username) */
+  actor?: string;
+  /** Only events strictly after this time */
+  since?: Date;
+}
+
+/**
+ * Get activity logs for a vault with pagination (all actors, newest first)
+ */
+export async function getActivityForVault(
+  vaultId: string,
+  pagination: PaginationQuery,
+  filter: VaultActivityFilter = {}
+): Promise<{ activities: ActivityLogItem[]; total: number }> {
+  const conditions = [eq(activityLogs.vaultId, vaultId)];
+  if (filter.environment) {
+    conditions.push(sql`(${activityLogs.metadata})::jsonb ->> 'environment' = ${filter.environment}`);
+  }
+  if (filter.actor) {
+    conditions.push(
+      sql`${activityLogs.userId} IN (SELECT ${users.id} FROM ${users} WHERE lower(${users.username}) = lower(${filter.actor}))`
+    );
+  }
+  if (filter.since) {
+    conditions.push(gt(activityLogs.createdAt, filter.since));
+  }
+  const whereClause = and(...conditions);
+
+  const [countResult] = await db.select({ count: count() }).from(activityLogs).where(whereClause);
+  const total = countResult?.count ?? 0;
+
+  const logs = await db.query.activityLogs.findMany({
+    where: whereClause,
+    with: {
+      user: true,
+      vault: true,
+    },
+    orderBy: [desc(activityLogs.createdAt)],
+    limit: pagination.limit,
+    offset: pagination.offset,
   });
 
-  return { activities, total };
+  return { activities: logs.map(toActivityLogItem), total };
+}
+
+type ActivityLogRow = typeof activityLogs.$inferSelect & {
+  user: typeof users.$inferSelect | null;
+  vault: typeof vaults.$inferSelect | null;
+};
+
+function toActivityLogItem(log: ActivityLogRow): ActivityLogItem {
+  const metadata = log.metadata ? JSON.parse(log.metadata) : null;
+  return {
+    id: log.id,
+    action: log.action,
+    vaultId: log.vaultId,
+    repoFullName: log.vault?.repoFullName || metadata?.repoFullName || null,
+    actor: log.user
+      ? {
+          id: log.user.id,
+          username: log.user.username,
+          avatarUrl: log.user.avatarUrl,
+        }
+      : {
+          id: log.userId || "deleted",
+          username: metadata?.username || "Deleted User",
+          avatarUrl: null,
+        },
+    platform: log.platform,
+    metadata,
+    timestamp: log.createdAt.toISOString(),
+  };
 }
 
 /**
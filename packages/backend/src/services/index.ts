@@ -28,6 +28,7 @@ export {
   getTrashedSecrets,
   getTrashedSecretsCount,
   getTrashedSecretById,
+  getTrashedSecretValue,
   restoreSecret,
   emptyTrash,
   purgeExpiredTrash,
@@ -41,10 +42,12 @@ export {
 export {
   logActivity,
   getActivityForUser,
+  getActivityForVault,
   extractRequestInfo,
   detectPlatform,
   type ActivityLogItem,
   type LogActivityInput,
+  type VaultActivityFilter,
 } from "./activity.service";
 
 // Usage service
@@ -131,3 +134,15 @@ export {
   type SignupMethod,
   type NewUserSignupParams,
 } from "./signup.service";
+
+// Vault key service (zero-knowledge mode)
+export {
+  getVaultKeys,
+  registerVaultPublicKey,
+  grantVaultKeys,
+  findPlaintextValues,
+  assertEncryptedValues,
+  E2E_VALUE_PREFIX,
+  type VaultKeyMember,
+  type VaultKeysInfo,
+} from "./vaultKey.service";
@@ -476,6 +476,35 @@ export async function getTrashedSecretById(
   };
 }
 
+/**
+ * Get a trashed secret's decrypted value (to check it before restoring)
+ * Returns null if secret not found or not in trash
+ */
+export async function getTrashedSecretValue(
+  secretId: string,
+  vaultId: string
+): Promise<string | null> {
+  const secret = await db.query.secrets.findFirst({
+    where: and(
+      eq(secrets.id, secretId),
+      eq(secrets.vaultId, vaultId),
+      isNotNull(secrets.deletedAt)
+    ),
+  });
+
+  if (!secret) {
+    return null;
+  }
+
+  const encryptionService = await getEncryptionService();
+  return encryptionService.decrypt({
+    encryptedContent: secret.encryptedValue,
+    iv: secret.iv,
+    authTag: secret.authTag,
+    version: secret.encryptionVersion ?? 1,
+  });
+}
+
 /**
  * Restore a secret from trash
  * Returns null if secret not found or not in trash
@@ -0,0 +1,142 @@
+import { db, users, vaults, vaultKeys } from "../db";
+import { eq, and, sql } from "drizzle-orm";
+import { BadRequestError } from "../lib";
+
+// Prefix of values encrypted by the CLI in zero-knowledge mode
+export const E2E_VALUE_PREFIX = "kw1:";
+
+export interface VaultKeyMember {
+  userId: string;
+  username: string;
+  publicKey: string;
+  granted: boolean;
+}
+
+export interface VaultKeysInfo {
+  e2eEnabled: boolean;
+  // The vault key sealed to the caller's public key, if granted
+  wrappedKey: string | null;
+  members: VaultKeyMember[];
+}
+
+/**
+ * Get the end-to-end encryption state of a vault, as seen by a member.
+ * Only the caller's own wrapped key is returned.
+ */
+export async function getVaultKeys(
+  vaultId: string,
+  e2eEnabled: boolean,
+  userId: string
+): Promise<VaultKeysInfo> {
+  const rows = await db
+    .select({
+      userId: vaultKeys.userId,
+      username: users.username,
+      publicKey: vaultKeys.publicKey,
+      wrappedKey: vaultKeys.wrappedKey,
+    })
+    .from(vaultKeys)
+    .innerJoin(users, eq(vaultKeys.userId, users.id))
+    .where(eq(vaultKeys.vaultId, vaultId));
+
+  const own = rows.find((row) => row.userId === userId);
+
+  return {
+    e2eEnabled,
+    wrappedKey: own?.wrappedKey ?? null,
+    members: rows.map((row) => ({
+      userId: row.userId,
+      username: row.username,
+      publicKey: row.publicKey,
+      granted: row.wrappedKey !== null,
+    })),
+  };
+}
+
+/**
+ * Register (or replace) a member's public key for a vault.
+ * A new public key invalidates the key previously sealed to the old one.
+ */
+export async function registerVaultPublicKey(
+  vaultId: string,
+  userId: string,
+  publicKey: string
+): Promise<void> {
+  await db
+    .insert(vaultKeys)
+    .values({ vaultId, userId, publicKey })
+    .onConflictDoUpdate({
+      target: [vaultKeys.vaultId, vaultKeys.userId],
+      set: {
+        publicKey,
+        wrappedKey: sql`CASE WHEN ${vaultKeys.publicKey} = ${publicKey} THEN ${vaultKeys.wrappedKey} ELSE NULL END`,
+        updatedAt: new Date(),
+      },
+    });
+}
+
+/**
+ * Store vault keys sealed to members' registered public keys, and optionally
+ * turn on zero-knowledge mode. Returns the user IDs that have no registered
+ * public key for this vault and were skipped.
+ */
+export async function grantVaultKeys(
+  vaultId: string,
+  wrappedKeys: { userId: string; wrappedKey: string }[],
+  enable: boolean
+): Promise<string[]> {
+  const skipped: string[] = [];
+
+  await db.transaction(async (tx) => {
+    for (const entry of wrappedKeys) {
+      const updated = await tx
+        .update(vaultKeys)
+        .set({ wrappedKey: entry.wrappedKey, updatedAt: new Date() })
+        .where(and(eq(vaultKeys.vaultId, vaultId), eq(vaultKeys.userId, entry.userId)))
+        .returning({ id: vaultKeys.id });
+      if (updated.length === 0) {
+        skipped.push(entry.userId);
+      }
+    }
+
+    if (enable) {
+      await tx
+        .update(vaults)
+        .set({ e2eEnabled: true, updatedAt: new Date() })
+        .where(eq(vaults.id, vaultId));
+    }
+  });
+
+  return skipped;
+}
+
+/**
+ * Find the keys of secrets that are not encrypted by the CLI.
+ * Used to reject plaintext pushes to zero-knowledge vaults.
+ */
+export function findPlaintextValues(secretValues: Record<string, string>): string[] {
+  return Object.entries(secretValues)
+    .filter(([, value]) => !value.startsWith(E2E_VALUE_PREFIX))
+    .map(([key]) => key);
+}
+
+/**
+ * Reject a write to a zero-knowledge vault unless every value was encrypted
+ * by the CLI, so neither an outdated client nor the dashboard or API can store
+ * plaintext in it. No-op for other vaults.
+ */
+export function assertEncryptedValues(
+  vault: { e2eEnabled: boolean },
+  secretValues: Record<string, string>
+): void {
+  if (!vault.e2eEnabled) {
+    return;
+  }
+  const plaintextKeys = findPlaintextValues(secretValues);
+  if (plaintextKeys.length > 0) {
+    throw new BadRequestError(
+      `This vault uses end-to-end encryption and only accepts encrypted values ` +
+        `(plaintext: ${plaintextKeys.slice(0, 5).join(", ")}). Update the Keyway CLI.`
+    );
+  }
+}
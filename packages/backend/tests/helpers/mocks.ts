@@ -95,6 +95,7 @@ export const mockVault = {
   repoName: 'test-repo',
   repoFullName: 'testuser/test-repo',
   isPrivate: false,
+  e2eEnabled: false,
   environments: ['local', 'dev', 'staging', 'production'],
   ownerId: mockUser.id,
   createdById: mockUser.id,
@@ -395,6 +395,45 @@ describe('Secrets Routes', () => {
       expect(response.statusCode).toBe(400);
     });
 
+    it('should reject plaintext values for end-to-end encrypted vaults', async () => {
+      await setupAuthenticatedVault({ e2eEnabled: true });
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/secrets/push',
+        headers: {
+          authorization: 'Bearer valid-token',
+        },
+        payload: {
+          repoFullName: 'testuser/test-repo',
+          environment: 'development',
+          secrets: { API_KEY: 'kw1:bm9uY2VjaXBoZXJ0ZXh0', DB_URL: 'postgres://localhost' },
+        },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(JSON.parse(response.body).detail).toContain('DB_URL');
+    });
+
+    it('should accept encrypted values for end-to-end encrypted vaults', async () => {
+      await setupAuthenticatedVault({ e2eEnabled: true });
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/secrets/push',
+        headers: {
+          authorization: 'Bearer valid-token',
+        },
+        payload: {
+          repoFullName: 'testuser/test-repo',
+          environment: 'development',
+          secrets: { API_KEY: 'kw1:bm9uY2VjaXBoZXJ0ZXh0' },
+        },
+      });
+
+      expect(response.statusCode).toBe(200);
+    });
+
     it('should return 403 for plan limit exceeded', async () => {
       await setupAuthenticatedVault();
 
@@ -444,6 +483,33 @@ describe('Secrets Routes', () => {
       const body = JSON.parse(response.body);
       expect(body.data.content).toContain('API_KEY=');
       expect(body.data.content).toContain('DB_URL=');
+      expect(body.data.revision).toMatch(/^[0-9a-f]{16}$/);
+    });
+
+    it('should return 304 when the client already has the current revision', async () => {
+      await setupAuthenticatedVault({}, [{ ...mockSecret, key: 'API_KEY' }]);
+
+      const first = await app.inject({
+        method: 'GET',
+        url: '/v1/secrets/pull?repo=testuser/test-repo&environment=development',
+        headers: {
+          authorization: 'Bearer valid-token',
+        },
+      });
+      expect(first.statusCode).toBe(200);
+      const etag = first.headers.etag as string;
+      expect(etag).toBe(`"${JSON.parse(first.body).data.revision}"`);
+
+      const second = await app.inject({
+        method: 'GET',
+        url: '/v1/secrets/pull?repo=testuser/test-repo&environment=development',
+        headers: {
+          authorization: 'Bearer valid-token',
+          'if-none-match': etag,
+        },
+      });
+      expect(second.statusCode).toBe(304);
+      expect(second.body).toBe('');
     });
 
     it('should return 403 for non-existent vault (access denied)', async () => {
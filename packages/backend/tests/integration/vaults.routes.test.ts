@@ -294,6 +294,20 @@ vi.mock('../../src/services', () => ({
     { name: 'production', type: 'protected', displayOrder: 2 },
   ]),
   getVaultEnvironmentNames: vi.fn().mockResolvedValue(['development', 'staging', 'production']),
+  getActivityForVault: vi.fn().mockResolvedValue({ activities: [], total: 0 }),
+  getTrashedSecretById: vi.fn(),
+  getTrashedSecretValue: vi.fn(),
+  restoreSecret: vi.fn(),
+  getVaultKeys: vi.fn(),
+  registerVaultPublicKey: vi.fn().mockResolvedValue(undefined),
+  grantVaultKeys: vi.fn().mockResolvedValue([]),
+}));
+
+// Mock secret version service (route imports it directly)
+vi.mock('../../src/services/secretVersion.service', () => ({
+  getSecretVersions: vi.fn().mockResolvedValue([]),
+  getSecretVersionValue: vi.fn(),
+  restoreSecretVersion: vi.fn().mockResolvedValue({ key: 'API_KEY', versionNumber: 1 }),
 }));
 
 describe('Vaults Routes', () => {
@@ -809,3 +823,447 @@ describe('Secret lastModifiedBy', () => {
     );
   });
 });
+
+describe('GET /v1/vaults/:owner/:repo/activity (Vault Activity)', () => {
+  let app: FastifyInstance;
+
+  beforeEach(async () => {
+    vi.clearAllMocks();
+
+    app = Fastify({ logger: false });
+    await app.register(formbody);
+    await app.register(cookie);
+
+    // Query validation errors become 400s, as in src/index.ts
+    const { ZodError } = await import('zod');
+    const { ApiError, ValidationError } = await import('../../src/lib/errors');
+    app.setErrorHandler((error, request, reply) => {
+      const apiError = error instanceof ZodError ? ValidationError.fromZodError(error) : error;
+      if (apiError instanceof ApiError) {
+        return reply.status(apiError.status).send(apiError.toProblemDetails(request.id));
+      }
+      return reply.status((error as any).statusCode || 500).send({ status: 500, detail: error.message });
+    });
+
+    const { vaultsRoutes } = await import('../../src/api/v1/routes/vaults.routes');
+    await app.register(vaultsRoutes, { prefix: '/v1/vaults' });
+
+    await app.ready();
+  });
+
+  afterEach(async () => {
+    await app.close();
+  });
+
+  it('should list the vault activity with pagination', async () => {
+    const services = await import('../../src/services');
+    const { getUserRoleWithApp } = await import('../../src/utils/github');
+
+    (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+    (getUserRoleWithApp as any).mockResolvedValue('read');
+    (services.getActivityForVault as any).mockResolvedValue({
+      activities: [{ id: 'a1', action: 'secrets_pulled', metadata: { environment: 'production' } }],
+      total: 3,
+    });
+
+    const response = await app.inject({
+      method: 'GET',
+      url: '/v1/vaults/testuser/test-repo/activity?limit=1&offset=0',
+      headers: {
+        authorization: 'Bearer mock-keyway-token',
+      },
+    });
+
+    expect(response.statusCode).toBe(200);
+    const body = JSON.parse(response.body);
+    expect(body.data).toHaveLength(1);
+    expect(body.meta.pagination.hasMore).toBe(true);
+    expect(services.getActivityForVault).toHaveBeenCalledWith(
+      mockVault.id,
+      expect.objectContaining({ limit: 1, offset: 0 }),
+      {}
+    );
+  });
+
+  it('should pass the environment, actor and since filters to the service', async () => {
+    const services = await import('../../src/services');
+    const { getUserRoleWithApp } = await import('../../src/utils/github');
+
+    (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+    (getUserRoleWithApp as any).mockResolvedValue('read');
+    (services.getActivityForVault as any).mockResolvedValue({ activities: [], total: 0 });
+
+    const response = await app.inject({
+      method: 'GET',
+      url: '/v1/vaults/testuser/test-repo/activity?environment=production&actor=alice&since=2026-01-02T00:00:00Z',
+      headers: {
+        authorization: 'Bearer mock-keyway-token',
+      },
+    });
+
+    expect(response.statusCode).toBe(200);
+    expect(services.getActivityForVault).toHaveBeenCalledWith(mockVault.id, expect.anything(), {
+      environment: 'production',
+      actor: 'alice',
+      since: new Date('2026-01-02T00:00:00Z'),
+    });
+  });
+
+  it('should reject an invalid since', async () => {
+    const services = await import('../../src/services');
+    const { getUserRoleWithApp } = await import('../../src/utils/github');
+
+    (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+    (getUserRoleWithApp as any).mockResolvedValue('read');
+
+    const response = await app.inject({
+      method: 'GET',
+      url: '/v1/vaults/testuser/test-repo/activity?since=yesterday-ish',
+      headers: {
+        authorization: 'Bearer mock-keyway-token',
+      },
+    });
+
+    expect(response.statusCode).toBe(400);
+    expect(services.getActivityForVault).not.toHaveBeenCalled();
+  });
+
+  it('should return 403 if user has no access to vault', async () => {
+    const services = await import('../../src/services');
+    const { getUserRoleWithApp } = await import('../../src/utils/github');
+
+    (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+    (getUserRoleWithApp as any).mockResolvedValue(null);
+
+    const response = await app.inject({
+      method: 'GET',
+      url: '/v1/vaults/testuser/test-repo/activity',
+      headers: {
+        authorization: 'Bearer mock-keyway-token',
+      },
+    });
+
+    expect(response.statusCode).toBe(403);
+    expect(services.getActivityForVault).not.toHaveBeenCalled();
+  });
+});
+
+describe('End-to-end encrypted vaults', () => {
+  let app: FastifyInstance;
+  const e2eVault = { ...mockVault, e2eEnabled: true };
+  const ciphertext = 'kw1:c2VhbGVkLXZhbHVl';
+  const publicKey = 'A'.repeat(43) + '=';
+
+  beforeEach(async () => {
+    vi.clearAllMocks();
+
+    app = Fastify({ logger: false });
+    await app.register(formbody);
+    await app.register(cookie);
+
+    // API errors become their status, as in src/index.ts
+    const { ZodError } = await import('zod');
+    const { ApiError, ValidationError } = await import('../../src/lib/errors');
+    app.setErrorHandler((error, request, reply) => {
+      const apiError = error instanceof ZodError ? ValidationError.fromZodError(error) : error;
+      if (apiError instanceof ApiError) {
+        return reply.status(apiError.status).send(apiError.toProblemDetails(request.id));
+      }
+      return reply.status((error as any).statusCode || 500).send({ status: 500, detail: error.message });
+    });
+
+    const { vaultsRoutes } = await import('../../src/api/v1/routes/vaults.routes');
+    await app.register(vaultsRoutes, { prefix: '/v1/vaults' });
+
+    await app.ready();
+
+    const services = await import('../../src/services');
+    const { getUserRoleWithApp } = await import('../../src/utils/github');
+    (services.getVaultByRepoInternal as any).mockResolvedValue(e2eVault);
+    (services.canWriteToVault as any).mockResolvedValue({ allowed: true });
+    (services.getSecretById as any).mockResolvedValue({ id: 'secret-123', key: 'API_KEY', environment: 'development' });
+    (getUserRoleWithApp as any).mockResolvedValue('admin');
+  });
+
+  afterEach(async () => {
+    const services = await import('../../src/services');
+    (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+    await app.close();
+  });
+
+  describe('POST /v1/vaults/:owner/:repo/secrets', () => {
+    it('should reject a plaintext value', async () => {
+      const services = await import('../../src/services');
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/vaults/testuser/test-repo/secrets',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { key: 'API_KEY', value: 'plaintext', environment: 'development' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(JSON.parse(response.body).detail).toContain('end-to-end encryption');
+      expect(services.upsertSecret).not.toHaveBeenCalled();
+    });
+
+    it('should accept an encrypted value', async () => {
+      const services = await import('../../src/services');
+      (services.upsertSecret as any).mockResolvedValue({ id: 'secret-id', status: 'created' });
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/vaults/testuser/test-repo/secrets',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { key: 'API_KEY', value: ciphertext, environment: 'development' },
+      });
+
+      expect(response.statusCode).toBe(201);
+      expect(services.upsertSecret).toHaveBeenCalledWith(expect.objectContaining({ value: ciphertext }));
+    });
+  });
+
+  describe('PATCH /v1/vaults/:owner/:repo/secrets/:secretId', () => {
+    it('should reject a plaintext value', async () => {
+      const services = await import('../../src/services');
+
+      const response = await app.inject({
+        method: 'PATCH',
+        url: '/v1/vaults/testuser/test-repo/secrets/secret-123',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { value: 'plaintext' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(services.updateSecret).not.toHaveBeenCalled();
+    });
+
+    it('should reject a rename, since values are bound to their key name', async () => {
+      const services = await import('../../src/services');
+
+      const response = await app.inject({
+        method: 'PATCH',
+        url: '/v1/vaults/testuser/test-repo/secrets/secret-123',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { name: 'NEW_API_KEY' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(services.updateSecret).not.toHaveBeenCalled();
+    });
+
+    it('should accept an encrypted value', async () => {
+      const services = await import('../../src/services');
+      (services.updateSecret as any).mockResolvedValue(mockSecretListItem);
+
+      const response = await app.inject({
+        method: 'PATCH',
+        url: '/v1/vaults/testuser/test-repo/secrets/secret-123',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { value: ciphertext },
+      });
+
+      expect(response.statusCode).toBe(200);
+      expect(services.updateSecret).toHaveBeenCalled();
+    });
+  });
+
+  describe('POST /v1/vaults/:owner/:repo/secrets/:secretId/versions/:versionId/restore', () => {
+    it('should refuse to restore a version saved before encryption was enabled', async () => {
+      const versions = await import('../../src/services/secretVersion.service');
+      (versions.getSecretVersionValue as any).mockResolvedValue({ value: 'plaintext', versionNumber: 1 });
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/vaults/testuser/test-repo/secrets/secret-123/versions/version-1/restore',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(JSON.parse(response.body).detail).toContain('before end-to-end encryption');
+      expect(versions.restoreSecretVersion).not.toHaveBeenCalled();
+    });
+
+    it('should restore an encrypted version', async () => {
+      const versions = await import('../../src/services/secretVersion.service');
+      (versions.getSecretVersionValue as any).mockResolvedValue({ value: ciphertext, versionNumber: 2 });
+      (versions.restoreSecretVersion as any).mockResolvedValue({ key: 'API_KEY', versionNumber: 2 });
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/vaults/testuser/test-repo/secrets/secret-123/versions/version-2/restore',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+      });
+
+      expect(response.statusCode).toBe(200);
+      expect(versions.restoreSecretVersion).toHaveBeenCalledWith('version-2', 'secret-123', e2eVault.id, mockUser.id);
+    });
+  });
+
+  describe('POST /v1/vaults/:owner/:repo/trash/:secretId/restore', () => {
+    it('should refuse to restore a secret trashed before encryption was enabled', async () => {
+      const services = await import('../../src/services');
+      (services.getTrashedSecretById as any).mockResolvedValue({ id: 'secret-123', key: 'API_KEY', environment: 'development' });
+      (services.getTrashedSecretValue as any).mockResolvedValue('plaintext');
+
+      const response = await app.inject({
+        method: 'POST',
+        url: '/v1/vaults/testuser/test-repo/trash/secret-123/restore',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(services.restoreSecret).not.toHaveBeenCalled();
+    });
+  });
+
+  describe('PATCH /v1/vaults/:owner/:repo/environments/:name', () => {
+    it('should refuse to rename an environment, since values are bound to it', async () => {
+      const response = await app.inject({
+        method: 'PATCH',
+        url: '/v1/vaults/testuser/test-repo/environments/staging',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { newName: 'preprod' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(JSON.parse(response.body).detail).toContain('keyway env clone');
+    });
+  });
+
+  describe('GET /v1/vaults/:owner/:repo/keys', () => {
+    it('should return the caller\'s view of the vault keys', async () => {
+      const services = await import('../../src/services');
+      const keys = {
+        e2eEnabled: true,
+        wrappedKey: 'wrapped',
+        members: [{ userId: mockUser.id, username: 'testuser', publicKey, granted: true }],
+      };
+      (services.getVaultKeys as any).mockResolvedValue(keys);
+
+      const response = await app.inject({
+        method: 'GET',
+        url: '/v1/vaults/testuser/test-repo/keys',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+      });
+
+      expect(response.statusCode).toBe(200);
+      expect(JSON.parse(response.body).data).toEqual(keys);
+      expect(services.getVaultKeys).toHaveBeenCalledWith(e2eVault.id, true, mockUser.id);
+    });
+
+    it('should return 403 if user has no access to vault', async () => {
+      const services = await import('../../src/services');
+      const { getUserRoleWithApp } = await import('../../src/utils/github');
+      (getUserRoleWithApp as any).mockResolvedValue(null);
+
+      const response = await app.inject({
+        method: 'GET',
+        url: '/v1/vaults/testuser/test-repo/keys',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+      });
+
+      expect(response.statusCode).toBe(403);
+      expect(services.getVaultKeys).not.toHaveBeenCalled();
+    });
+  });
+
+  describe('PUT /v1/vaults/:owner/:repo/keys/me', () => {
+    it('should register the caller\'s public key', async () => {
+      const services = await import('../../src/services');
+
+      const response = await app.inject({
+        method: 'PUT',
+        url: '/v1/vaults/testuser/test-repo/keys/me',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { publicKey },
+      });
+
+      expect(response.statusCode).toBe(204);
+      expect(services.registerVaultPublicKey).toHaveBeenCalledWith(e2eVault.id, mockUser.id, publicKey);
+    });
+
+    it('should reject an invalid public key', async () => {
+      const services = await import('../../src/services');
+
+      const response = await app.inject({
+        method: 'PUT',
+        url: '/v1/vaults/testuser/test-repo/keys/me',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { publicKey: 'not-a-key' },
+      });
+
+      expect(response.statusCode).toBe(400);
+      expect(services.registerVaultPublicKey).not.toHaveBeenCalled();
+    });
+  });
+
+  describe('PUT /v1/vaults/:owner/:repo/keys', () => {
+    const wrappedKeys = [{ userId: '7f3e4c1a-2b5d-4e6f-8a9b-0c1d2e3f4a5b', wrappedKey: 'wrapped' }];
+
+    it('should share the vault key with members', async () => {
+      const services = await import('../../src/services');
+      const { getUserRoleWithApp } = await import('../../src/utils/github');
+      (getUserRoleWithApp as any).mockResolvedValue('write');
+
+      const response = await app.inject({
+        method: 'PUT',
+        url: '/v1/vaults/testuser/test-repo/keys',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { wrappedKeys },
+      });
+
+      expect(response.statusCode).toBe(200);
+      expect(JSON.parse(response.body).data).toEqual({ granted: 1, skipped: [] });
+      expect(services.grantVaultKeys).toHaveBeenCalledWith(e2eVault.id, wrappedKeys, false);
+    });
+
+    it('should only let admins enable encryption', async () => {
+      const services = await import('../../src/services');
+      const { getUserRoleWithApp } = await import('../../src/utils/github');
+      (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+      (getUserRoleWithApp as any).mockResolvedValue('write');
+
+      const response = await app.inject({
+        method: 'PUT',
+        url: '/v1/vaults/testuser/test-repo/keys',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { wrappedKeys, enable: true },
+      });
+
+      expect(response.statusCode).toBe(403);
+      expect(services.grantVaultKeys).not.toHaveBeenCalled();
+    });
+
+    it('should enable encryption and log it', async () => {
+      const services = await import('../../src/services');
+      (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+
+      const response = await app.inject({
+        method: 'PUT',
+        url: '/v1/vaults/testuser/test-repo/keys',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { wrappedKeys, enable: true },
+      });
+
+      expect(response.statusCode).toBe(200);
+      expect(services.grantVaultKeys).toHaveBeenCalledWith(mockVault.id, wrappedKeys, true);
+      expect(services.logActivity).toHaveBeenCalledWith(expect.objectContaining({ action: 'vault_e2e_enabled' }));
+    });
+
+    it('should refuse to share a key of a vault without encryption', async () => {
+      const services = await import('../../src/services');
+      (services.getVaultByRepoInternal as any).mockResolvedValue(mockVault);
+
+      const response = await app.inject({
+        method: 'PUT',
+        url: '/v1/vaults/testuser/test-repo/keys',
+        headers: { authorization: 'Bearer mock-keyway-token' },
+        payload: { wrappedKeys },
+      });
+
+      expect(response.statusCode).toBe(409);
+      expect(services.grantVaultKeys).not.toHaveBeenCalled();
+    });
+  });
+});
@@ -0,0 +1,150 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+
+// Mock database
+vi.mock('../../src/db', () => ({
+  db: {
+    select: vi.fn(),
+    insert: vi.fn(),
+    transaction: vi.fn(),
+  },
+  users: { id: 'id', username: 'username' },
+  vaults: { id: 'id', e2eEnabled: 'e2eEnabled' },
+  vaultKeys: {
+    id: 'id',
+    vaultId: 'vaultId',
+    userId: 'userId',
+    publicKey: 'publicKey',
+    wrappedKey: 'wrappedKey',
+  },
+}));
+
+import { db } from '../../src/db';
+import {
+  getVaultKeys,
+  registerVaultPublicKey,
+  grantVaultKeys,
+  findPlaintextValues,
+  assertEncryptedValues,
+} from '../../src/services/vaultKey.service';
+import { BadRequestError } from '../../src/lib';
+
+describe('VaultKey Service', () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+  });
+
+  describe('getVaultKeys', () => {
+    const rows = [
+      { userId: 'user-1', username: 'alice', publicKey: 'pk-alice', wrappedKey: 'wrapped-alice' },
+      { userId: 'user-2', username: 'bob', publicKey: 'pk-bob', wrappedKey: null },
+    ];
+
+    beforeEach(() => {
+      (db.select as any).mockReturnValue({
+        from: vi.fn().mockReturnValue({
+          innerJoin: vi.fn().mockReturnValue({
+            where: vi.fn().mockResolvedValue(rows),
+          }),
+        }),
+      });
+    });
+
+    it('should return only the caller\'s wrapped key', async () => {
+      const result = await getVaultKeys('vault-1', true, 'user-1');
+
+      expect(result.e2eEnabled).toBe(true);
+      expect(result.wrappedKey).toBe('wrapped-alice');
+      expect(result.members).toEqual([
+        { userId: 'user-1', username: 'alice', publicKey: 'pk-alice', granted: true },
+        { userId: 'user-2', username: 'bob', publicKey: 'pk-bob', granted: false },
+      ]);
+      expect(JSON.stringify(result.members)).not.toContain('wrapped-alice');
+    });
+
+    it('should return no wrapped key to a member waiting for a grant', async () => {
+      const result = await getVaultKeys('vault-1', true, 'user-2');
+
+      expect(result.wrappedKey).toBeNull();
+    });
+  });
+
+  describe('registerVaultPublicKey', () => {
+    it('should upsert the public key and reset the wrapped key of a changed key', async () => {
+      const onConflictDoUpdate = vi.fn().mockResolvedValue(undefined);
+      const values = vi.fn().mockReturnValue({ onConflictDoUpdate });
+      (db.insert as any).mockReturnValue({ values });
+
+      await registerVaultPublicKey('vault-1', 'user-1', 'pk-new');
+
+      expect(values).toHaveBeenCalledWith({ vaultId: 'vault-1', userId: 'user-1', publicKey: 'pk-new' });
+      const conflict = onConflictDoUpdate.mock.calls[0][0];
+      expect(conflict.set.publicKey).toBe('pk-new');
+      expect(conflict.set.wrappedKey).toBeDefined();
+    });
+  });
+
+  describe('grantVaultKeys', () => {
+    it('should report members without a registered public key', async () => {
+      const returning = vi
+        .fn()
+        .mockResolvedValueOnce([{ id: 'key-1' }])
+        .mockResolvedValueOnce([]);
+      const tx = {
+        update: vi.fn().mockReturnValue({
+          set: vi.fn().mockReturnValue({
+            where: vi.fn().mockReturnValue({ returning }),
+          }),
+        }),
+      };
+      (db.transaction as any).mockImplementation(async (fn: (tx: unknown) => Promise<void>) => fn(tx));
+
+      const skipped = await grantVaultKeys(
+        'vault-1',
+        [
+          { userId: 'user-1', wrappedKey: 'w1' },
+          { userId: 'user-2', wrappedKey: 'w2' },
+        ],
+        false
+      );
+
+      expect(skipped).toEqual(['user-2']);
+      expect(tx.update).toHaveBeenCalledTimes(2);
+    });
+
+    it('should enable zero-knowledge mode in the same transaction', async () => {
+      const set = vi.fn().mockReturnValue({
+        where: vi.fn().mockReturnValue({ returning: vi.fn().mockResolvedValue([{ id: 'key-1' }]) }),
+      });
+      const tx = { update: vi.fn().mockReturnValue({ set }) };
+      (db.transaction as any).mockImplementation(async (fn: (tx: unknown) => Promise<void>) => fn(tx));
+
+      await grantVaultKeys('vault-1', [{ userId: 'user-1', wrappedKey: 'w1' }], true);
+
+      expect(tx.update).toHaveBeenCalledTimes(2);
+      expect(set.mock.calls[1][0]).toMatchObject({ e2eEnabled: true });
+    });
+  });
+
+  describe('findPlaintextValues', () => {
+    it('should list keys whose values are not encrypted by the CLI', () => {
+      expect(findPlaintextValues({ A: 'kw1:abc', B: 'plain', C: '' })).toEqual(['B', 'C']);
+    });
+  });
+
+  describe('assertEncryptedValues', () => {
+    it('should accept anything for vaults without end-to-end encryption', () => {
+      expect(() => assertEncryptedValues({ e2eEnabled: false }, { A: 'plain' })).not.toThrow();
+    });
+
+    it('should accept ciphertext for zero-knowledge vaults', () => {
+      expect(() => assertEncryptedValues({ e2eEnabled: true }, { A: 'kw1:abc' })).not.toThrow();
+    });
+
+    it('should reject plaintext for zero-knowledge vaults', () => {
+      expect(() => assertEncryptedValues({ e2eEnabled: true }, { A: 'kw1:abc', B: 'plain' })).toThrow(
+        BadRequestError
+      );
+      expect(() => assertEncryptedValues({ e2eEnabled: true }, { B: 'plain' })).toThrow(/plaintext: B/);
+    });
+  });
+});
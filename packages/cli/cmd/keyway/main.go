@@ -18,6 +18,8 @@ func main() {
 	defer analytics.Shutdown()
 
 	if err := cmd.Execute(version); err != nil {
-		os.Exit(1)
+		// os.Exit skips deferred calls
+		analytics.Shutdown()
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,69 @@
+// Package agent implements a local daemon, similar to ssh-agent, that keeps
+// pulled environments in memory so that keyway run doesn't need an API round
+// trip and end-to-end decryption every time. It serves the user that started
+// it over a Unix socket.
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketEnvVar overrides the path of the agent's socket
+const SocketEnvVar = "KEYWAY_AGENT_SOCK"
+
+// DefaultTTL is how long the agent keeps an environment
+const DefaultTTL = 15 * time.Minute
+
+// ErrNotRunning is returned when no agent listens on the socket
+var ErrNotRunning = errors.New("no keyway agent is running")
+
+// SocketPath returns where the agent listens: $KEYWAY_AGENT_SOCK, or a
+// private directory in $XDG_RUNTIME_DIR or ~/.config/keyway
+func SocketPath() (string, error) {
+	if path := os.Getenv(SocketEnvVar); path != "" {
+		return path, nil
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "keyway", "agent.sock"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "keyway", "agent.sock"), nil
+}
+
+// request is a line sent to the agent
+type request struct {
+	Op   string `json:"op"` // "pull", "evict", "status" or "lock"
+	Repo string `json:"repo,omitempty"`
+	Env  string `json:"env,omitempty"`
+}
+
+// response is the line the agent answers with
+type response struct {
+	Error    string  `json:"error,omitempty"`
+	Content  string  `json:"content,omitempty"`
+	Revision string  `json:"revision,omitempty"`
+	Status   *Status `json:"status,omitempty"`
+}
+
+// Status describes a running agent. It never includes secret values.
+type Status struct {
+	PID     int           `json:"pid"`
+	TTL     time.Duration `json:"ttl"`
+	Started time.Time     `json:"started"`
+	Locked  bool          `json:"locked"` // whether memory could be locked
+	Entries []Entry       `json:"entries"`
+}
+
+// Entry is an environment held by the agent
+type Entry struct {
+	Repo    string    `json:"repo"`
+	Env     string    `json:"env"`
+	Fetched time.Time `json:"fetched"`
+	Expires time.Time `json:"expires"`
+}
@@ -0,0 +1,190 @@
+//go:build !windows
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// startAgent serves a Server on a temporary socket
+func startAgent(t *testing.T, fetch FetchFunc, ttl time.Duration) (*Client, chan error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	served := make(chan error, 1)
+	srv := NewServer(fetch, ttl)
+	go func() { served <- srv.Serve(ln) }()
+	t.Cleanup(srv.Lock)
+	return NewClient(path), served
+}
+
+func TestAgent_CachesEnvironments(t *testing.T) {
+	var fetches int32
+	fetch := func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+		atomic.AddInt32(&fetches, 1)
+		return &api.PullSecretsResponse{Content: "API_KEY=" + env, Revision: "r1"}, nil
+	}
+	client, _ := startAgent(t, fetch, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Pull(ctx, "owner/repo", "staging")
+		if err != nil {
+			t.Fatalf("Pull: %v", err)
+		}
+		if resp.Content != "API_KEY=staging" || resp.Revision != "r1" {
+			t.Errorf("got %+v", resp)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected 1 fetch, got %d", n)
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(status.Entries) != 1 || status.Entries[0].Repo != "owner/repo" || status.Entries[0].Env != "staging" {
+		t.Errorf("unexpected entries %+v", status.Entries)
+	}
+}
+
+func TestAgent_RefetchesAfterTTL(t *testing.T) {
+	var fetches int32
+	fetch := func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+		atomic.AddInt32(&fetches, 1)
+		return &api.PullSecretsResponse{Content: "A=1"}, nil
+	}
+	client, _ := startAgent(t, fetch, 50*time.Millisecond)
+
+	_, _ = client.Pull(context.Background(), "owner/repo", "dev")
+	time.Sleep(60 * time.Millisecond)
+	_, _ = client.Pull(context.Background(), "owner/repo", "dev")
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected 2 fetches, got %d", n)
+	}
+}
+
+func TestAgent_Evict(t *testing.T) {
+	var fetches int32
+	fetch := func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return &api.PullSecretsResponse{Content: fmt.Sprintf("A=%d", n)}, nil
+	}
+	client, _ := startAgent(t, fetch, time.Minute)
+	ctx := context.Background()
+
+	for _, env := range []string{"dev", "staging"} {
+		if _, err := client.Pull(ctx, "owner/repo", env); err != nil {
+			t.Fatalf("Pull: %v", err)
+		}
+	}
+	_, _ = client.Pull(ctx, "owner/other", "dev")
+
+	if err := client.Evict(ctx, "owner/repo", "dev"); err != nil {
+		t.Fatalf("Evict: %v", err)
+	}
+	resp, err := client.Pull(ctx, "owner/repo", "dev")
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if resp.Content != "A=4" {
+		t.Errorf("expected the evicted environment to be fetched again, got %q", resp.Content)
+	}
+
+	// An empty env evicts the whole repository, and only it
+	if err := client.Evict(ctx, "owner/repo", ""); err != nil {
+		t.Fatalf("Evict: %v", err)
+	}
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(status.Entries) != 1 || status.Entries[0].Repo != "owner/other" {
+		t.Errorf("unexpected entries %+v", status.Entries)
+	}
+}
+
+func TestAgent_Lock(t *testing.T) {
+	fetch := func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+		return &api.PullSecretsResponse{Content: "A=1"}, nil
+	}
+	client, served := startAgent(t, fetch, time.Minute)
+	_, _ = client.Pull(context.Background(), "owner/repo", "dev")
+
+	if err := client.Lock(context.Background()); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("expected Serve to return nil, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent still running after lock")
+	}
+	if client.Available() {
+		t.Error("expected the socket to be gone")
+	}
+	if _, err := client.Pull(context.Background(), "owner/repo", "dev"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestAgent_SingleInstance(t *testing.T) {
+	client, _ := startAgent(t, nil, time.Minute)
+	if _, err := Listen(client.path); err == nil {
+		t.Error("expected error when an agent already listens")
+	}
+}
+
+func TestAPIClient_FallsBackWithoutAgent(t *testing.T) {
+	inner := api.NewMockClient()
+	inner.PullSecretsFn = func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+		return &api.PullSecretsResponse{Content: "FROM=api"}, nil
+	}
+	c := NewAPIClient(inner, NewClient(filepath.Join(t.TempDir(), "none.sock")))
+
+	resp, err := c.PullSecrets(context.Background(), "owner/repo", "dev")
+	if err != nil || resp.Content != "FROM=api" {
+		t.Fatalf("got %+v, %v", resp, err)
+	}
+	if c.Served() != 0 {
+		t.Errorf("expected nothing served by the agent, got %d", c.Served())
+	}
+}
+
+func TestAPIClient_PullsThroughAgent(t *testing.T) {
+	fetch := func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+		return &api.PullSecretsResponse{Content: "FROM=agent"}, nil
+	}
+	client, _ := startAgent(t, fetch, time.Minute)
+	c := NewAPIClient(api.NewMockClient(), client)
+
+	resp, err := c.PullSecrets(context.Background(), "owner/repo", "dev")
+	if err != nil || resp.Content != "FROM=agent" || c.Served() != 1 {
+		t.Fatalf("got %+v, %v, served %d", resp, err, c.Served())
+	}
+}
+
+func TestLockedBuffer_Destroy(t *testing.T) {
+	buf, _ := newLockedBuffer([]byte("secret"))
+	if string(buf.Bytes()) != "secret" {
+		t.Fatalf("got %q", buf.Bytes())
+	}
+	buf.Destroy()
+	if len(buf.Bytes()) != 0 {
+		t.Error("expected the buffer to be emptied")
+	}
+}
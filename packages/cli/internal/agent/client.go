@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// Client talks to a running agent
+type Client struct {
+	path string
+}
+
+// NewClient returns a client for the agent listening on path
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Available reports whether an agent answers on the socket
+func (c *Client) Available() bool {
+	conn, err := net.DialTimeout("unix", c.path, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Pull returns an environment, from the agent's memory when it holds it
+func (c *Client) Pull(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+	resp, err := c.call(ctx, request{Op: "pull", Repo: repo, Env: env})
+	if err != nil {
+		return nil, err
+	}
+	return &api.PullSecretsResponse{Content: resp.Content, Revision: resp.Revision}, nil
+}
+
+// Evict makes the agent forget an environment, or every environment of the
+// repository when env is empty, after it changed in the vault
+func (c *Client) Evict(ctx context.Context, repo, env string) error {
+	_, err := c.call(ctx, request{Op: "evict", Repo: repo, Env: env})
+	return err
+}
+
+// Status describes the agent
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	resp, err := c.call(ctx, request{Op: "status"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status == nil {
+		return nil, errors.New("invalid response from the agent")
+	}
+	return resp.Status, nil
+}
+
+// Lock makes the agent wipe what it holds and exit
+func (c *Client) Lock(ctx context.Context) error {
+	_, err := c.call(ctx, request{Op: "lock"})
+	return err
+}
+
+// call sends a request and reads the response
+func (c *Client) call(ctx context.Context, req request) (*response, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// APIClient wraps an API client so that secrets are pulled through the
+// agent. When the agent fails, the wrapped client is used instead.
+type APIClient struct {
+	api.APIClient
+
+	agent *Client
+
+	mu     sync.Mutex
+	served int
+}
+
+// NewAPIClient wraps client
+func NewAPIClient(client api.APIClient, agent *Client) *APIClient {
+	return &APIClient{APIClient: client, agent: agent}
+}
+
+// PullSecrets pulls through the agent
+func (c *APIClient) PullSecrets(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+	resp, err := c.agent.Pull(ctx, repo, env)
+	if err != nil {
+		return c.APIClient.PullSecrets(ctx, repo, env)
+	}
+	c.mu.Lock()
+	c.served++
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// Served returns how many environments the agent served
+func (c *APIClient) Served() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.served
+}
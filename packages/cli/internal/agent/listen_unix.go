@@ -0,0 +1,68 @@
+//go:build !windows
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Listen creates the agent's socket, which only the current user can use
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, errors.New("an agent is already running")
+	}
+	// An agent that didn't exit cleanly leaves its socket behind
+	_ = os.Remove(path)
+
+	old := syscall.Umask(0077)
+	ln, err := net.Listen("unix", path)
+	syscall.Umask(old)
+	if err != nil {
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Spawn starts keyway with args as a daemon, detached from the terminal, and
+// returns its PID
+func Spawn(args []string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start the agent: %w", err)
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// errPeer is returned to connections from other users
+var errPeer = errors.New("the agent only serves the user that started it")
+
+// peerControl runs fn with the file descriptor of a Unix socket connection
+func peerControl(conn net.Conn, fn func(fd int)) error {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errPeer
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return raw.Control(func(fd uintptr) { fn(int(fd)) })
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package agent
+
+import (
+	"errors"
+	"net"
+)
+
+var errUnsupported = errors.New("the agent is not supported on Windows")
+
+// Listen is not supported on Windows
+func Listen(path string) (net.Listener, error) {
+	return nil, errUnsupported
+}
+
+// Spawn is not supported on Windows
+func Spawn(args []string) (int, error) {
+	return 0, errUnsupported
+}
+
+// checkPeer is not supported on Windows
+func checkPeer(conn net.Conn) error {
+	return errUnsupported
+}
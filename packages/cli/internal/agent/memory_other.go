@@ -0,0 +1,6 @@
+//go:build !linux && !windows
+
+package agent
+
+// dontDump is only supported on Linux
+func dontDump(mem []byte) {}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package agent
+
+import "golang.org/x/sys/unix"
+
+// lockedBuffer holds secret bytes in memory that is never swapped out and is
+// left out of core dumps, and wipes them when destroyed. Only the buffer
+// itself is protected: copies made from it, such as the responses sent to
+// clients, are ordinary memory until the garbage collector reclaims them.
+type lockedBuffer struct {
+	mem []byte
+	n   int
+}
+
+// newLockedBuffer copies data into locked memory. locked is false when the
+// memory could not be locked (RLIMIT_MEMLOCK), in which case the data is
+// still held, but could be swapped.
+func newLockedBuffer(data []byte) (buf *lockedBuffer, locked bool) {
+	size := len(data)
+	if size == 0 {
+		size = 1
+	}
+	mem, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return &lockedBuffer{mem: append([]byte(nil), data...), n: len(data)}, false
+	}
+	locked = unix.Mlock(mem) == nil
+	dontDump(mem)
+	copy(mem, data)
+	return &lockedBuffer{mem: mem, n: len(data)}, locked
+}
+
+// Bytes returns the data
+func (b *lockedBuffer) Bytes() []byte {
+	return b.mem[:b.n]
+}
+
+// Destroy wipes the data and releases the memory
+func (b *lockedBuffer) Destroy() {
+	if b.mem == nil {
+		return
+	}
+	for i := range b.mem {
+		b.mem[i] = 0
+	}
+	_ = unix.Munlock(b.mem)
+	_ = unix.Munmap(b.mem)
+	b.mem = nil
+	b.n = 0
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package agent
+
+// lockedBuffer holds secret bytes; memory is not locked on Windows
+type lockedBuffer struct {
+	mem []byte
+}
+
+func newLockedBuffer(data []byte) (buf *lockedBuffer, locked bool) {
+	return &lockedBuffer{mem: append([]byte(nil), data...)}, false
+}
+
+// Bytes returns the data
+func (b *lockedBuffer) Bytes() []byte {
+	return b.mem
+}
+
+// Destroy wipes the data
+func (b *lockedBuffer) Destroy() {
+	for i := range b.mem {
+		b.mem[i] = 0
+	}
+	b.mem = nil
+}
@@ -0,0 +1,23 @@
+package agent
+
+import (
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkPeer refuses connections from other users
+func checkPeer(conn net.Conn) error {
+	var cred *unix.Ucred
+	var credErr error
+	if err := peerControl(conn, func(fd int) {
+		cred, credErr = unix.GetsockoptUcred(fd, unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return err
+	}
+	if credErr != nil || cred.Uid != uint32(os.Getuid()) {
+		return errPeer
+	}
+	return nil
+}
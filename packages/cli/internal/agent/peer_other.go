@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package agent
+
+import "net"
+
+// checkPeer relies on the permissions of the socket where peer credentials
+// are not supported
+func checkPeer(conn net.Conn) error {
+	return nil
+}
@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// FetchFunc pulls an environment from the API, decrypted
+type FetchFunc func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error)
+
+// Server keeps environments pulled with Fetch for TTL and serves them on a
+// listener until it is locked
+type Server struct {
+	fetch   FetchFunc
+	ttl     time.Duration
+	started time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry // by repo and env
+	locked  bool              // whether every buffer so far could be locked
+	closed  bool
+	ln      net.Listener
+	done    chan struct{}
+}
+
+// entry is an environment held by the server
+type entry struct {
+	repo, env string
+	content   *lockedBuffer
+	revision  string
+	fetched   time.Time
+}
+
+// NewServer returns a server that keeps environments for ttl (DefaultTTL
+// when zero)
+func NewServer(fetch FetchFunc, ttl time.Duration) *Server {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Server{
+		fetch:   fetch,
+		ttl:     ttl,
+		started: time.Now(),
+		entries: make(map[string]*entry),
+		locked:  true,
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve accepts connections on ln until Lock is called, and wipes what the
+// server holds before returning
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+
+	go s.expire()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.Lock()
+			select {
+			case <-s.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		go s.handle(conn)
+	}
+}
+
+// Lock wipes every environment and stops the server
+func (s *Server) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for key, e := range s.entries {
+		e.content.Destroy()
+		delete(s.entries, key)
+	}
+	close(s.done)
+	if s.ln != nil {
+		s.ln.Close()
+	}
+}
+
+// expire wipes environments once their TTL is over
+func (s *Server) expire() {
+	tick := s.ttl / 4
+	if tick < time.Second {
+		tick = time.Second
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		for key, e := range s.entries {
+			if time.Since(e.fetched) >= s.ttl {
+				e.content.Destroy()
+				delete(s.entries, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// handle answers the requests of a connection, one JSON line each
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	if err := checkPeer(conn); err != nil {
+		_ = json.NewEncoder(conn).Encode(response{Error: err.Error()})
+		return
+	}
+
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = enc.Encode(response{Error: "invalid request"})
+			return
+		}
+		_ = enc.Encode(s.answer(req))
+		if req.Op == "lock" {
+			return
+		}
+	}
+}
+
+// answer runs a request
+func (s *Server) answer(req request) response {
+	switch req.Op {
+	case "pull":
+		return s.pull(req.Repo, req.Env)
+	case "evict":
+		s.evict(req.Repo, req.Env)
+		return response{}
+	case "status":
+		return response{Status: s.status()}
+	case "lock":
+		s.Lock()
+		return response{}
+	default:
+		return response{Error: "unknown operation " + req.Op}
+	}
+}
+
+// pull returns an environment, fetching it when the server doesn't hold it
+func (s *Server) pull(repo, env string) response {
+	key := repo + "\x00" + env
+
+	s.mu.Lock()
+	if e, ok := s.entries[key]; ok && time.Since(e.fetched) < s.ttl {
+		resp := response{Content: string(e.content.Bytes()), Revision: e.revision}
+		s.mu.Unlock()
+		return resp
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pulled, err := s.fetch(ctx, repo, env)
+	if err != nil {
+		return response{Error: err.Error()}
+	}
+
+	// Keep only the locked copy: the response is built from it, and the
+	// fetched content is dropped so it isn't held past this request
+	buf, locked := newLockedBuffer([]byte(pulled.Content))
+	revision := pulled.Revision
+	pulled = nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		buf.Destroy()
+		return response{Error: ErrNotRunning.Error()}
+	}
+	if old, ok := s.entries[key]; ok {
+		old.content.Destroy()
+	}
+	s.entries[key] = &entry{repo: repo, env: env, content: buf, revision: revision, fetched: time.Now()}
+	s.locked = s.locked && locked
+	return response{Content: string(buf.Bytes()), Revision: revision}
+}
+
+// evict wipes an environment so the next pull fetches it again. An empty env
+// wipes every environment of the repository.
+func (s *Server) evict(repo, env string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.entries {
+		if e.repo == repo && (env == "" || e.env == env) {
+			e.content.Destroy()
+			delete(s.entries, key)
+		}
+	}
+}
+
+// status describes the server
+func (s *Server) status() *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &Status{PID: os.Getpid(), TTL: s.ttl, Started: s.started, Locked: s.locked, Entries: []Entry{}}
+	for _, e := range s.entries {
+		st.Entries = append(st.Entries, Entry{Repo: e.repo, Env: e.env, Fetched: e.fetched, Expires: e.fetched.Add(s.ttl)})
+	}
+	sort.Slice(st.Entries, func(i, j int) bool {
+		if st.Entries[i].Repo != st.Entries[j].Repo {
+			return st.Entries[i].Repo < st.Entries[j].Repo
+		}
+		return st.Entries[i].Env < st.Entries[j].Env
+	})
+	return st
+}
@@ -16,13 +16,25 @@ import (
 // Events constants for analytics tracking
 const (
 	// Core commands
-	EventLogin  = "cli_login"
-	EventInit   = "cli_init"
-	EventPush   = "cli_push"
-	EventPull   = "cli_pull"
-	EventDiff   = "cli_diff"
-	EventDoctor = "cli_doctor"
-	EventScan   = "cli_scan"
+	EventLogin   = "cli_login"
+	EventInit    = "cli_init"
+	EventPush    = "cli_push"
+	EventPull    = "cli_pull"
+	EventDiff    = "cli_diff"
+	EventDoctor  = "cli_doctor"
+	EventScan    = "cli_scan"
+	EventPromote = "cli_promote"
+	EventEnv     = "cli_env"
+	EventStatus  = "cli_status"
+	EventRender  = "cli_render"
+	EventE2E     = "cli_e2e"
+	EventSeal    = "cli_seal"
+	EventUnseal  = "cli_unseal"
+	EventAgent   = "cli_agent"
+	EventHooks   = "cli_hooks"
+
+	// Lockfile
+	EventLockVerify = "cli_lock_verify"
 
 	// Provider integration
 	EventConnect    = "cli_connect"
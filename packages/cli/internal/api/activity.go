@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ActivityEvent is one entry of a vault's activity log
+type ActivityEvent struct {
+	ID           string                 `json:"id"`
+	Action       string                 `json:"action"`
+	RepoFullName string                 `json:"repoFullName"`
+	Actor        UserRef                `json:"actor"`
+	Platform     string                 `json:"platform"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	Timestamp    time.Time              `json:"timestamp"`
+}
+
+// Environment returns the environment the event applies to, if any
+func (e ActivityEvent) Environment() string {
+	env, _ := e.Metadata["environment"].(string)
+	return env
+}
+
+// ActivityFilter narrows down a vault's activity log, on the server
+type ActivityFilter struct {
+	Environment string
+	// Actor is a username
+	Actor string
+	// Since keeps only the events strictly after it, when non-zero
+	Since time.Time
+}
+
+// ListVaultActivity returns the events of a vault's activity log that match
+// filter, newest first. Paging stops as soon as events older than
+// filter.Since are reached.
+func (c *Client) ListVaultActivity(ctx context.Context, repoFullName string, filter ActivityFilter) ([]ActivityEvent, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	var all []ActivityEvent
+	for offset := 0; ; offset += listPageSize {
+		params := url.Values{}
+		params.Set("limit", fmt.Sprintf("%d", listPageSize))
+		params.Set("offset", fmt.Sprintf("%d", offset))
+		if filter.Environment != "" {
+			params.Set("environment", filter.Environment)
+		}
+		if filter.Actor != "" {
+			params.Set("actor", filter.Actor)
+		}
+		if !filter.Since.IsZero() {
+			params.Set("since", filter.Since.UTC().Format(time.RFC3339Nano))
+		}
+
+		path := fmt.Sprintf("/v1/vaults/%s/%s/activity?%s", owner, repo, params.Encode())
+		var wrapper struct {
+			Data []ActivityEvent `json:"data"`
+			Meta paginationMeta  `json:"meta"`
+		}
+		if err := c.do(ctx, "GET", path, nil, &wrapper); err != nil {
+			return nil, err
+		}
+
+		for _, e := range wrapper.Data {
+			if !filter.Since.IsZero() && !e.Timestamp.After(filter.Since) {
+				return all, nil
+			}
+			all = append(all, e)
+		}
+		if !wrapper.Meta.Pagination.HasMore || len(wrapper.Data) == 0 {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_ListVaultActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/vaults/owner/repo/activity" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if q := r.URL.Query(); q.Has("environment") || q.Has("actor") || q.Has("since") {
+			t.Errorf("expected no filter, got %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{
+					"id":        "a1",
+					"action":    "secrets_pulled",
+					"actor":     map[string]interface{}{"id": "u1", "username": "alice"},
+					"platform":  "cli",
+					"metadata":  map[string]interface{}{"environment": "production", "secretCount": 3},
+					"timestamp": "2026-01-02T10:00:00Z",
+				},
+				{
+					"id":        "a2",
+					"action":    "vault_created",
+					"actor":     map[string]interface{}{"id": "u2", "username": "bob"},
+					"platform":  "web",
+					"metadata":  nil,
+					"timestamp": "2026-01-01T10:00:00Z",
+				},
+			},
+			"meta": map[string]interface{}{"pagination": map[string]interface{}{"hasMore": false}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	events, err := client.ListVaultActivity(context.Background(), "owner/repo", ActivityFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Actor.Username != "alice" || events[0].Environment() != "production" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Environment() != "" {
+		t.Errorf("expected no environment, got %q", events[1].Environment())
+	}
+}
+
+func TestClient_ListVaultActivity_StopsAtSince(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"id": "a1", "action": "secrets_pushed", "timestamp": "2026-01-03T10:00:00Z"},
+				{"id": "a2", "action": "secrets_pushed", "timestamp": "2026-01-01T10:00:00Z"},
+			},
+			"meta": map[string]interface{}{"pagination": map[string]interface{}{"hasMore": true}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	since := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	events, err := client.ListVaultActivity(context.Background(), "owner/repo", ActivityFilter{Since: since})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected paging to stop after 1 request, got %d", calls)
+	}
+	if len(events) != 1 || events[0].ID != "a1" {
+		t.Errorf("expected only a1, got %+v", events)
+	}
+}
+
+func TestClient_ListVaultActivity_InvalidRepo(t *testing.T) {
+	client := NewClient("token")
+	if _, err := client.ListVaultActivity(context.Background(), "invalid", ActivityFilter{}); err == nil {
+		t.Error("expected error for invalid repo format")
+	}
+}
+
+func TestClient_ListVaultActivity_SendsFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("environment") != "production" || q.Get("actor") != "alice" || q.Get("since") != "2026-01-02T00:00:00Z" {
+			t.Errorf("unexpected filter: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{},
+			"meta": map[string]interface{}{"pagination": map[string]interface{}{"hasMore": false}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	filter := ActivityFilter{Environment: "production", Actor: "alice", Since: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if _, err := client.ListVaultActivity(context.Background(), "owner/repo", filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
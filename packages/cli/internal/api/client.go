@@ -92,18 +92,25 @@ func (c *Client) SetTimeout(timeout time.Duration) {
 
 // do performs an HTTP request
 func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	_, err := c.doWithHeaders(ctx, method, path, nil, body, result)
+	return err
+}
+
+// doWithHeaders performs an HTTP request with extra headers and returns the
+// status code, so callers can handle non-error statuses like 304
+func (c *Client) doWithHeaders(ctx context.Context, method, path string, headers map[string]string, body, result interface{}) (int, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request: %w", err)
+			return 0, fmt.Errorf("failed to marshal request: %w", err)
 		}
 		bodyReader = bytes.NewReader(jsonBody)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -111,37 +118,40 @@ func (c *Client) do(ctx context.Context, method, path string, body, result inter
 	if c.token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return c.handleNetworkError(err)
+		return 0, c.handleNetworkError(err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
 		var apiErr APIError
 		if err := json.Unmarshal(respBody, &apiErr); err != nil {
-			return &APIError{
+			return resp.StatusCode, &APIError{
 				StatusCode: resp.StatusCode,
 				Detail:     string(respBody),
 			}
 		}
 		apiErr.StatusCode = resp.StatusCode
-		return &apiErr
+		return resp.StatusCode, &apiErr
 	}
 
 	if result != nil && len(respBody) > 0 {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return resp.StatusCode, fmt.Errorf("failed to unmarshal response: %w", err)
 		}
 	}
 
-	return nil
+	return resp.StatusCode, nil
 }
 
 // handleNetworkError converts network errors to user-friendly messages
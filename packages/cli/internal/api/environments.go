@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Environment is a vault environment
+type Environment struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+// ListEnvironments returns the environments of a vault in display order.
+// Unlike GetVaultEnvironments, errors are returned rather than replaced by defaults.
+func (c *Client) ListEnvironments(ctx context.Context, repoFullName string) ([]Environment, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments", owner, repo)
+	var wrapper struct {
+		Data struct {
+			Environments []Environment `json:"environments"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, "GET", path, nil, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.Data.Environments, nil
+}
+
+// CreateEnvironment adds an empty environment to a vault (admin only)
+func (c *Client) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments", owner, repo)
+	body := map[string]string{"name": name}
+	return c.do(ctx, "POST", path, body, nil)
+}
+
+// RenameEnvironment renames an environment, moving its secrets and permissions (admin only)
+func (c *Client) RenameEnvironment(ctx context.Context, repoFullName, oldName, newName string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments/%s", owner, repo, url.PathEscape(oldName))
+	body := map[string]string{"newName": newName}
+	return c.do(ctx, "PATCH", path, body, nil)
+}
+
+// DeleteEnvironment deletes an environment and all of its secrets (admin only)
+func (c *Client) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments/%s", owner, repo, url.PathEscape(name))
+	return c.do(ctx, "DELETE", path, nil, nil)
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListEnvironments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/vaults/owner/repo/environments" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"environments": []map[string]interface{}{
+					{"name": "development", "type": "development", "displayOrder": 0},
+					{"name": "production", "type": "protected", "displayOrder": 1},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	envs, err := client.ListEnvironments(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(envs) != 2 || envs[1].Name != "production" || envs[1].Type != "protected" {
+		t.Errorf("unexpected environments: %+v", envs)
+	}
+}
+
+func TestClient_ListEnvironments_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"detail": "Vault not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if _, err := client.ListEnvironments(context.Background(), "owner/repo"); err == nil {
+		t.Error("expected error to be returned, not defaults")
+	}
+}
+
+func TestClient_EnvironmentMutations(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(c *Client) error
+		wantMethod string
+		wantPath   string
+		wantBody   map[string]string
+	}{
+		{
+			name:       "create",
+			call:       func(c *Client) error { return c.CreateEnvironment(context.Background(), "owner/repo", "preview") },
+			wantMethod: "POST",
+			wantPath:   "/v1/vaults/owner/repo/environments",
+			wantBody:   map[string]string{"name": "preview"},
+		},
+		{
+			name:       "rename",
+			call:       func(c *Client) error { return c.RenameEnvironment(context.Background(), "owner/repo", "staging", "qa") },
+			wantMethod: "PATCH",
+			wantPath:   "/v1/vaults/owner/repo/environments/staging",
+			wantBody:   map[string]string{"newName": "qa"},
+		},
+		{
+			name:       "delete",
+			call:       func(c *Client) error { return c.DeleteEnvironment(context.Background(), "owner/repo", "preview") },
+			wantMethod: "DELETE",
+			wantPath:   "/v1/vaults/owner/repo/environments/preview",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != tt.wantMethod {
+					t.Errorf("expected %s, got %s", tt.wantMethod, r.Method)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("expected path %s, got %s", tt.wantPath, r.URL.Path)
+				}
+				if tt.wantBody != nil {
+					var body map[string]string
+					json.NewDecoder(r.Body).Decode(&body)
+					for k, v := range tt.wantBody {
+						if body[k] != v {
+							t.Errorf("expected body %s=%s, got %v", k, v, body)
+						}
+					}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{}})
+			}))
+			defer server.Close()
+
+			client := NewClient("token")
+			client.baseURL = server.URL
+
+			if err := tt.call(client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_GetVaultDetails_Syncs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":           "vault-1",
+				"repoFullName": "owner/repo",
+				"secretCount":  3,
+				"syncs": []map[string]interface{}{
+					{
+						"id":                  "sync-1",
+						"provider":            "vercel",
+						"projectId":           "prj_1",
+						"projectName":         "web",
+						"connectionId":        "conn-1",
+						"keywayEnvironment":   "production",
+						"providerEnvironment": "production",
+						"lastSyncedAt":        nil,
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	details, err := client.GetVaultDetails(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(details.Syncs) != 1 || details.Syncs[0].KeywayEnvironment != "production" || details.Syncs[0].LastSyncedAt != nil {
+		t.Errorf("unexpected syncs: %+v", details.Syncs)
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// listPageSize is the page size used when walking paginated list endpoints
+const listPageSize = 100
+
+// UserRef identifies the user behind a change
+type UserRef struct {
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
+// SecretItem is a secret's metadata as returned by the vault secrets list (no value)
+type SecretItem struct {
+	ID             string    `json:"id"`
+	Key            string    `json:"key"`
+	Environment    string    `json:"environment"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	LastModifiedBy *UserRef  `json:"lastModifiedBy"`
+}
+
+// SecretVersion is a previous value of a secret (metadata only).
+// A version is saved at the moment its value gets replaced, so CreatedAt is
+// when this value stopped being current and CreatedBy is who replaced it.
+type SecretVersion struct {
+	ID            string    `json:"id"`
+	VersionNumber int       `json:"versionNumber"`
+	CreatedAt     time.Time `json:"createdAt"`
+	CreatedBy     *UserRef  `json:"createdBy"`
+}
+
+// RestoreVersionResponse is the response from restoring a secret version
+type RestoreVersionResponse struct {
+	Message       string `json:"message"`
+	Key           string `json:"key"`
+	VersionNumber int    `json:"versionNumber"`
+}
+
+// TrashedSecret is a soft-deleted secret that can still be restored
+type TrashedSecret struct {
+	ID          string    `json:"id"`
+	Key         string    `json:"key"`
+	Environment string    `json:"environment"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// paginationMeta mirrors the pagination metadata of list responses
+type paginationMeta struct {
+	Pagination struct {
+		Total   int  `json:"total"`
+		HasMore bool `json:"hasMore"`
+	} `json:"pagination"`
+}
+
+// ListSecrets returns metadata for every active secret in a vault, across all environments
+func (c *Client) ListSecrets(ctx context.Context, repoFullName string) ([]SecretItem, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	var all []SecretItem
+	for offset := 0; ; offset += listPageSize {
+		params := url.Values{}
+		params.Set("limit", fmt.Sprintf("%d", listPageSize))
+		params.Set("offset", fmt.Sprintf("%d", offset))
+
+		path := fmt.Sprintf("/v1/vaults/%s/%s/secrets?%s", owner, repo, params.Encode())
+		var wrapper struct {
+			Data []SecretItem   `json:"data"`
+			Meta paginationMeta `json:"meta"`
+		}
+		if err := c.do(ctx, "GET", path, nil, &wrapper); err != nil {
+			return nil, err
+		}
+
+		all = append(all, wrapper.Data...)
+		if !wrapper.Meta.Pagination.HasMore || len(wrapper.Data) == 0 {
+			return all, nil
+		}
+	}
+}
+
+// GetSecretVersions returns the version history of a secret, newest first
+func (c *Client) GetSecretVersions(ctx context.Context, repoFullName, secretID string) ([]SecretVersion, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/secrets/%s/versions", owner, repo, url.PathEscape(secretID))
+	var wrapper struct {
+		Data struct {
+			Versions []SecretVersion `json:"versions"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, "GET", path, nil, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.Data.Versions, nil
+}
+
+// RestoreSecretVersion restores a secret to a previous version.
+// The current value is kept in history, so a restore can itself be undone.
+func (c *Client) RestoreSecretVersion(ctx context.Context, repoFullName, secretID, versionID string) (*RestoreVersionResponse, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/secrets/%s/versions/%s/restore",
+		owner, repo, url.PathEscape(secretID), url.PathEscape(versionID))
+	var wrapper struct {
+		Data RestoreVersionResponse `json:"data"`
+	}
+	// Send empty object as body (backend expects JSON)
+	if err := c.do(ctx, "POST", path, struct{}{}, &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Data, nil
+}
+
+// ListTrashedSecrets returns every soft-deleted secret of a vault that can still be restored
+func (c *Client) ListTrashedSecrets(ctx context.Context, repoFullName string) ([]TrashedSecret, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	var all []TrashedSecret
+	for offset := 0; ; offset += listPageSize {
+		params := url.Values{}
+		params.Set("limit", fmt.Sprintf("%d", listPageSize))
+		params.Set("offset", fmt.Sprintf("%d", offset))
+
+		path := fmt.Sprintf("/v1/vaults/%s/%s/trash?%s", owner, repo, params.Encode())
+		var wrapper struct {
+			Data []TrashedSecret `json:"data"`
+			Meta paginationMeta  `json:"meta"`
+		}
+		if err := c.do(ctx, "GET", path, nil, &wrapper); err != nil {
+			return nil, err
+		}
+
+		all = append(all, wrapper.Data...)
+		if !wrapper.Meta.Pagination.HasMore || len(wrapper.Data) == 0 {
+			return all, nil
+		}
+	}
+}
+
+// RestoreTrashedSecret restores a soft-deleted secret from the trash
+func (c *Client) RestoreTrashedSecret(ctx context.Context, repoFullName, secretID string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/trash/%s/restore", owner, repo, url.PathEscape(secretID))
+	return c.do(ctx, "POST", path, struct{}{}, nil)
+}
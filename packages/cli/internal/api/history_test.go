@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListSecrets_Paginates(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/v1/vaults/owner/repo/secrets" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("limit") != "100" {
+			t.Errorf("expected limit=100, got %s", r.URL.Query().Get("limit"))
+		}
+
+		offset := r.URL.Query().Get("offset")
+		var data []map[string]interface{}
+		hasMore := false
+		switch offset {
+		case "0":
+			data = []map[string]interface{}{
+				{"id": "s1", "key": "API_KEY", "environment": "production", "createdAt": "2026-01-01T10:00:00Z", "updatedAt": "2026-01-02T10:00:00Z", "lastModifiedBy": map[string]interface{}{"username": "alice"}},
+			}
+			hasMore = true
+		case "100":
+			data = []map[string]interface{}{
+				{"id": "s2", "key": "DB_URL", "environment": "staging", "createdAt": "2026-01-01T10:00:00Z", "updatedAt": "2026-01-01T10:00:00Z", "lastModifiedBy": nil},
+			}
+		default:
+			t.Errorf("unexpected offset: %s", offset)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": data,
+			"meta": map[string]interface{}{
+				"pagination": map[string]interface{}{"total": 2, "limit": 100, "offset": offset, "hasMore": hasMore},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	items, err := client.ListSecrets(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].LastModifiedBy == nil || items[0].LastModifiedBy.Username != "alice" {
+		t.Errorf("expected lastModifiedBy alice, got %+v", items[0].LastModifiedBy)
+	}
+	if items[0].UpdatedAt.Day() != 2 {
+		t.Errorf("expected updatedAt to be parsed, got %v", items[0].UpdatedAt)
+	}
+	if items[1].LastModifiedBy != nil {
+		t.Errorf("expected nil lastModifiedBy, got %+v", items[1].LastModifiedBy)
+	}
+}
+
+func TestClient_ListSecrets_InvalidRepo(t *testing.T) {
+	client := NewClient("token")
+	if _, err := client.ListSecrets(context.Background(), "invalid"); err == nil {
+		t.Error("expected error for invalid repo format")
+	}
+}
+
+func TestClient_GetSecretVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if r.URL.Path != "/v1/vaults/owner/repo/secrets/s1/versions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"versions": []map[string]interface{}{
+					{"id": "v2", "versionNumber": 2, "createdAt": "2026-01-03T10:00:00Z", "createdBy": map[string]interface{}{"username": "bob"}},
+					{"id": "v1", "versionNumber": 1, "createdAt": "2026-01-02T10:00:00Z", "createdBy": nil},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	versions, err := client.GetSecretVersions(context.Background(), "owner/repo", "s1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].VersionNumber != 2 || versions[0].CreatedBy.Username != "bob" {
+		t.Errorf("unexpected first version: %+v", versions[0])
+	}
+}
+
+func TestClient_RestoreSecretVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/v1/vaults/owner/repo/secrets/s1/versions/v1/restore" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"message":       "Restored to version 1",
+				"key":           "API_KEY",
+				"versionNumber": 1,
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	resp, err := client.RestoreSecretVersion(context.Background(), "owner/repo", "s1", "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Key != "API_KEY" || resp.VersionNumber != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestClient_RestoreSecretVersion_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"title": "Not Found", "detail": "Version not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	_, err := client.RestoreSecretVersion(context.Background(), "owner/repo", "s1", "missing")
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != 404 {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestClient_ListTrashedSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/vaults/owner/repo/trash" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"id": "t1", "key": "OLD_KEY", "environment": "production", "deletedAt": "2026-01-05T10:00:00Z", "expiresAt": "2026-02-04T10:00:00Z"},
+			},
+			"meta": map[string]interface{}{
+				"pagination": map[string]interface{}{"total": 1, "hasMore": false},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	trashed, err := client.ListTrashedSecrets(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].Key != "OLD_KEY" {
+		t.Errorf("unexpected trashed secrets: %+v", trashed)
+	}
+}
+
+func TestClient_RestoreTrashedSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/v1/vaults/owner/repo/trash/t1/restore" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"id": "t1"}})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.RestoreTrashedSecret(context.Background(), "owner/repo", "t1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -18,12 +18,34 @@ type APIClient interface {
 	GetVaultDetails(ctx context.Context, repoFullName string) (*VaultDetails, error)
 	GetVaultEnvironments(ctx context.Context, repoFullName string) ([]string, error)
 
+	// End-to-end encryption methods
+	GetVaultKeys(ctx context.Context, repoFullName string) (*VaultKeys, error)
+	RegisterPublicKey(ctx context.Context, repoFullName, publicKey string) error
+	PutVaultKeys(ctx context.Context, repoFullName string, keys []WrappedKey, enable bool) error
+
+	// Environment methods
+	ListEnvironments(ctx context.Context, repoFullName string) ([]Environment, error)
+	CreateEnvironment(ctx context.Context, repoFullName, name string) error
+	RenameEnvironment(ctx context.Context, repoFullName, oldName, newName string) error
+	DeleteEnvironment(ctx context.Context, repoFullName, name string) error
+
 	// Org methods
 	StartOrganizationTrial(ctx context.Context, orgLogin string) (*StartTrialResponse, error)
 
 	// Secrets methods
 	PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error)
 	PullSecrets(ctx context.Context, repo, env string) (*PullSecretsResponse, error)
+	PullSecretsIfChanged(ctx context.Context, repo, env, revision string) (*PullSecretsResponse, error)
+
+	// History methods
+	ListSecrets(ctx context.Context, repoFullName string) ([]SecretItem, error)
+	GetSecretVersions(ctx context.Context, repoFullName, secretID string) ([]SecretVersion, error)
+	RestoreSecretVersion(ctx context.Context, repoFullName, secretID, versionID string) (*RestoreVersionResponse, error)
+	ListTrashedSecrets(ctx context.Context, repoFullName string) ([]TrashedSecret, error)
+	RestoreTrashedSecret(ctx context.Context, repoFullName, secretID string) error
+
+	// Activity methods
+	ListVaultActivity(ctx context.Context, repoFullName string, filter ActivityFilter) ([]ActivityEvent, error)
 
 	// Provider methods
 	GetProviders(ctx context.Context) ([]Provider, error)
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// VaultKeys is the end-to-end encryption state of a vault, as seen by the caller
+type VaultKeys struct {
+	E2EEnabled bool        `json:"e2eEnabled"`
+	WrappedKey string      `json:"wrappedKey"` // vault key sealed to the caller's public key, empty until granted
+	Members    []MemberKey `json:"members"`
+}
+
+// MemberKey is a member's registered public key for a vault
+type MemberKey struct {
+	UserID    string `json:"userId"`
+	Username  string `json:"username"`
+	PublicKey string `json:"publicKey"`
+	Granted   bool   `json:"granted"`
+}
+
+// WrappedKey is the vault key sealed to one member's public key
+type WrappedKey struct {
+	UserID     string `json:"userId"`
+	WrappedKey string `json:"wrappedKey"`
+}
+
+// GetVaultKeys returns the end-to-end encryption state of a vault
+func (c *Client) GetVaultKeys(ctx context.Context, repoFullName string) (*VaultKeys, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	var wrapper struct {
+		Data VaultKeys `json:"data"`
+	}
+	path := fmt.Sprintf("/v1/vaults/%s/%s/keys", owner, repo)
+	if err := c.do(ctx, "GET", path, nil, &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Data, nil
+}
+
+// RegisterPublicKey registers the caller's public key for a vault, so members
+// holding the vault key can share it with them
+func (c *Client) RegisterPublicKey(ctx context.Context, repoFullName, publicKey string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/keys/me", owner, repo)
+	return c.do(ctx, "PUT", path, map[string]string{"publicKey": publicKey}, nil)
+}
+
+// PutVaultKeys stores the vault key sealed to members' public keys. With
+// enable, it also turns end-to-end encryption on (admin only).
+func (c *Client) PutVaultKeys(ctx context.Context, repoFullName string, keys []WrappedKey, enable bool) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	if keys == nil {
+		keys = []WrappedKey{}
+	}
+	body := map[string]interface{}{"wrappedKeys": keys}
+	if enable {
+		body["enable"] = true
+	}
+	path := fmt.Sprintf("/v1/vaults/%s/%s/keys", owner, repo)
+	return c.do(ctx, "PUT", path, body, nil)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_GetVaultKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/vaults/owner/repo/keys" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"e2eEnabled": true,
+				"wrappedKey": "sealed",
+				"members": []map[string]interface{}{
+					{"userId": "u1", "username": "alice", "publicKey": "pk", "granted": false},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	keys, err := client.GetVaultKeys(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !keys.E2EEnabled || keys.WrappedKey != "sealed" || len(keys.Members) != 1 || keys.Members[0].Username != "alice" {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+}
+
+func TestClient_KeyMutations(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c *Client) error
+		wantPath string
+		wantBody string
+	}{
+		{
+			name:     "register",
+			call:     func(c *Client) error { return c.RegisterPublicKey(context.Background(), "owner/repo", "pk") },
+			wantPath: "/v1/vaults/owner/repo/keys/me",
+			wantBody: `{"publicKey":"pk"}`,
+		},
+		{
+			name: "enable",
+			call: func(c *Client) error {
+				return c.PutVaultKeys(context.Background(), "owner/repo", []WrappedKey{{UserID: "u1", WrappedKey: "w"}}, true)
+			},
+			wantPath: "/v1/vaults/owner/repo/keys",
+			wantBody: `{"enable":true,"wrappedKeys":[{"userId":"u1","wrappedKey":"w"}]}`,
+		},
+		{
+			name:     "grant",
+			call:     func(c *Client) error { return c.PutVaultKeys(context.Background(), "owner/repo", nil, false) },
+			wantPath: "/v1/vaults/owner/repo/keys",
+			wantBody: `{"wrappedKeys":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "PUT" || r.URL.Path != tt.wantPath {
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+				var body interface{}
+				json.NewDecoder(r.Body).Decode(&body)
+				got, _ := json.Marshal(body)
+				if string(got) != tt.wantBody {
+					t.Errorf("body = %s, want %s", got, tt.wantBody)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			client := NewClient("token")
+			client.baseURL = server.URL
+			if err := tt.call(client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
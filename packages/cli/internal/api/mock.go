@@ -20,9 +20,31 @@ type MockClient struct {
 	GetVaultDetailsFn      func(ctx context.Context, repoFullName string) (*VaultDetails, error)
 	GetVaultEnvironmentsFn func(ctx context.Context, repoFullName string) ([]string, error)
 
+	// End-to-end encryption mocks
+	GetVaultKeysFn      func(ctx context.Context, repoFullName string) (*VaultKeys, error)
+	RegisterPublicKeyFn func(ctx context.Context, repoFullName, publicKey string) error
+	PutVaultKeysFn      func(ctx context.Context, repoFullName string, keys []WrappedKey, enable bool) error
+
+	// Environment mocks
+	ListEnvironmentsFn  func(ctx context.Context, repoFullName string) ([]Environment, error)
+	CreateEnvironmentFn func(ctx context.Context, repoFullName, name string) error
+	RenameEnvironmentFn func(ctx context.Context, repoFullName, oldName, newName string) error
+	DeleteEnvironmentFn func(ctx context.Context, repoFullName, name string) error
+
 	// Secrets mocks
-	PushSecretsFn func(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error)
-	PullSecretsFn func(ctx context.Context, repo, env string) (*PullSecretsResponse, error)
+	PushSecretsFn          func(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error)
+	PullSecretsFn          func(ctx context.Context, repo, env string) (*PullSecretsResponse, error)
+	PullSecretsIfChangedFn func(ctx context.Context, repo, env, revision string) (*PullSecretsResponse, error)
+
+	// History mocks
+	ListSecretsFn          func(ctx context.Context, repoFullName string) ([]SecretItem, error)
+	GetSecretVersionsFn    func(ctx context.Context, repoFullName, secretID string) ([]SecretVersion, error)
+	RestoreSecretVersionFn func(ctx context.Context, repoFullName, secretID, versionID string) (*RestoreVersionResponse, error)
+	ListTrashedSecretsFn   func(ctx context.Context, repoFullName string) ([]TrashedSecret, error)
+	RestoreTrashedSecretFn func(ctx context.Context, repoFullName, secretID string) error
+
+	// Activity mocks
+	ListVaultActivityFn func(ctx context.Context, repoFullName string, filter ActivityFilter) ([]ActivityEvent, error)
 
 	// Provider mocks
 	GetProvidersFn           func(ctx context.Context) ([]Provider, error)
@@ -155,6 +177,68 @@ func (m *MockClient) GetVaultEnvironments(ctx context.Context, repoFullName stri
 	return []string{"production", "staging", "development"}, nil
 }
 
+// End-to-end encryption methods
+func (m *MockClient) GetVaultKeys(ctx context.Context, repoFullName string) (*VaultKeys, error) {
+	m.track("GetVaultKeys")
+	if m.GetVaultKeysFn != nil {
+		return m.GetVaultKeysFn(ctx, repoFullName)
+	}
+	return &VaultKeys{}, nil
+}
+
+func (m *MockClient) RegisterPublicKey(ctx context.Context, repoFullName, publicKey string) error {
+	m.track("RegisterPublicKey")
+	if m.RegisterPublicKeyFn != nil {
+		return m.RegisterPublicKeyFn(ctx, repoFullName, publicKey)
+	}
+	return nil
+}
+
+func (m *MockClient) PutVaultKeys(ctx context.Context, repoFullName string, keys []WrappedKey, enable bool) error {
+	m.track("PutVaultKeys")
+	if m.PutVaultKeysFn != nil {
+		return m.PutVaultKeysFn(ctx, repoFullName, keys, enable)
+	}
+	return nil
+}
+
+// Environment methods
+func (m *MockClient) ListEnvironments(ctx context.Context, repoFullName string) ([]Environment, error) {
+	m.track("ListEnvironments")
+	if m.ListEnvironmentsFn != nil {
+		return m.ListEnvironmentsFn(ctx, repoFullName)
+	}
+	return []Environment{
+		{Name: "development", Type: "development", DisplayOrder: 0},
+		{Name: "staging", Type: "standard", DisplayOrder: 1},
+		{Name: "production", Type: "protected", DisplayOrder: 2},
+	}, nil
+}
+
+func (m *MockClient) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	m.track("CreateEnvironment")
+	if m.CreateEnvironmentFn != nil {
+		return m.CreateEnvironmentFn(ctx, repoFullName, name)
+	}
+	return nil
+}
+
+func (m *MockClient) RenameEnvironment(ctx context.Context, repoFullName, oldName, newName string) error {
+	m.track("RenameEnvironment")
+	if m.RenameEnvironmentFn != nil {
+		return m.RenameEnvironmentFn(ctx, repoFullName, oldName, newName)
+	}
+	return nil
+}
+
+func (m *MockClient) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	m.track("DeleteEnvironment")
+	if m.DeleteEnvironmentFn != nil {
+		return m.DeleteEnvironmentFn(ctx, repoFullName, name)
+	}
+	return nil
+}
+
 // Secrets methods
 func (m *MockClient) PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error) {
 	m.track("PushSecrets")
@@ -186,6 +270,64 @@ func (m *MockClient) PullSecrets(ctx context.Context, repo, env string) (*PullSe
 	}, nil
 }
 
+func (m *MockClient) PullSecretsIfChanged(ctx context.Context, repo, env, revision string) (*PullSecretsResponse, error) {
+	m.track("PullSecretsIfChanged")
+	if m.PullSecretsIfChangedFn != nil {
+		return m.PullSecretsIfChangedFn(ctx, repo, env, revision)
+	}
+	return m.PullSecrets(ctx, repo, env)
+}
+
+// History methods
+func (m *MockClient) ListSecrets(ctx context.Context, repoFullName string) ([]SecretItem, error) {
+	m.track("ListSecrets")
+	if m.ListSecretsFn != nil {
+		return m.ListSecretsFn(ctx, repoFullName)
+	}
+	return []SecretItem{}, nil
+}
+
+func (m *MockClient) GetSecretVersions(ctx context.Context, repoFullName, secretID string) ([]SecretVersion, error) {
+	m.track("GetSecretVersions")
+	if m.GetSecretVersionsFn != nil {
+		return m.GetSecretVersionsFn(ctx, repoFullName, secretID)
+	}
+	return []SecretVersion{}, nil
+}
+
+func (m *MockClient) RestoreSecretVersion(ctx context.Context, repoFullName, secretID, versionID string) (*RestoreVersionResponse, error) {
+	m.track("RestoreSecretVersion")
+	if m.RestoreSecretVersionFn != nil {
+		return m.RestoreSecretVersionFn(ctx, repoFullName, secretID, versionID)
+	}
+	return &RestoreVersionResponse{Message: "Restored"}, nil
+}
+
+func (m *MockClient) ListTrashedSecrets(ctx context.Context, repoFullName string) ([]TrashedSecret, error) {
+	m.track("ListTrashedSecrets")
+	if m.ListTrashedSecretsFn != nil {
+		return m.ListTrashedSecretsFn(ctx, repoFullName)
+	}
+	return []TrashedSecret{}, nil
+}
+
+func (m *MockClient) RestoreTrashedSecret(ctx context.Context, repoFullName, secretID string) error {
+	m.track("RestoreTrashedSecret")
+	if m.RestoreTrashedSecretFn != nil {
+		return m.RestoreTrashedSecretFn(ctx, repoFullName, secretID)
+	}
+	return nil
+}
+
+// Activity methods
+func (m *MockClient) ListVaultActivity(ctx context.Context, repoFullName string, filter ActivityFilter) ([]ActivityEvent, error) {
+	m.track("ListVaultActivity")
+	if m.ListVaultActivityFn != nil {
+		return m.ListVaultActivityFn(ctx, repoFullName, filter)
+	}
+	return []ActivityEvent{}, nil
+}
+
 // Provider methods
 func (m *MockClient) GetProviders(ctx context.Context) ([]Provider, error) {
 	m.track("GetProviders")
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 )
 
@@ -18,7 +19,9 @@ type PushSecretsResponse struct {
 
 // PullSecretsResponse is the response from pulling secrets
 type PullSecretsResponse struct {
-	Content string `json:"content"`
+	Content    string `json:"content"`
+	Revision   string `json:"revision,omitempty"`   // opaque, changes whenever the environment does
+	E2EEnabled bool   `json:"e2eEnabled,omitempty"` // values are encrypted with the vault key
 }
 
 // PushSecrets uploads secrets to the vault
@@ -48,3 +51,29 @@ func (c *Client) PullSecrets(ctx context.Context, repo, env string) (*PullSecret
 	err := c.do(ctx, "GET", "/v1/secrets/pull?"+params.Encode(), nil, &wrapper)
 	return &wrapper.Data, err
 }
+
+// PullSecretsIfChanged downloads secrets unless the environment is still at
+// revision, in which case it returns nil. The server answers such requests
+// with 304 without decrypting anything or logging a pull.
+func (c *Client) PullSecretsIfChanged(ctx context.Context, repo, env, revision string) (*PullSecretsResponse, error) {
+	params := url.Values{}
+	params.Set("repo", repo)
+	params.Set("environment", env)
+
+	var headers map[string]string
+	if revision != "" {
+		headers = map[string]string{"If-None-Match": `"` + revision + `"`}
+	}
+
+	var wrapper struct {
+		Data PullSecretsResponse `json:"data"`
+	}
+	status, err := c.doWithHeaders(ctx, "GET", "/v1/secrets/pull?"+params.Encode(), headers, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotModified {
+		return nil, nil
+	}
+	return &wrapper.Data, nil
+}
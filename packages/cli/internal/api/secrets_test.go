@@ -177,7 +177,8 @@ func TestClient_PullSecrets_Success(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"data": map[string]interface{}{
-				"content": "API_KEY=secret123\nDB_URL=postgres://localhost",
+				"content":  "API_KEY=secret123\nDB_URL=postgres://localhost",
+				"revision": "0123456789abcdef",
 			},
 		})
 	}))
@@ -197,6 +198,9 @@ func TestClient_PullSecrets_Success(t *testing.T) {
 	if resp.Content != "API_KEY=secret123\nDB_URL=postgres://localhost" {
 		t.Errorf("unexpected content: %s", resp.Content)
 	}
+	if resp.Revision != "0123456789abcdef" {
+		t.Errorf("unexpected revision: %s", resp.Revision)
+	}
 }
 
 func TestClient_PullSecrets_EmptyVault(t *testing.T) {
@@ -320,3 +324,38 @@ func TestClient_PushSecrets_SpecialCharacters(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestClient_PullSecretsIfChanged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"rev1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("unexpected If-None-Match: %s", r.Header.Get("If-None-Match"))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"content": "API_KEY=new", "revision": "rev2"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	resp, err := client.PullSecretsIfChanged(context.Background(), "owner/repo", "development", "rev1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != nil {
+		t.Errorf("expected nil response when not modified, got %+v", resp)
+	}
+
+	resp, err = client.PullSecretsIfChanged(context.Background(), "owner/repo", "development", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp == nil || resp.Revision != "rev2" || resp.Content != "API_KEY=new" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // InitVaultResponse is the response from initializing a vault
@@ -21,9 +22,22 @@ type VaultInfo struct {
 
 // VaultDetails contains detailed vault information including secret count
 type VaultDetails struct {
-	ID           string `json:"id"`
-	RepoFullName string `json:"repoFullName"`
-	SecretCount  int    `json:"secretCount"`
+	ID           string      `json:"id"`
+	RepoFullName string      `json:"repoFullName"`
+	SecretCount  int         `json:"secretCount"`
+	Syncs        []VaultSync `json:"syncs"`
+}
+
+// VaultSync is a sync link between a vault environment and a provider project
+type VaultSync struct {
+	ID                  string     `json:"id"`
+	Provider            string     `json:"provider"`
+	ProjectID           string     `json:"projectId"`
+	ProjectName         string     `json:"projectName"`
+	ConnectionID        string     `json:"connectionId"`
+	KeywayEnvironment   string     `json:"keywayEnvironment"`
+	ProviderEnvironment string     `json:"providerEnvironment"`
+	LastSyncedAt        *time.Time `json:"lastSyncedAt"`
 }
 
 // InitVault creates a new vault for a repository
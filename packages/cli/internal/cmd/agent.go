@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/keywaysh/cli/internal/agent"
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Keep pulled secrets in a local agent for faster keyway run",
+	Long: `Like ssh-agent, the keyway agent runs in the background and keeps the
+environments it pulled in memory for a while (15 minutes by default). keyway run
+asks it first, which skips the API round trip and end-to-end decryption:
+repeated runs in a test loop start almost instantly.
+
+The agent only serves the user that started it, over a Unix socket in
+$XDG_RUNTIME_DIR/keyway or ~/.config/keyway ($KEYWAY_AGENT_SOCK to override).
+The copy of each environment the agent keeps is locked against swapping and
+left out of core dumps; the short-lived copies used to answer a request are
+not. Commands that change the vault make the agent forget the environments
+they touched. keyway agent lock wipes everything and stops the agent.
+
+Examples:
+  keyway agent start
+  keyway agent start --ttl 1h
+  keyway agent status
+  keyway agent lock`,
+}
+
+var agentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the agent in the background",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentStart,
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the agent runs and what it holds",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentStatus,
+}
+
+var agentLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Wipe the secrets held by the agent and stop it",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentLock,
+}
+
+func init() {
+	agentStartCmd.Flags().Duration("ttl", agent.DefaultTTL, "How long the agent keeps an environment before pulling it again")
+	agentStartCmd.Flags().Bool("foreground", false, "Run the agent in this terminal instead of the background")
+
+	agentCmd.AddCommand(agentStartCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentLockCmd)
+}
+
+// AgentOptions contains the parsed flags for the agent subcommands
+type AgentOptions struct {
+	TTL        time.Duration
+	Foreground bool
+}
+
+// runAgentStart is the entry point for the agent start command (uses default dependencies)
+func runAgentStart(cmd *cobra.Command, args []string) error {
+	opts := AgentOptions{}
+	opts.TTL, _ = cmd.Flags().GetDuration("ttl")
+	opts.Foreground, _ = cmd.Flags().GetBool("foreground")
+	if opts.Foreground {
+		return serveAgent(opts, defaultDeps)
+	}
+	return runAgentStartWithDeps(opts, defaultDeps)
+}
+
+// runAgentStatus is the entry point for the agent status command (uses default dependencies)
+func runAgentStatus(cmd *cobra.Command, args []string) error {
+	return runAgentStatusWithDeps(AgentOptions{}, defaultDeps)
+}
+
+// runAgentLock is the entry point for the agent lock command (uses default dependencies)
+func runAgentLock(cmd *cobra.Command, args []string) error {
+	return runAgentLockWithDeps(AgentOptions{}, defaultDeps)
+}
+
+// runAgentStartWithDeps is the testable version of runAgentStart
+func runAgentStartWithDeps(opts AgentOptions, deps *Dependencies) error {
+	deps.UI.Intro("agent")
+
+	if opts.TTL <= 0 {
+		opts.TTL = agent.DefaultTTL
+	}
+	if deps.Agent.Available() {
+		deps.UI.Success("An agent is already running")
+		deps.UI.Outro("Restart it with keyway agent lock, then keyway agent start")
+		return nil
+	}
+
+	// Log in now: the agent can't ask for it once in the background
+	if _, err := deps.Auth.EnsureLogin(); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	var pid int
+	err := deps.UI.Spin("Starting agent...", func() error {
+		var err error
+		pid, err = deps.Agent.Start(opts.TTL)
+		return err
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	analytics.Track(analytics.EventAgent, map[string]interface{}{
+		"ttlSeconds": int(opts.TTL.Seconds()),
+	})
+
+	deps.UI.Success(fmt.Sprintf("Agent started (pid %d)", pid))
+	deps.UI.Step(fmt.Sprintf("Environments are kept for %s", deps.UI.Value(opts.TTL)))
+	deps.UI.Outro("keyway run now pulls through the agent - wipe it with keyway agent lock")
+	return nil
+}
+
+// runAgentStatusWithDeps is the testable version of runAgentStatus
+func runAgentStatusWithDeps(opts AgentOptions, deps *Dependencies) error {
+	deps.UI.Intro("agent status")
+
+	if !deps.Agent.Available() {
+		deps.UI.Info("No agent is running - start one with keyway agent start")
+		return nil
+	}
+	status, err := deps.Agent.Status(context.Background())
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Step(fmt.Sprintf("PID: %s", deps.UI.Value(status.PID)))
+	deps.UI.Step(fmt.Sprintf("TTL: %s", deps.UI.Value(status.TTL)))
+	if !status.Locked {
+		deps.UI.Warn("Memory could not be locked (see ulimit -l): secrets may be swapped to disk")
+	}
+	if len(status.Entries) == 0 {
+		deps.UI.Info("No environments held yet")
+		return nil
+	}
+	deps.UI.Message("")
+	for _, e := range status.Entries {
+		left := time.Until(e.Expires).Round(time.Second)
+		deps.UI.Message(fmt.Sprintf("  %s %s %s", e.Repo, deps.UI.Value(e.Env), deps.UI.Dim(fmt.Sprintf("(expires in %s)", left))))
+	}
+	return nil
+}
+
+// runAgentLockWithDeps is the testable version of runAgentLock
+func runAgentLockWithDeps(opts AgentOptions, deps *Dependencies) error {
+	deps.UI.Intro("agent lock")
+
+	if !deps.Agent.Available() {
+		deps.UI.Info("No agent is running")
+		return nil
+	}
+	if err := deps.Agent.Lock(context.Background()); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	deps.UI.Success("Agent locked: its secrets are wiped and it has stopped")
+	return nil
+}
+
+// evictAgentCache makes a running agent forget an environment a command just
+// changed in the vault, so the next keyway run doesn't get the old values. An
+// empty env evicts every environment of the repository. Best effort: the
+// write already succeeded, so a failure is only a warning.
+func evictAgentCache(deps *Dependencies, repo, env string) {
+	err := deps.Agent.Evict(context.Background(), repo, env)
+	if err != nil && !errors.Is(err, agent.ErrNotRunning) {
+		deps.UI.Warn(fmt.Sprintf("The agent may serve outdated secrets (%v) - run keyway agent lock", err))
+	}
+}
+
+// serveAgent runs the agent in this process until it is locked or
+// interrupted
+func serveAgent(opts AgentOptions, deps *Dependencies) error {
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	client := deps.APIFactory.NewClient(token)
+
+	path, err := agent.SocketPath()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	ln, err := agent.Listen(path)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	srv := agent.NewServer(client.PullSecrets, opts.TTL)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		if _, ok := <-sigs; ok {
+			srv.Lock()
+		}
+	}()
+
+	deps.UI.Success(fmt.Sprintf("Agent listening on %s", deps.UI.File(path)))
+	return srv.Serve(ln)
+}
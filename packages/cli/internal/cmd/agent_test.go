@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/agent"
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunAgentStartWithDeps_Starts(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.StartPID = 4242
+
+	if err := runAgentStartWithDeps(AgentOptions{TTL: time.Hour}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if agentMock.StartedTTL != time.Hour {
+		t.Errorf("expected TTL 1h, got %s", agentMock.StartedTTL)
+	}
+	if len(uiMock.SuccessCalls) == 0 || !strings.Contains(uiMock.SuccessCalls[0], "4242") {
+		t.Errorf("expected success with the pid, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunAgentStartWithDeps_AlreadyRunning(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+
+	if err := runAgentStartWithDeps(AgentOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if agentMock.StartedTTL != 0 {
+		t.Error("expected no second agent to be started")
+	}
+}
+
+func TestRunAgentStartWithDeps_RequiresLogin(t *testing.T) {
+	deps, _, authMock, _, _, _ := NewTestDeps()
+	authMock.Error = errors.New("not logged in")
+
+	if err := runAgentStartWithDeps(AgentOptions{}, deps); err == nil {
+		t.Fatal("expected an error")
+	}
+	if deps.Agent.(*MockAgent).Running {
+		t.Error("expected no agent to be started")
+	}
+}
+
+func TestRunAgentStatusWithDeps_ListsEntries(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+	agentMock.StatusResult = &agent.Status{
+		PID:    4242,
+		TTL:    agent.DefaultTTL,
+		Locked: false,
+		Entries: []agent.Entry{
+			{Repo: "owner/repo", Env: "staging", Expires: time.Now().Add(time.Minute)},
+		},
+	}
+
+	if err := runAgentStatusWithDeps(AgentOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 {
+		t.Errorf("expected a warning about unlocked memory, got %v", uiMock.WarnCalls)
+	}
+	if !strings.Contains(strings.Join(uiMock.MessageCalls, "\n"), "owner/repo") {
+		t.Errorf("expected the entry to be listed, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunAgentStatusWithDeps_NotRunning(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	if err := runAgentStatusWithDeps(AgentOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.InfoCalls) == 0 {
+		t.Error("expected Info to be called")
+	}
+}
+
+func TestRunAgentLockWithDeps_Locks(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+
+	if err := runAgentLockWithDeps(AgentOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !agentMock.Locked {
+		t.Error("expected the agent to be locked")
+	}
+}
+
+func TestEvictAgentCache(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	agentMock := deps.Agent.(*MockAgent)
+
+	// No agent running: nothing to evict, nothing to say
+	evictAgentCache(deps, "owner/repo", "development")
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("expected no warning without an agent, got %v", uiMock.WarnCalls)
+	}
+
+	agentMock.Running = true
+	agentMock.EvictError = errors.New("broken pipe")
+	evictAgentCache(deps, "owner/repo", "development")
+	if len(uiMock.WarnCalls) != 1 || !strings.Contains(uiMock.WarnCalls[0], "keyway agent lock") {
+		t.Errorf("expected a warning pointing to keyway agent lock, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunRunWithDeps_ServedByAgent(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=from-vault"}
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+	agentMock.Contents = map[string]string{"owner/repo:development": "API_KEY=from-agent"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "from-agent" {
+		t.Errorf("expected the agent's secrets, got %q", cmdRunner.LastSecrets["API_KEY"])
+	}
+	if !strings.Contains(strings.Join(uiMock.StepCalls, "\n"), "agent") {
+		t.Errorf("expected a step about the agent, got %v", uiMock.StepCalls)
+	}
+
+	opts.NoAgent = true
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "from-vault" {
+		t.Errorf("expected --no-agent to pull from the vault, got %q", cmdRunner.LastSecrets["API_KEY"])
+	}
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// auditPollInterval is how often --follow checks for new events
+const auditPollInterval = 5 * time.Second
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the vault activity log",
+	Long: `List vault events: who pulled, pushed, set or synced which environment, and when.
+
+Events are shown oldest first. Use --follow to keep watching for new events,
+and --json to get one JSON object per line (for log pipelines and SIEMs).
+
+Examples:
+  keyway audit
+  keyway audit --env production --since 7d
+  keyway audit --actor alice --since 2024-06-01
+  keyway audit --follow
+  keyway audit --json --since 30d > audit.jsonl`,
+	Args: cobra.NoArgs,
+	RunE: runAudit,
+}
+
+func init() {
+	auditCmd.Flags().StringP("env", "e", "", "Only show events for this environment")
+	auditCmd.Flags().String("actor", "", "Only show events by this user")
+	auditCmd.Flags().String("since", "", "Only show events after this time (RFC 3339, YYYY-MM-DD, or a duration ago like 2h, 7d)")
+	auditCmd.Flags().BoolP("follow", "f", false, "Keep watching for new events")
+	auditCmd.Flags().Bool("json", false, "Output one JSON object per line")
+}
+
+// AuditOptions contains the parsed flags for the audit command
+type AuditOptions struct {
+	EnvName    string
+	Actor      string
+	Since      string
+	Follow     bool
+	JSONOutput bool
+
+	// Context stops --follow when cancelled (defaults to context.Background)
+	Context context.Context
+	// PollInterval overrides auditPollInterval (used by tests)
+	PollInterval time.Duration
+	// Now is the reference time for relative --since values (defaults to time.Now)
+	Now time.Time
+}
+
+// auditActionLabels maps activity actions to short, human-readable verbs
+var auditActionLabels = map[string]string{
+	"secrets_pulled":                "pulled",
+	"secrets_pushed":                "pushed",
+	"secrets_synced":                "synced",
+	"secret_created":                "set",
+	"secret_updated":                "updated",
+	"secret_deleted":                "deleted",
+	"secret_rotated":                "rotated",
+	"secret_trashed":                "trashed",
+	"secret_restored":               "restored",
+	"secret_permanently_deleted":    "purged",
+	"secret_version_restored":       "rolled back",
+	"secret_value_accessed":         "viewed",
+	"secret_version_value_accessed": "viewed version of",
+}
+
+// runAudit is the entry point for the audit command (uses default dependencies)
+func runAudit(cmd *cobra.Command, args []string) error {
+	opts := AuditOptions{}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Actor, _ = cmd.Flags().GetString("actor")
+	opts.Since, _ = cmd.Flags().GetString("since")
+	opts.Follow, _ = cmd.Flags().GetBool("follow")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	opts.Context = ctx
+
+	return runAuditWithDeps(opts, defaultDeps)
+}
+
+// runAuditWithDeps is the testable version of runAudit
+func runAuditWithDeps(opts AuditOptions, deps *Dependencies) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	interval := opts.PollInterval
+	if interval == 0 {
+		interval = auditPollInterval
+	}
+
+	if !opts.JSONOutput {
+		deps.UI.Intro("audit")
+	}
+
+	var since time.Time
+	if opts.Since != "" {
+		var err error
+		since, err = parseRollbackTime(opts.Since, now)
+		if err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	} else if opts.Follow {
+		// Without --since, follow behaves like tail -f on an empty file
+		since = now
+	}
+
+	envName := ""
+	if opts.EnvName != "" {
+		envName = normalizeEnvName(opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+
+	// The server filters and pages; matchesAuditFilter below only matters
+	// with servers that predate the filters
+	filter := api.ActivityFilter{Environment: envName, Actor: strings.TrimPrefix(opts.Actor, "@"), Since: since}
+
+	var events []api.ActivityEvent
+	fetch := func() error {
+		var fetchErr error
+		events, fetchErr = client.ListVaultActivity(ctx, repo, filter)
+		return fetchErr
+	}
+	if opts.JSONOutput {
+		err = fetch()
+	} else {
+		err = deps.UI.Spin("Fetching activity...", fetch)
+	}
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		analytics.Track(analytics.EventError, map[string]interface{}{
+			"command": "audit",
+			"error":   err.Error(),
+		})
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if !opts.JSONOutput {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+		if envName != "" {
+			deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+		}
+		deps.UI.Message("")
+	}
+
+	out := auditPrinter{deps: deps, jsonOutput: opts.JSONOutput, w: os.Stdout}
+	// IDs of the events printed within the overlap window of --follow polls
+	seen := make(map[string]time.Time)
+	printed := 0
+
+	emit := func(batch []api.ActivityEvent) error {
+		// API returns newest first; print oldest first like a log
+		for i := len(batch) - 1; i >= 0; i-- {
+			e := batch[i]
+			if _, ok := seen[e.ID]; ok {
+				continue
+			}
+			seen[e.ID] = e.Timestamp
+			if e.Timestamp.After(since) {
+				since = e.Timestamp
+			}
+			if !matchesAuditFilter(e, envName, opts.Actor) {
+				continue
+			}
+			if err := out.print(e); err != nil {
+				return err
+			}
+			printed++
+		}
+		return nil
+	}
+
+	if err := emit(events); err != nil {
+		return err
+	}
+
+	if !opts.Follow {
+		if printed == 0 && !opts.JSONOutput {
+			deps.UI.Info("No matching events")
+		}
+		return nil
+	}
+
+	if !opts.JSONOutput {
+		deps.UI.Info(fmt.Sprintf("Watching for new events (every %s, Ctrl+C to stop)...", interval))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Step back a second so events sharing the last timestamp are not missed;
+		// anything already printed is skipped by ID. Older IDs can't come back.
+		filter.Since = since.Add(-time.Second)
+		for id, ts := range seen {
+			if !ts.After(filter.Since) {
+				delete(seen, id)
+			}
+		}
+		batch, err := client.ListVaultActivity(ctx, repo, filter)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !opts.JSONOutput {
+				deps.UI.Warn(fmt.Sprintf("Failed to fetch activity: %s", err.Error()))
+			}
+			continue
+		}
+		if err := emit(batch); err != nil {
+			return err
+		}
+	}
+}
+
+// matchesAuditFilter reports whether an event passes the --env and --actor filters
+func matchesAuditFilter(e api.ActivityEvent, envName, actor string) bool {
+	if envName != "" && e.Environment() != envName {
+		return false
+	}
+	if actor != "" && !strings.EqualFold(strings.TrimPrefix(actor, "@"), e.Actor.Username) {
+		return false
+	}
+	return true
+}
+
+// auditPrinter writes events as either human-readable lines or JSON lines
+type auditPrinter struct {
+	deps       *Dependencies
+	jsonOutput bool
+	w          io.Writer
+}
+
+func (p auditPrinter) print(e api.ActivityEvent) error {
+	if p.jsonOutput {
+		return json.NewEncoder(p.w).Encode(e)
+	}
+	p.deps.UI.Message(formatAuditEvent(e, p.deps.UI))
+	return nil
+}
+
+// formatAuditEvent renders an event as a single line: time, actor, action, target
+func formatAuditEvent(e api.ActivityEvent, u UIProvider) string {
+	parts := []string{
+		u.Dim(e.Timestamp.Local().Format("2006-01-02 15:04:05")),
+		u.Bold(e.Actor.Username),
+		auditActionLabel(e.Action),
+	}
+	if key, ok := e.Metadata["key"].(string); ok && key != "" {
+		parts = append(parts, key)
+	}
+	if env := e.Environment(); env != "" {
+		parts = append(parts, u.Value(env))
+	}
+	if provider, ok := e.Metadata["provider"].(string); ok && provider != "" {
+		parts = append(parts, "via "+provider)
+	}
+	if e.Platform != "" {
+		parts = append(parts, u.Dim("("+e.Platform+")"))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// auditActionLabel returns a short label for an activity action
+func auditActionLabel(action string) string {
+	if label, ok := auditActionLabels[action]; ok {
+		return label
+	}
+	return strings.ReplaceAll(action, "_", " ")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func auditEvent(id, action, actor, env string, ts time.Time) api.ActivityEvent {
+	e := api.ActivityEvent{
+		ID:        id,
+		Action:    action,
+		Actor:     api.UserRef{Username: actor},
+		Platform:  "cli",
+		Timestamp: ts,
+	}
+	if env != "" {
+		e.Metadata = map[string]interface{}{"environment": env}
+	}
+	return e
+}
+
+func TestMatchesAuditFilter(t *testing.T) {
+	e := auditEvent("a1", "secrets_pulled", "Alice", "production", time.Now())
+
+	tests := []struct {
+		name  string
+		env   string
+		actor string
+		want  bool
+	}{
+		{"no filters", "", "", true},
+		{"env match", "production", "", true},
+		{"env mismatch", "staging", "", false},
+		{"actor case-insensitive", "", "alice", true},
+		{"actor with @", "", "@alice", true},
+		{"actor mismatch", "", "bob", false},
+		{"both match", "production", "alice", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAuditFilter(e, tt.env, tt.actor); got != tt.want {
+				t.Errorf("matchesAuditFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAuditEvent(t *testing.T) {
+	ui := &MockUIProvider{}
+	e := auditEvent("a1", "secret_updated", "alice", "production", time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	e.Metadata["key"] = "API_KEY"
+
+	line := formatAuditEvent(e, ui)
+	for _, want := range []string{"alice", "updated", "API_KEY", "(cli)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %q", want, line)
+		}
+	}
+}
+
+func TestAuditActionLabel(t *testing.T) {
+	if got := auditActionLabel("secrets_pulled"); got != "pulled" {
+		t.Errorf("expected pulled, got %q", got)
+	}
+	if got := auditActionLabel("environment_renamed"); got != "environment renamed" {
+		t.Errorf("expected fallback label, got %q", got)
+	}
+}
+
+func TestAuditPrinter_JSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	p := auditPrinter{jsonOutput: true, w: &buf}
+
+	ts := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	if err := p.print(auditEvent("a1", "secrets_pulled", "alice", "production", ts)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.print(auditEvent("a2", "secrets_pushed", "bob", "staging", ts)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d", len(lines))
+	}
+	var decoded api.ActivityEvent
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if decoded.ID != "a1" || decoded.Environment() != "production" {
+		t.Errorf("unexpected decoded event: %+v", decoded)
+	}
+}
+
+func TestRunAuditWithDeps_FiltersAndOrders(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	apiMock.ActivityEvents = []api.ActivityEvent{
+		auditEvent("a4", "secrets_pushed", "alice", "production", now.Add(-1*time.Hour)),
+		auditEvent("a3", "secrets_pulled", "bob", "production", now.Add(-2*time.Hour)),
+		auditEvent("a2", "secrets_pulled", "alice", "staging", now.Add(-3*time.Hour)),
+		auditEvent("a1", "secrets_pulled", "alice", "production", now.Add(-48*time.Hour)),
+	}
+
+	opts := AuditOptions{EnvName: "prod", Actor: "alice", Since: "1d", Now: now}
+	if err := runAuditWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var events []string
+	for _, m := range uiMock.MessageCalls {
+		if strings.Contains(m, "alice") || strings.Contains(m, "bob") {
+			events = append(events, m)
+		}
+	}
+	if len(events) != 1 || !strings.Contains(events[0], "pushed") {
+		t.Errorf("expected only the production push by alice, got %v", events)
+	}
+	want := api.ActivityFilter{Environment: "production", Actor: "alice", Since: now.AddDate(0, 0, -1)}
+	if len(apiMock.ActivityFilters) != 1 || apiMock.ActivityFilters[0] != want {
+		t.Errorf("expected the server to filter with %+v, got %+v", want, apiMock.ActivityFilters)
+	}
+}
+
+func TestRunAuditWithDeps_OldestFirst(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	apiMock.ActivityEvents = []api.ActivityEvent{
+		auditEvent("a2", "secrets_pushed", "alice", "production", now.Add(-1*time.Hour)),
+		auditEvent("a1", "secrets_pulled", "alice", "production", now.Add(-2*time.Hour)),
+	}
+
+	if err := runAuditWithDeps(AuditOptions{Now: now}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var actions []string
+	for _, m := range uiMock.MessageCalls {
+		if strings.Contains(m, "alice") {
+			actions = append(actions, m)
+		}
+	}
+	if len(actions) != 2 || !strings.Contains(actions[0], "pulled") || !strings.Contains(actions[1], "pushed") {
+		t.Errorf("expected pull before push, got %v", actions)
+	}
+}
+
+func TestRunAuditWithDeps_InvalidSince(t *testing.T) {
+	deps, _, _, uiMock, _, _, _ := NewTestDepsWithEnv()
+
+	if err := runAuditWithDeps(AuditOptions{Since: "whenever"}, deps); err == nil {
+		t.Fatal("expected error for invalid --since")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunAuditWithDeps_APIError(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.ActivityError = errors.New("network error")
+
+	if err := runAuditWithDeps(AuditOptions{}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRunAuditWithDeps_Follow(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	polls := 0
+	apiMock.ActivityFn = func(filter api.ActivityFilter) ([]api.ActivityEvent, error) {
+		polls++
+		switch polls {
+		case 1:
+			if !filter.Since.Equal(now) {
+				t.Errorf("expected follow without --since to start at now, got %v", filter.Since)
+			}
+			return nil, nil
+		case 2:
+			return []api.ActivityEvent{auditEvent("a1", "secrets_pushed", "alice", "production", now.Add(time.Second))}, nil
+		default:
+			// Same event is returned again because of the overlap window
+			cancel()
+			return []api.ActivityEvent{auditEvent("a1", "secrets_pushed", "alice", "production", now.Add(time.Second))}, nil
+		}
+	}
+
+	opts := AuditOptions{Follow: true, Context: ctx, PollInterval: time.Millisecond, Now: now}
+	if err := runAuditWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	printed := 0
+	for _, m := range uiMock.MessageCalls {
+		if strings.Contains(m, "alice") {
+			printed++
+		}
+	}
+	if printed != 1 {
+		t.Errorf("expected the new event to be printed once, got %d", printed)
+	}
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/auth"
 )
@@ -10,8 +12,7 @@ import (
 // In non-interactive mode, it shows a clear error message.
 // Returns the new token if re-login was successful, empty string and original error otherwise.
 func handleAuthError(err error, deps *Dependencies) (string, error) {
-	apiErr, ok := err.(*api.APIError)
-	if !ok || apiErr.StatusCode != 401 {
+	if !isAuthError(err) {
 		return "", err
 	}
 
@@ -39,8 +40,9 @@ func handleAuthError(err error, deps *Dependencies) (string, error) {
 	return "", err
 }
 
-// isAuthError checks if the error is an authentication error (401)
+// isAuthError checks if the error is an authentication error (401), also when
+// it was wrapped, e.g. while resolving a reference or a layer
 func isAuthError(err error) bool {
-	apiErr, ok := err.(*api.APIError)
-	return ok && apiErr.StatusCode == 401
+	var apiErr *api.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 401
 }
@@ -5,7 +5,14 @@ package cmd
 // Mock implementations for testing are in mocks_test.go.
 
 import (
+	"context"
+	"time"
+
+	"github.com/keywaysh/cli/internal/agent"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/e2e"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/injector"
 )
 
 // MonorepoInfo contains information about detected monorepo setup
@@ -80,7 +87,10 @@ type APIClientFactory interface {
 
 // CommandRunner abstracts command execution for testing
 type CommandRunner interface {
-	RunCommand(name string, args []string, secrets map[string]string) error
+	RunCommand(name string, args []string, secrets map[string]string, opts injector.Options) error
+	RunCommandWatched(name string, args []string, secrets map[string]string, opts injector.Options, updates <-chan map[string]string) error
+	Exec(name string, args []string, secrets map[string]string, opts injector.Options) error
+	RunProcesses(procs []injector.Process, opts injector.Options) error
 }
 
 // BrowserOpener abstracts browser operations for testing
@@ -121,6 +131,42 @@ type FileStat interface {
 	Stat(name string) (FileInfo, error)
 }
 
+// BaselineStore abstracts storage of last-pulled baselines for testing
+type BaselineStore interface {
+	Load(repo, envName, file string) (*env.Baseline, error)
+	Save(repo, envName, file string, b *env.Baseline) error
+}
+
+// IdentityStore abstracts the end-to-end encryption identity and the vaults
+// recorded as encrypted for testing
+type IdentityStore interface {
+	Load() (*e2e.Identity, error)
+	LoadOrCreate() (id *e2e.Identity, created bool, err error)
+	IsRecorded(repo string) (bool, error)
+	Record(repo string) error
+}
+
+// AgentClient abstracts the local secrets agent for testing
+type AgentClient interface {
+	Available() bool
+	Status(ctx context.Context) (*agent.Status, error)
+	Lock(ctx context.Context) error
+	// Evict makes the agent forget an environment (every environment of the
+	// repository when env is empty); agent.ErrNotRunning when none runs
+	Evict(ctx context.Context, repo, env string) error
+	// Start runs the agent in the background and waits until it listens
+	Start(ttl time.Duration) (pid int, err error)
+	// WrapAPI returns client, pulling secrets through the agent
+	WrapAPI(client api.APIClient) AgentAPIClient
+}
+
+// AgentAPIClient is an API client that pulls secrets through the agent
+type AgentAPIClient interface {
+	api.APIClient
+	// Served returns how many environments the agent served
+	Served() int
+}
+
 // Dependencies holds all external dependencies for commands
 type Dependencies struct {
 	Git        GitClient
@@ -135,4 +181,7 @@ type Dependencies struct {
 	Stat       FileStat
 	AuthStore  AuthStore
 	HTTP       HTTPClient
+	Baselines  BaselineStore
+	Identity   IdentityStore
+	Agent      AgentClient
 }
@@ -12,13 +12,17 @@ package cmd
 // The testable business logic lives in the *WithDeps functions in each command file.
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/keywaysh/cli/internal/agent"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/auth"
+	"github.com/keywaysh/cli/internal/e2e"
 	"github.com/keywaysh/cli/internal/env"
 	"github.com/keywaysh/cli/internal/git"
 	"github.com/keywaysh/cli/internal/injector"
@@ -87,11 +91,12 @@ func (r *realFileSystem) WriteFile(name string, data []byte, perm uint32) error
 	return osWriteFile(name, data, perm)
 }
 
-// realAPIFactory creates real API clients
+// realAPIFactory creates real API clients. Secrets of end-to-end encrypted
+// vaults are encrypted and decrypted transparently.
 type realAPIFactory struct{}
 
 func (r *realAPIFactory) NewClient(token string) api.APIClient {
-	return api.NewClient(token)
+	return e2e.NewClient(api.NewClient(token), &realIdentityStore{})
 }
 
 // realEnvHelper wraps the env package
@@ -113,8 +118,20 @@ func (r *realEnvHelper) DeriveEnvFromFile(file string) string {
 // realCommandRunner wraps the injector package
 type realCommandRunner struct{}
 
-func (r *realCommandRunner) RunCommand(name string, args []string, secrets map[string]string) error {
-	return injector.RunCommand(name, args, secrets)
+func (r *realCommandRunner) RunCommand(name string, args []string, secrets map[string]string, opts injector.Options) error {
+	return injector.RunCommand(name, args, secrets, opts)
+}
+
+func (r *realCommandRunner) RunCommandWatched(name string, args []string, secrets map[string]string, opts injector.Options, updates <-chan map[string]string) error {
+	return injector.RunCommandWatched(name, args, secrets, opts, updates)
+}
+
+func (r *realCommandRunner) Exec(name string, args []string, secrets map[string]string, opts injector.Options) error {
+	return injector.Exec(name, args, secrets, opts)
+}
+
+func (r *realCommandRunner) RunProcesses(procs []injector.Process, opts injector.Options) error {
+	return injector.RunProcesses(procs, opts)
 }
 
 // realBrowserOpener wraps the browser package
@@ -187,6 +204,90 @@ func (r *realHTTPClient) Head(url string) (int, error) {
 	return resp.StatusCode, nil
 }
 
+// realBaselineStore wraps the env baseline storage
+type realBaselineStore struct{}
+
+func (r *realBaselineStore) Load(repo, envName, file string) (*env.Baseline, error) {
+	return env.LoadBaseline(repo, envName, file)
+}
+
+func (r *realBaselineStore) Save(repo, envName, file string, b *env.Baseline) error {
+	return env.SaveBaseline(repo, envName, file, b)
+}
+
+// realIdentityStore keeps the identity and the encrypted vaults in the user config dir
+type realIdentityStore struct{}
+
+func (r *realIdentityStore) Load() (*e2e.Identity, error) {
+	path, err := e2e.IdentityPath()
+	if err != nil {
+		return nil, err
+	}
+	return e2e.LoadIdentity(path)
+}
+
+func (r *realIdentityStore) LoadOrCreate() (*e2e.Identity, bool, error) {
+	path, err := e2e.IdentityPath()
+	if err != nil {
+		return nil, false, err
+	}
+	return e2e.LoadOrCreateIdentity(path)
+}
+
+func (r *realIdentityStore) IsRecorded(repo string) (bool, error) {
+	path, err := e2e.VaultsPath()
+	if err != nil {
+		return false, err
+	}
+	return e2e.IsRecordedVault(path, repo)
+}
+
+func (r *realIdentityStore) Record(repo string) error {
+	path, err := e2e.VaultsPath()
+	if err != nil {
+		return err
+	}
+	return e2e.RecordVault(path, repo)
+}
+
+// realAgent talks to the agent on its default socket
+type realAgent struct{}
+
+func (r *realAgent) client() *agent.Client {
+	path, _ := agent.SocketPath()
+	return agent.NewClient(path)
+}
+
+func (r *realAgent) Available() bool { return r.client().Available() }
+
+func (r *realAgent) Status(ctx context.Context) (*agent.Status, error) {
+	return r.client().Status(ctx)
+}
+
+func (r *realAgent) Lock(ctx context.Context) error { return r.client().Lock(ctx) }
+
+func (r *realAgent) Evict(ctx context.Context, repo, env string) error {
+	return r.client().Evict(ctx, repo, env)
+}
+
+func (r *realAgent) Start(ttl time.Duration) (int, error) {
+	pid, err := agent.Spawn([]string{"agent", "start", "--foreground", "--ttl", ttl.String()})
+	if err != nil {
+		return 0, err
+	}
+	c := r.client()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if c.Available() {
+			return pid, nil
+		}
+	}
+	return 0, fmt.Errorf("the agent did not start - run keyway agent start --foreground to see why")
+}
+
+func (r *realAgent) WrapAPI(client api.APIClient) AgentAPIClient {
+	return agent.NewAPIClient(client, r.client())
+}
+
 // DefaultDeps returns the default (real) dependencies
 func DefaultDeps() *Dependencies {
 	return &Dependencies{
@@ -202,6 +303,9 @@ func DefaultDeps() *Dependencies {
 		Stat:       &realFileStat{},
 		AuthStore:  &realAuthStore{},
 		HTTP:       &realHTTPClient{},
+		Baselines:  &realBaselineStore{},
+		Identity:   &realIdentityStore{},
+		Agent:      &realAgent{},
 	}
 }
 
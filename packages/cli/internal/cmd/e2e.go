@@ -0,0 +1,486 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/e2e"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var e2eCmd = &cobra.Command{
+	Use:   "e2e",
+	Short: "Manage end-to-end encryption for this vault",
+	Long: `With end-to-end encryption, the CLI encrypts secret values with a vault key
+before pushing them, and decrypts them after pulling. The server only stores
+ciphertext. The vault key is shared with each member by sealing it to their
+public key; the matching private key stays in ~/.config/keyway/identity.key.
+
+Commands keep working as usual on encrypted vaults. Provider sync and
+viewing values in the dashboard are not available, since the server can't
+read them.
+
+Examples:
+  keyway e2e enable
+  keyway e2e join
+  keyway e2e grant alice
+  keyway e2e status`,
+}
+
+var e2eEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Turn on end-to-end encryption and encrypt every environment",
+	Args:  cobra.NoArgs,
+	RunE:  runE2EEnable,
+}
+
+var e2eJoinCmd = &cobra.Command{
+	Use:   "join",
+	Short: "Register your public key so a member can share the vault key with you",
+	Args:  cobra.NoArgs,
+	RunE:  runE2EJoin,
+}
+
+var e2eGrantCmd = &cobra.Command{
+	Use:   "grant [username...]",
+	Short: "Share the vault key with members who joined",
+	Long: `Share the vault key with members who ran keyway e2e join.
+
+Without usernames, every member waiting for the key is granted. Check that the
+fingerprints shown match the ones your teammates see with keyway e2e join.`,
+	RunE: runE2EGrant,
+}
+
+var e2eStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show end-to-end encryption status and members",
+	Args:  cobra.NoArgs,
+	RunE:  runE2EStatus,
+}
+
+func init() {
+	e2eEnableCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	e2eGrantCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	e2eCmd.AddCommand(e2eEnableCmd)
+	e2eCmd.AddCommand(e2eJoinCmd)
+	e2eCmd.AddCommand(e2eGrantCmd)
+	e2eCmd.AddCommand(e2eStatusCmd)
+}
+
+// E2EOptions contains the parsed flags for the e2e subcommands
+type E2EOptions struct {
+	Yes       bool
+	Usernames []string
+}
+
+// runE2EEnable is the entry point for the e2e enable command (uses default dependencies)
+func runE2EEnable(cmd *cobra.Command, args []string) error {
+	opts := E2EOptions{}
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	return runE2EEnableWithDeps(opts, defaultDeps)
+}
+
+// runE2EJoin is the entry point for the e2e join command (uses default dependencies)
+func runE2EJoin(cmd *cobra.Command, args []string) error {
+	return runE2EJoinWithDeps(E2EOptions{}, defaultDeps)
+}
+
+// runE2EGrant is the entry point for the e2e grant command (uses default dependencies)
+func runE2EGrant(cmd *cobra.Command, args []string) error {
+	opts := E2EOptions{Usernames: args}
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	return runE2EGrantWithDeps(opts, defaultDeps)
+}
+
+// runE2EStatus is the entry point for the e2e status command (uses default dependencies)
+func runE2EStatus(cmd *cobra.Command, args []string) error {
+	return runE2EStatusWithDeps(E2EOptions{}, defaultDeps)
+}
+
+// e2eSession holds what every e2e subcommand needs
+type e2eSession struct {
+	deps   *Dependencies
+	repo   string
+	client api.APIClient
+	ctx    context.Context
+}
+
+// newE2ESession detects the repository and logs in
+func newE2ESession(deps *Dependencies, command string) (*e2eSession, error) {
+	deps.UI.Intro(command)
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return nil, err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return nil, err
+	}
+
+	return &e2eSession{
+		deps:   deps,
+		repo:   repo,
+		client: deps.APIFactory.NewClient(token),
+		ctx:    context.Background(),
+	}, nil
+}
+
+// vaultKeys fetches the encryption state of the vault, refreshing an expired
+// login once
+func (s *e2eSession) vaultKeys(command string) (*api.VaultKeys, error) {
+	var keys *api.VaultKeys
+	fetch := func() error {
+		var err error
+		keys, err = s.client.GetVaultKeys(s.ctx, s.repo)
+		return err
+	}
+
+	err := s.deps.UI.Spin("Fetching vault keys...", fetch)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, s.deps)
+		if authErr != nil {
+			return nil, authErr
+		}
+		s.client = s.deps.APIFactory.NewClient(newToken)
+		err = s.deps.UI.Spin("Fetching vault keys...", fetch)
+	}
+	if err != nil {
+		return nil, s.fail(command, err)
+	}
+	return keys, nil
+}
+
+// fail reports an error and returns it
+func (s *e2eSession) fail(command string, err error) error {
+	analytics.Track(analytics.EventError, map[string]interface{}{
+		"command": command,
+		"error":   err.Error(),
+	})
+	s.deps.UI.Error(err.Error())
+	return err
+}
+
+// identity loads or creates the caller's identity and registers its public
+// key with the vault
+func (s *e2eSession) identity(command string) (*e2e.Identity, error) {
+	id, created, err := s.deps.Identity.LoadOrCreate()
+	if err != nil {
+		return nil, s.fail(command, fmt.Errorf("failed to load identity: %w", err))
+	}
+	if created {
+		s.deps.UI.Info("Created a new identity in ~/.config/keyway/identity.key - back it up, it can't be recovered")
+	}
+	if err := s.client.RegisterPublicKey(s.ctx, s.repo, id.PublicKey()); err != nil {
+		return nil, s.fail(command, err)
+	}
+	return id, nil
+}
+
+// runE2EEnableWithDeps is the testable version of runE2EEnable
+func runE2EEnableWithDeps(opts E2EOptions, deps *Dependencies) error {
+	const command = "e2e enable"
+	s, err := newE2ESession(deps, command)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.vaultKeys(command)
+	if err != nil {
+		return err
+	}
+	if keys.E2EEnabled {
+		deps.UI.Warn("End-to-end encryption is already enabled for this vault")
+		return nil
+	}
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		deps.UI.Message("")
+		deps.UI.Message("Every environment will be re-pushed encrypted. Afterwards:")
+		deps.UI.Message("  - only members you grant the vault key to can read secrets")
+		deps.UI.Message("  - provider sync and viewing values in the dashboard stop working")
+		deps.UI.Message("  - versions pushed before remain readable by the server; rotate sensitive secrets")
+		deps.UI.Message("")
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Enable end-to-end encryption for %s?", s.repo), false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	if _, err := s.identity(command); err != nil {
+		return err
+	}
+	// Fetch again to include our own registration
+	if keys, err = s.vaultKeys(command); err != nil {
+		return err
+	}
+
+	envNames, err := s.client.GetVaultEnvironments(s.ctx, s.repo)
+	if err != nil {
+		return s.fail(command, err)
+	}
+
+	plaintext := make(map[string]map[string]string)
+	err = deps.UI.Spin("Downloading secrets...", func() error {
+		for _, envName := range envNames {
+			resp, err := s.client.PullSecrets(s.ctx, s.repo, envName)
+			if err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+					continue
+				}
+				return err
+			}
+			plaintext[envName] = env.Parse(resp.Content)
+		}
+		return nil
+	})
+	if err != nil {
+		return s.fail(command, err)
+	}
+
+	vaultKey, err := e2e.NewVaultKey()
+	if err != nil {
+		return s.fail(command, err)
+	}
+	wrapped, err := wrapForMembers(vaultKey, keys.Members)
+	if err != nil {
+		return s.fail(command, err)
+	}
+	if err := s.client.PutVaultKeys(s.ctx, s.repo, wrapped, true); err != nil {
+		return s.fail(command, err)
+	}
+	if err := deps.Identity.Record(s.repo); err != nil {
+		return s.fail(command, fmt.Errorf("failed to record the vault as encrypted: %w", err))
+	}
+
+	err = deps.UI.Spin("Encrypting secrets...", func() error {
+		for _, envName := range sortedEnvNames(plaintext) {
+			encrypted, err := vaultKey.EncryptSecrets(envName, plaintext[envName])
+			if err != nil {
+				return err
+			}
+			if _, err := s.client.PushSecrets(s.ctx, s.repo, envName, encrypted); err != nil {
+				return fmt.Errorf("%s: %w", envName, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		deps.UI.Warn("Encryption is enabled but some environments were not re-pushed - push them again with keyway push")
+		return s.fail(command, err)
+	}
+
+	analytics.Track(analytics.EventE2E, map[string]interface{}{
+		"action":       "enable",
+		"repoFullName": s.repo,
+		"members":      len(wrapped),
+	})
+
+	deps.UI.Success(fmt.Sprintf("Encrypted %d environment(s)", len(plaintext)))
+	deps.UI.Message(fmt.Sprintf("Vault key shared with %d member(s)", len(wrapped)))
+	deps.UI.Outro("Teammates run keyway e2e join, then you run keyway e2e grant")
+	return nil
+}
+
+// runE2EJoinWithDeps is the testable version of runE2EJoin
+func runE2EJoinWithDeps(opts E2EOptions, deps *Dependencies) error {
+	const command = "e2e join"
+	s, err := newE2ESession(deps, command)
+	if err != nil {
+		return err
+	}
+
+	id, err := s.identity(command)
+	if err != nil {
+		return err
+	}
+
+	// From now on this machine refuses plaintext for the vault, whatever
+	// the server says
+	keys, err := s.vaultKeys(command)
+	if err != nil {
+		return err
+	}
+	if keys.E2EEnabled {
+		if err := deps.Identity.Record(s.repo); err != nil {
+			return s.fail(command, fmt.Errorf("failed to record the vault as encrypted: %w", err))
+		}
+	}
+
+	analytics.Track(analytics.EventE2E, map[string]interface{}{
+		"action":       "join",
+		"repoFullName": s.repo,
+	})
+
+	deps.UI.Success("Public key registered")
+	deps.UI.Message(fmt.Sprintf("Fingerprint: %s", deps.UI.Value(e2e.Fingerprint(id.PublicKey()))))
+	deps.UI.Outro("Ask a member with access to run keyway e2e grant, and check they see this fingerprint")
+	return nil
+}
+
+// runE2EGrantWithDeps is the testable version of runE2EGrant
+func runE2EGrantWithDeps(opts E2EOptions, deps *Dependencies) error {
+	const command = "e2e grant"
+	s, err := newE2ESession(deps, command)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.vaultKeys(command)
+	if err != nil {
+		return err
+	}
+	if !keys.E2EEnabled {
+		return s.fail(command, fmt.Errorf("end-to-end encryption is not enabled for %s", s.repo))
+	}
+	if keys.WrappedKey == "" {
+		return s.fail(command, fmt.Errorf("you don't have the vault key yourself - run keyway e2e join and ask another member"))
+	}
+
+	id, err := deps.Identity.Load()
+	if err != nil {
+		return s.fail(command, fmt.Errorf("failed to load identity: %w", err))
+	}
+	vaultKey, err := id.Unwrap(keys.WrappedKey)
+	if err != nil {
+		return s.fail(command, err)
+	}
+
+	var pending []api.MemberKey
+	for _, m := range keys.Members {
+		if !m.Granted {
+			pending = append(pending, m)
+		}
+	}
+	if len(opts.Usernames) > 0 {
+		byName := make(map[string]api.MemberKey, len(pending))
+		for _, m := range pending {
+			byName[strings.ToLower(m.Username)] = m
+		}
+		var selected []api.MemberKey
+		for _, name := range opts.Usernames {
+			m, ok := byName[strings.ToLower(strings.TrimPrefix(name, "@"))]
+			if !ok {
+				return s.fail(command, fmt.Errorf("%s is not waiting for the vault key - they need to run keyway e2e join first", name))
+			}
+			selected = append(selected, m)
+		}
+		pending = selected
+	}
+	if len(pending) == 0 {
+		deps.UI.Info("No member is waiting for the vault key")
+		return nil
+	}
+
+	deps.UI.Message("")
+	deps.UI.Message("Share the vault key with:")
+	for _, m := range pending {
+		deps.UI.DiffAdded(fmt.Sprintf("%s %s", m.Username, deps.UI.Dim(e2e.Fingerprint(m.PublicKey))))
+	}
+	deps.UI.Message("")
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm("Do these fingerprints match what your teammates see?", false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	wrapped, err := wrapForMembers(vaultKey, pending)
+	if err != nil {
+		return s.fail(command, err)
+	}
+	if err := s.client.PutVaultKeys(s.ctx, s.repo, wrapped, false); err != nil {
+		return s.fail(command, err)
+	}
+
+	analytics.Track(analytics.EventE2E, map[string]interface{}{
+		"action":       "grant",
+		"repoFullName": s.repo,
+		"members":      len(wrapped),
+	})
+
+	deps.UI.Outro(fmt.Sprintf("Vault key shared with %d member(s)", len(wrapped)))
+	return nil
+}
+
+// runE2EStatusWithDeps is the testable version of runE2EStatus
+func runE2EStatusWithDeps(opts E2EOptions, deps *Dependencies) error {
+	const command = "e2e status"
+	s, err := newE2ESession(deps, command)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.vaultKeys(command)
+	if err != nil {
+		return err
+	}
+
+	if !keys.E2EEnabled {
+		deps.UI.Info("End-to-end encryption is not enabled")
+		deps.UI.Message(deps.UI.Dim("Run keyway e2e enable to turn it on (admin only)"))
+		return nil
+	}
+
+	deps.UI.Step(fmt.Sprintf("End-to-end encryption: %s", deps.UI.Value("enabled")))
+	if keys.WrappedKey != "" {
+		deps.UI.Step("You have the vault key")
+	} else {
+		deps.UI.Warn("You don't have the vault key yet - run keyway e2e join, then ask a member to grant it")
+	}
+
+	if len(keys.Members) > 0 {
+		deps.UI.Message("")
+		deps.UI.Message("Members:")
+		for _, m := range keys.Members {
+			line := fmt.Sprintf("%s %s", m.Username, deps.UI.Dim(e2e.Fingerprint(m.PublicKey)))
+			if m.Granted {
+				deps.UI.DiffKept(line)
+			} else {
+				deps.UI.DiffAdded(line + " " + deps.UI.Dim("(waiting for grant)"))
+			}
+		}
+	}
+	deps.UI.Message("")
+	return nil
+}
+
+// wrapForMembers seals the vault key to each member's public key
+func wrapForMembers(key *e2e.VaultKey, members []api.MemberKey) ([]api.WrappedKey, error) {
+	wrapped := make([]api.WrappedKey, 0, len(members))
+	for _, m := range members {
+		w, err := key.Wrap(m.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Username, err)
+		}
+		wrapped = append(wrapped, api.WrappedKey{UserID: m.UserID, WrappedKey: w})
+	}
+	return wrapped, nil
+}
+
+// sortedEnvNames returns the environment names of a map in a stable order
+func sortedEnvNames(m map[string]map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/e2e"
+)
+
+func TestRunE2EEnableWithDeps_EncryptsEveryEnvironment(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	member, _ := e2e.NewIdentity()
+	apiMock.VaultKeys = &api.VaultKeys{Members: []api.MemberKey{
+		{UserID: "u2", Username: "alice", PublicKey: member.PublicKey()},
+	}}
+	apiMock.VaultEnvs = []string{"development", "production", "staging"}
+	apiMock.PullByEnv = map[string]string{
+		"development": "API_KEY=dev",
+		"production":  "API_KEY=prod\nDB_URL=postgres://prod",
+	}
+
+	if err := runE2EEnableWithDeps(E2EOptions{Yes: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !apiMock.PutKeysEnable {
+		t.Error("expected encryption to be enabled")
+	}
+	if apiMock.RegisteredPublicKey == "" {
+		t.Error("expected own public key to be registered")
+	}
+	if len(apiMock.PutKeys) != 1 || apiMock.PutKeys[0].UserID != "u2" {
+		t.Fatalf("expected vault key wrapped for alice, got %+v", apiMock.PutKeys)
+	}
+
+	if !deps.Identity.(*MockIdentityStore).Recorded["owner/repo"] {
+		t.Error("expected the vault to be recorded as encrypted")
+	}
+
+	key, err := member.Unwrap(apiMock.PutKeys[0].WrappedKey)
+	if err != nil {
+		t.Fatalf("member cannot unwrap vault key: %v", err)
+	}
+	if _, ok := apiMock.PushedByEnv["staging"]; ok {
+		t.Error("environments without secrets should not be pushed")
+	}
+	for envName, want := range map[string]string{"development": "dev", "production": "prod"} {
+		value := apiMock.PushedByEnv[envName]["API_KEY"]
+		if !e2e.IsEncrypted(value) {
+			t.Fatalf("%s: expected encrypted value, got %q", envName, value)
+		}
+		if got, _ := key.Decrypt(envName, "API_KEY", value); got != want {
+			t.Errorf("%s: decrypted %q, want %q", envName, got, want)
+		}
+	}
+}
+
+func TestRunE2EEnableWithDeps_AlreadyEnabled(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.VaultKeys = &api.VaultKeys{E2EEnabled: true}
+
+	if err := runE2EEnableWithDeps(E2EOptions{Yes: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PutKeysEnable || apiMock.PushedByEnv != nil {
+		t.Error("expected nothing to change")
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning")
+	}
+}
+
+func TestRunE2EEnableWithDeps_RequiresConfirmation(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	if err := runE2EEnableWithDeps(E2EOptions{}, deps); err == nil {
+		t.Fatal("expected error without --yes in non-interactive mode")
+	}
+	if apiMock.PutKeysEnable {
+		t.Error("expected encryption to stay disabled")
+	}
+}
+
+func TestRunE2EJoinWithDeps_RegistersPublicKey(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	if err := runE2EJoinWithDeps(E2EOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	id := deps.Identity.(*MockIdentityStore).Identity
+	if id == nil {
+		t.Fatal("expected an identity to be created")
+	}
+	if apiMock.RegisteredPublicKey != id.PublicKey() {
+		t.Errorf("registered %q, want %q", apiMock.RegisteredPublicKey, id.PublicKey())
+	}
+	if len(deps.Identity.(*MockIdentityStore).Recorded) != 0 {
+		t.Error("expected a vault without encryption not to be recorded")
+	}
+}
+
+func TestRunE2EJoinWithDeps_RecordsEncryptedVault(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.VaultKeys = &api.VaultKeys{E2EEnabled: true}
+
+	if err := runE2EJoinWithDeps(E2EOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !deps.Identity.(*MockIdentityStore).Recorded["owner/repo"] {
+		t.Error("expected the vault to be recorded as encrypted")
+	}
+}
+
+func TestRunE2EGrantWithDeps_WrapsForPendingMembers(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	me, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: me}
+	key, _ := e2e.NewVaultKey()
+	myWrapped, _ := key.Wrap(me.PublicKey())
+
+	alice, _ := e2e.NewIdentity()
+	bob, _ := e2e.NewIdentity()
+	apiMock.VaultKeys = &api.VaultKeys{
+		E2EEnabled: true,
+		WrappedKey: myWrapped,
+		Members: []api.MemberKey{
+			{UserID: "u1", Username: "me", PublicKey: me.PublicKey(), Granted: true},
+			{UserID: "u2", Username: "alice", PublicKey: alice.PublicKey()},
+			{UserID: "u3", Username: "bob", PublicKey: bob.PublicKey()},
+		},
+	}
+
+	if err := runE2EGrantWithDeps(E2EOptions{Yes: true, Usernames: []string{"@Alice"}}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.PutKeysEnable {
+		t.Error("grant should not send enable")
+	}
+	if len(apiMock.PutKeys) != 1 || apiMock.PutKeys[0].UserID != "u2" {
+		t.Fatalf("expected key wrapped for alice only, got %+v", apiMock.PutKeys)
+	}
+	got, err := alice.Unwrap(apiMock.PutKeys[0].WrappedKey)
+	if err != nil {
+		t.Fatalf("alice cannot unwrap vault key: %v", err)
+	}
+	if *got != *key {
+		t.Error("alice received a different vault key")
+	}
+}
+
+func TestRunE2EGrantWithDeps_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      *api.VaultKeys
+		usernames []string
+		wantErr   string
+	}{
+		{
+			name:    "not enabled",
+			keys:    &api.VaultKeys{},
+			wantErr: "not enabled",
+		},
+		{
+			name:    "no vault key",
+			keys:    &api.VaultKeys{E2EEnabled: true},
+			wantErr: "don't have the vault key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+			apiMock.VaultKeys = tt.keys
+
+			err := runE2EGrantWithDeps(E2EOptions{Yes: true, Usernames: tt.usernames}, deps)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunE2EGrantWithDeps_UnknownMember(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	me, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: me}
+	key, _ := e2e.NewVaultKey()
+	myWrapped, _ := key.Wrap(me.PublicKey())
+	apiMock.VaultKeys = &api.VaultKeys{E2EEnabled: true, WrappedKey: myWrapped}
+
+	err := runE2EGrantWithDeps(E2EOptions{Yes: true, Usernames: []string{"carol"}}, deps)
+	if err == nil || !strings.Contains(err.Error(), "keyway e2e join") {
+		t.Errorf("expected join hint, got %v", err)
+	}
+}
@@ -0,0 +1,575 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	envpkg "github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+// envNamePattern mirrors the server-side validation for environment names
+var envNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,29}$`)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage vault environments",
+	Long: `List, create, rename, delete or clone the environments of a vault.
+
+Shorthand names are resolved the same way as everywhere else in the CLI:
+'prod' means production, 'dev' means development, 'stg' means staging.
+
+Examples:
+  keyway env list
+  keyway env create preview
+  keyway env rename stg qa
+  keyway env clone production prod-backup
+  keyway env delete preview`,
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List environments",
+	Args:  cobra.NoArgs,
+	RunE:  runEnvList,
+}
+
+var envCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an empty environment",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnvCreate,
+}
+
+var envRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new-name>",
+	Short: "Rename an environment and move its secrets",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runEnvRename,
+}
+
+var envDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an environment and all of its secrets",
+	Long: `Delete an environment and all of its secrets.
+
+Deletion is refused while sync links (e.g. Vercel, Railway) still point at the
+environment, since those syncs would silently stop working. Remove the links
+first, or pass --force.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEnvDelete,
+}
+
+var envCloneCmd = &cobra.Command{
+	Use:   "clone <name> <new-name>",
+	Short: "Create a new environment with a copy of another one's secrets",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runEnvClone,
+}
+
+func init() {
+	envListCmd.Flags().Bool("json", false, "Output as JSON")
+	envRenameCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	envDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	envDeleteCmd.Flags().Bool("force", false, "Delete even if sync links use this environment")
+
+	envCmd.AddCommand(envListCmd)
+	envCmd.AddCommand(envCreateCmd)
+	envCmd.AddCommand(envRenameCmd)
+	envCmd.AddCommand(envDeleteCmd)
+	envCmd.AddCommand(envCloneCmd)
+}
+
+// EnvOptions contains the parsed flags for the env subcommands
+type EnvOptions struct {
+	Name       string
+	NewName    string
+	JSONOutput bool
+	Yes        bool
+	Force      bool
+}
+
+// EnvListEntry is one environment in `env list --json` output
+type EnvListEntry struct {
+	Name  string          `json:"name"`
+	Type  string          `json:"type"`
+	Syncs []api.VaultSync `json:"syncs"`
+}
+
+// runEnvList is the entry point for the env list command (uses default dependencies)
+func runEnvList(cmd *cobra.Command, args []string) error {
+	opts := EnvOptions{}
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+	return runEnvListWithDeps(opts, defaultDeps)
+}
+
+// runEnvCreate is the entry point for the env create command (uses default dependencies)
+func runEnvCreate(cmd *cobra.Command, args []string) error {
+	return runEnvCreateWithDeps(EnvOptions{Name: args[0]}, defaultDeps)
+}
+
+// runEnvRename is the entry point for the env rename command (uses default dependencies)
+func runEnvRename(cmd *cobra.Command, args []string) error {
+	opts := EnvOptions{Name: args[0], NewName: args[1]}
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	return runEnvRenameWithDeps(opts, defaultDeps)
+}
+
+// runEnvDelete is the entry point for the env delete command (uses default dependencies)
+func runEnvDelete(cmd *cobra.Command, args []string) error {
+	opts := EnvOptions{Name: args[0]}
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	opts.Force, _ = cmd.Flags().GetBool("force")
+	return runEnvDeleteWithDeps(opts, defaultDeps)
+}
+
+// runEnvClone is the entry point for the env clone command (uses default dependencies)
+func runEnvClone(cmd *cobra.Command, args []string) error {
+	return runEnvCloneWithDeps(EnvOptions{Name: args[0], NewName: args[1]}, defaultDeps)
+}
+
+// envSession holds what every env subcommand needs once the vault is loaded
+type envSession struct {
+	repo         string
+	client       api.APIClient
+	environments []api.Environment
+}
+
+// loadEnvSession detects the repo, logs in and fetches the vault's environments
+func loadEnvSession(deps *Dependencies, quiet bool) (*envSession, error) {
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return nil, err
+	}
+	if !quiet {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return nil, err
+	}
+
+	s := &envSession{repo: repo, client: deps.APIFactory.NewClient(token)}
+	fetch := func() error {
+		var fetchErr error
+		s.environments, fetchErr = s.client.ListEnvironments(context.Background(), repo)
+		return fetchErr
+	}
+	if quiet {
+		err = fetch()
+	} else {
+		err = deps.UI.Spin("Fetching environments...", fetch)
+	}
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return nil, authErr
+		}
+		s.client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to fetch environments: %s", err.Error()))
+		return nil, err
+	}
+	return s, nil
+}
+
+// syncsFor returns the sync links of the vault that use the given environment
+func (s *envSession) syncsFor(name string) ([]api.VaultSync, error) {
+	details, err := s.client.GetVaultDetails(context.Background(), s.repo)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, nil
+	}
+	var links []api.VaultSync
+	for _, sync := range details.Syncs {
+		if sync.KeywayEnvironment == name {
+			links = append(links, sync)
+		}
+	}
+	return links, nil
+}
+
+// resolve finds an existing environment by name. An exact match wins, so an
+// environment literally named "prod" stays reachable; otherwise aliases are
+// normalized ("prod" -> "production").
+func (s *envSession) resolve(name string) (string, error) {
+	raw := strings.ToLower(strings.TrimSpace(name))
+	normalized := envpkg.NormalizeEnvName(name)
+	for _, candidate := range []string{raw, normalized} {
+		for _, e := range s.environments {
+			if e.Name == candidate {
+				return e.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("environment '%s' not found (available: %s)", name, strings.Join(s.names(), ", "))
+}
+
+// has reports whether an environment with exactly this name exists
+func (s *envSession) has(name string) bool {
+	for _, e := range s.environments {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *envSession) names() []string {
+	names := make([]string, len(s.environments))
+	for i, e := range s.environments {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// newEnvName normalizes and validates the name of an environment about to be created.
+// Aliases are expanded so `env create prod` cannot create an environment that
+// `pull -e prod` would never reach.
+func newEnvName(name string, deps *Dependencies) (string, error) {
+	normalized := envpkg.NormalizeEnvName(name)
+	if !envNamePattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid environment name '%s': use 2-30 lowercase letters, numbers, dashes or underscores, starting with a letter", name)
+	}
+	if normalized != strings.ToLower(strings.TrimSpace(name)) {
+		deps.UI.Info(fmt.Sprintf("'%s' is an alias for %s", name, deps.UI.Value(normalized)))
+	}
+	return normalized, nil
+}
+
+// runEnvListWithDeps is the testable version of runEnvList
+func runEnvListWithDeps(opts EnvOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("env list")
+	}
+
+	s, err := loadEnvSession(deps, opts.JSONOutput)
+	if err != nil {
+		return err
+	}
+
+	// Sync links are informational here; the list is still useful without them
+	var allSyncs []api.VaultSync
+	if details, err := s.client.GetVaultDetails(context.Background(), s.repo); err == nil && details != nil {
+		allSyncs = details.Syncs
+	}
+
+	entries := make([]EnvListEntry, len(s.environments))
+	for i, e := range s.environments {
+		entries[i] = EnvListEntry{Name: e.Name, Type: e.Type, Syncs: []api.VaultSync{}}
+		for _, sync := range allSyncs {
+			if sync.KeywayEnvironment == e.Name {
+				entries[i].Syncs = append(entries[i].Syncs, sync)
+			}
+		}
+	}
+
+	if opts.JSONOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		deps.UI.Info("No environments yet")
+		deps.UI.Message(deps.UI.Dim("Create one with: keyway env create <name>"))
+		return nil
+	}
+
+	deps.UI.Message("")
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %s", deps.UI.Bold(e.Name), deps.UI.Dim(e.Type))
+		for _, sync := range e.Syncs {
+			line += "  " + deps.UI.Dim(fmt.Sprintf("→ %s", describeSync(sync)))
+		}
+		deps.UI.Message(line)
+	}
+	return nil
+}
+
+// runEnvCreateWithDeps is the testable version of runEnvCreate
+func runEnvCreateWithDeps(opts EnvOptions, deps *Dependencies) error {
+	deps.UI.Intro("env create")
+
+	name, err := newEnvName(opts.Name, deps)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	s, err := loadEnvSession(deps, false)
+	if err != nil {
+		return err
+	}
+
+	if s.has(name) {
+		err := fmt.Errorf("environment '%s' already exists", name)
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	err = deps.UI.Spin(fmt.Sprintf("Creating %s...", name), func() error {
+		return s.client.CreateEnvironment(context.Background(), s.repo, name)
+	})
+	if err != nil {
+		return envCommandFailed(deps, "create", err)
+	}
+
+	analytics.Track(analytics.EventEnv, map[string]interface{}{
+		"repoFullName": s.repo,
+		"action":       "create",
+	})
+
+	deps.UI.Success(fmt.Sprintf("Created environment %s", deps.UI.Value(name)))
+	deps.UI.Outro(fmt.Sprintf("Add secrets with: %s", deps.UI.Command(fmt.Sprintf("keyway push -e %s", name))))
+	return nil
+}
+
+// runEnvRenameWithDeps is the testable version of runEnvRename
+func runEnvRenameWithDeps(opts EnvOptions, deps *Dependencies) error {
+	deps.UI.Intro("env rename")
+
+	newName, err := newEnvName(opts.NewName, deps)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	s, err := loadEnvSession(deps, false)
+	if err != nil {
+		return err
+	}
+
+	oldName, err := s.resolve(opts.Name)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if oldName == newName {
+		deps.UI.Info(fmt.Sprintf("Environment is already named %s", newName))
+		return nil
+	}
+	if s.has(newName) {
+		err := fmt.Errorf("environment '%s' already exists", newName)
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	// Sync links keep pointing at the old name on the server
+	links, err := s.syncsFor(oldName)
+	if err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not check sync links: %s", err.Error()))
+	}
+	if len(links) > 0 {
+		deps.UI.Warn(fmt.Sprintf("%d sync link(s) use %s and will need to be set up again:", len(links), oldName))
+		for _, sync := range links {
+			deps.UI.Message(deps.UI.Dim("  " + describeSync(sync)))
+		}
+	}
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Rename %s to %s?", oldName, newName), true)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	err = deps.UI.Spin(fmt.Sprintf("Renaming %s to %s...", oldName, newName), func() error {
+		return s.client.RenameEnvironment(context.Background(), s.repo, oldName, newName)
+	})
+	if err != nil {
+		return envCommandFailed(deps, "rename", err)
+	}
+	evictAgentCache(deps, s.repo, oldName)
+
+	analytics.Track(analytics.EventEnv, map[string]interface{}{
+		"repoFullName": s.repo,
+		"action":       "rename",
+	})
+
+	deps.UI.Success(fmt.Sprintf("Renamed %s to %s", oldName, deps.UI.Value(newName)))
+	if len(links) > 0 {
+		deps.UI.Outro(fmt.Sprintf("Reconnect syncs with: %s", deps.UI.Command(fmt.Sprintf("keyway sync -e %s", newName))))
+	}
+	return nil
+}
+
+// runEnvDeleteWithDeps is the testable version of runEnvDelete
+func runEnvDeleteWithDeps(opts EnvOptions, deps *Dependencies) error {
+	deps.UI.Intro("env delete")
+
+	s, err := loadEnvSession(deps, false)
+	if err != nil {
+		return err
+	}
+
+	name, err := s.resolve(opts.Name)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if len(s.environments) == 1 {
+		err := fmt.Errorf("cannot delete the last environment")
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	links, err := s.syncsFor(name)
+	if err != nil && !opts.Force {
+		deps.UI.Error(fmt.Sprintf("Could not check sync links: %s", err.Error()))
+		return err
+	}
+	if len(links) > 0 {
+		if !opts.Force {
+			deps.UI.Error(fmt.Sprintf("%s is used by %d sync link(s):", name, len(links)))
+			for _, sync := range links {
+				deps.UI.Message(deps.UI.Dim("  " + describeSync(sync)))
+			}
+			deps.UI.Message(fmt.Sprintf("Remove them from the dashboard first (%s), or pass --force", config.GetDashboardURL()))
+			return fmt.Errorf("environment has sync links")
+		}
+		deps.UI.Warn(fmt.Sprintf("Deleting %s breaks %d sync link(s)", name, len(links)))
+	}
+
+	secretCount := 0
+	if resp, err := s.client.PullSecrets(context.Background(), s.repo, name); err == nil {
+		secretCount = envpkg.CountLines(resp.Content)
+	}
+	deps.UI.Step(fmt.Sprintf("Environment: %s (%d secrets)", deps.UI.Value(name), secretCount))
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Delete %s and its %d secrets? This cannot be undone.", name, secretCount), false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	err = deps.UI.Spin(fmt.Sprintf("Deleting %s...", name), func() error {
+		return s.client.DeleteEnvironment(context.Background(), s.repo, name)
+	})
+	if err != nil {
+		return envCommandFailed(deps, "delete", err)
+	}
+	evictAgentCache(deps, s.repo, name)
+
+	analytics.Track(analytics.EventEnv, map[string]interface{}{
+		"repoFullName": s.repo,
+		"action":       "delete",
+		"secretCount":  secretCount,
+		"forced":       len(links) > 0,
+	})
+
+	deps.UI.Success(fmt.Sprintf("Deleted environment %s", name))
+	return nil
+}
+
+// runEnvCloneWithDeps is the testable version of runEnvClone
+func runEnvCloneWithDeps(opts EnvOptions, deps *Dependencies) error {
+	deps.UI.Intro("env clone")
+
+	newName, err := newEnvName(opts.NewName, deps)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	s, err := loadEnvSession(deps, false)
+	if err != nil {
+		return err
+	}
+
+	source, err := s.resolve(opts.Name)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if s.has(newName) {
+		err := fmt.Errorf("environment '%s' already exists", newName)
+		deps.UI.Error(err.Error())
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("To copy secrets into it, use: keyway promote %s %s", source, newName)))
+		return err
+	}
+
+	deps.UI.Step(fmt.Sprintf("Clone: %s → %s", deps.UI.Value(source), deps.UI.Value(newName)))
+
+	ctx := context.Background()
+	var secrets map[string]string
+	created := false
+	err = deps.UI.Spin(fmt.Sprintf("Cloning %s...", source), func() error {
+		resp, err := s.client.PullSecrets(ctx, s.repo, source)
+		if err != nil {
+			return err
+		}
+		secrets = envpkg.Parse(resp.Content)
+
+		if err := s.client.CreateEnvironment(ctx, s.repo, newName); err != nil {
+			return err
+		}
+		created = true
+		if len(secrets) == 0 {
+			return nil
+		}
+		_, err = s.client.PushSecrets(ctx, s.repo, newName, secrets)
+		return err
+	})
+	if err != nil && created {
+		// Don't leave an empty environment behind, which would make a retry
+		// fail with "already exists"
+		if deleteErr := s.client.DeleteEnvironment(ctx, s.repo, newName); deleteErr != nil {
+			deps.UI.Warn(fmt.Sprintf("%s was created but its secrets could not be pushed, and removing it failed: %v", newName, deleteErr))
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Remove it with: keyway env delete %s", newName)))
+		}
+	}
+	if err != nil {
+		return envCommandFailed(deps, "clone", err)
+	}
+
+	analytics.Track(analytics.EventEnv, map[string]interface{}{
+		"repoFullName": s.repo,
+		"action":       "clone",
+		"secretCount":  len(secrets),
+	})
+
+	deps.UI.Success(fmt.Sprintf("Created %s with %d secrets from %s", deps.UI.Value(newName), len(secrets), source))
+	deps.UI.Outro(deps.UI.Dim("Permissions and sync links are not copied"))
+	return nil
+}
+
+// envCommandFailed reports a failed environment mutation
+func envCommandFailed(deps *Dependencies, action string, err error) error {
+	analytics.Track(analytics.EventError, map[string]interface{}{
+		"command": "env " + action,
+		"error":   err.Error(),
+	})
+	deps.UI.Error(err.Error())
+	return err
+}
+
+// describeSync renders a sync link as "vercel: my-project (production)"
+func describeSync(sync api.VaultSync) string {
+	project := sync.ProjectName
+	if project == "" {
+		project = sync.ProjectID
+	}
+	return fmt.Sprintf("%s: %s (%s)", sync.Provider, project, sync.ProviderEnvironment)
+}
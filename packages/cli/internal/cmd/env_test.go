@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func defaultTestEnvironments() []api.Environment {
+	return []api.Environment{
+		{Name: "development", Type: "development"},
+		{Name: "staging", Type: "standard"},
+		{Name: "production", Type: "protected"},
+	}
+}
+
+func TestEnvSessionResolve(t *testing.T) {
+	s := &envSession{environments: []api.Environment{
+		{Name: "development"},
+		{Name: "production"},
+		{Name: "stg"}, // Legacy environment literally named like an alias
+	}}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"production", "production", false},
+		{"prod", "production", false},
+		{"PROD", "production", false},
+		{"dev", "development", false},
+		{"stg", "stg", false}, // exact match wins over alias
+		{"staging", "", true},
+		{"qa", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := s.resolve(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolve(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEnvName(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"preview", "preview", false},
+		{"prod", "production", false},
+		{"Stage", "staging", false},
+		{"qa-2", "qa-2", false},
+		{"x", "", true},
+		{"1st", "", true},
+		{"has space", "", true},
+		{strings.Repeat("a", 31), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			deps, _, _, _, _, _, _ := NewTestDepsWithEnv()
+			got, err := newEnvName(tt.input, deps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newEnvName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("newEnvName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunEnvListWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.VaultDetails = &api.VaultDetails{Syncs: []api.VaultSync{
+		{Provider: "vercel", ProjectName: "web", KeywayEnvironment: "production", ProviderEnvironment: "production"},
+	}}
+
+	if err := runEnvListWithDeps(EnvOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var prodLine string
+	for _, m := range uiMock.MessageCalls {
+		if strings.HasPrefix(m, "production") {
+			prodLine = m
+		}
+	}
+	if !strings.Contains(prodLine, "vercel: web") {
+		t.Errorf("expected production to show its sync link, got %q", prodLine)
+	}
+}
+
+func TestRunEnvListWithDeps_APIError(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.EnvironmentsError = errors.New("network error")
+
+	if err := runEnvListWithDeps(EnvOptions{}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunEnvCreateWithDeps(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+
+	if err := runEnvCreateWithDeps(EnvOptions{Name: "preview"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.EnvironmentCalls) != 1 || apiMock.EnvironmentCalls[0] != "create:preview" {
+		t.Errorf("unexpected calls: %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvCreateWithDeps_AliasOfExisting(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+
+	if err := runEnvCreateWithDeps(EnvOptions{Name: "prod"}, deps); err == nil {
+		t.Fatal("expected error: prod is production, which already exists")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 {
+		t.Errorf("expected no API mutation, got %v", apiMock.EnvironmentCalls)
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunEnvCreateWithDeps_ExpandsAlias(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = []api.Environment{{Name: "development"}}
+
+	if err := runEnvCreateWithDeps(EnvOptions{Name: "stg"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.EnvironmentCalls) != 1 || apiMock.EnvironmentCalls[0] != "create:staging" {
+		t.Errorf("expected staging to be created, got %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvRenameWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.VaultDetails = &api.VaultDetails{Syncs: []api.VaultSync{
+		{Provider: "railway", ProjectName: "api", KeywayEnvironment: "staging", ProviderEnvironment: "staging"},
+	}}
+
+	if err := runEnvRenameWithDeps(EnvOptions{Name: "stg", NewName: "qa", Yes: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.EnvironmentCalls) != 1 || apiMock.EnvironmentCalls[0] != "rename:staging:qa" {
+		t.Errorf("unexpected calls: %v", apiMock.EnvironmentCalls)
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning about the staging sync link")
+	}
+}
+
+func TestRunEnvRenameWithDeps_TargetExists(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+
+	if err := runEnvRenameWithDeps(EnvOptions{Name: "staging", NewName: "prod", Yes: true}, deps); err == nil {
+		t.Fatal("expected error: prod resolves to existing production")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 {
+		t.Errorf("expected no API mutation, got %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvRenameWithDeps_RequiresConfirmation(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+
+	if err := runEnvRenameWithDeps(EnvOptions{Name: "staging", NewName: "qa"}, deps); err == nil {
+		t.Fatal("expected confirmation error in non-interactive mode")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 {
+		t.Errorf("expected no API mutation, got %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvDeleteWithDeps(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.VaultDetails = &api.VaultDetails{}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1\nB=2\n"}
+
+	if err := runEnvDeleteWithDeps(EnvOptions{Name: "stg", Yes: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.EnvironmentCalls) != 1 || apiMock.EnvironmentCalls[0] != "delete:staging" {
+		t.Errorf("unexpected calls: %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvDeleteWithDeps_BlockedBySyncLinks(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.VaultDetails = &api.VaultDetails{Syncs: []api.VaultSync{
+		{Provider: "vercel", ProjectName: "web", KeywayEnvironment: "production", ProviderEnvironment: "production"},
+	}}
+
+	err := runEnvDeleteWithDeps(EnvOptions{Name: "prod", Yes: true}, deps)
+	if err == nil {
+		t.Fatal("expected deletion to be refused")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 {
+		t.Errorf("expected no API mutation, got %v", apiMock.EnvironmentCalls)
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunEnvDeleteWithDeps_ForceWithSyncLinks(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.VaultDetails = &api.VaultDetails{Syncs: []api.VaultSync{
+		{Provider: "vercel", ProjectName: "web", KeywayEnvironment: "production", ProviderEnvironment: "production"},
+	}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+
+	if err := runEnvDeleteWithDeps(EnvOptions{Name: "production", Yes: true, Force: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.EnvironmentCalls) != 1 || apiMock.EnvironmentCalls[0] != "delete:production" {
+		t.Errorf("unexpected calls: %v", apiMock.EnvironmentCalls)
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning about broken sync links")
+	}
+}
+
+func TestRunEnvDeleteWithDeps_SyncCheckFails(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.VaultDetailsError = errors.New("network error")
+
+	if err := runEnvDeleteWithDeps(EnvOptions{Name: "staging", Yes: true}, deps); err == nil {
+		t.Fatal("expected error when sync links cannot be checked")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 {
+		t.Errorf("expected no API mutation, got %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvDeleteWithDeps_LastEnvironment(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = []api.Environment{{Name: "production"}}
+
+	if err := runEnvDeleteWithDeps(EnvOptions{Name: "production", Yes: true}, deps); err == nil {
+		t.Fatal("expected error when deleting the last environment")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 {
+		t.Errorf("expected no API mutation, got %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvCloneWithDeps(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.PullByEnv = map[string]string{"production": "API_KEY=live\nDB_URL=postgres://prod\n"}
+	apiMock.PushResponse = &api.PushSecretsResponse{}
+
+	if err := runEnvCloneWithDeps(EnvOptions{Name: "prod", NewName: "prod-backup"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.EnvironmentCalls) != 1 || apiMock.EnvironmentCalls[0] != "create:prod-backup" {
+		t.Errorf("unexpected calls: %v", apiMock.EnvironmentCalls)
+	}
+	if apiMock.PushedEnv != "prod-backup" || apiMock.PushedSecrets["API_KEY"] != "live" || len(apiMock.PushedSecrets) != 2 {
+		t.Errorf("unexpected push: env=%s secrets=%v", apiMock.PushedEnv, apiMock.PushedSecrets)
+	}
+}
+
+func TestRunEnvCloneWithDeps_PushFailureRemovesEnvironment(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+	apiMock.PullByEnv = map[string]string{"production": "API_KEY=live\n"}
+	apiMock.PushError = errors.New("network error")
+
+	if err := runEnvCloneWithDeps(EnvOptions{Name: "production", NewName: "prod-backup"}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Join(apiMock.EnvironmentCalls, ",") != "create:prod-backup,delete:prod-backup" {
+		t.Errorf("expected the new environment to be removed, got %v", apiMock.EnvironmentCalls)
+	}
+}
+
+func TestRunEnvCloneWithDeps_TargetExists(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.Environments = defaultTestEnvironments()
+
+	if err := runEnvCloneWithDeps(EnvOptions{Name: "production", NewName: "stg"}, deps); err == nil {
+		t.Fatal("expected error: stg resolves to existing staging")
+	}
+	if len(apiMock.EnvironmentCalls) != 0 || apiMock.PushedSecrets != nil {
+		t.Error("expected nothing to be created or pushed")
+	}
+}
@@ -7,7 +7,12 @@ import (
 // osReadFile wraps os.ReadFile
 var osReadFile = os.ReadFile
 
-// osWriteFile wraps os.WriteFile with proper permissions
+// osWriteFile wraps os.WriteFile with proper permissions. os.WriteFile only
+// applies perm to a file it creates, so an existing file is chmoded too: a
+// world-readable file doesn't stay so once secrets are written to it.
 var osWriteFile = func(name string, data []byte, perm uint32) error {
-	return os.WriteFile(name, data, os.FileMode(perm))
+	if err := os.WriteFile(name, data, os.FileMode(perm)); err != nil {
+		return err
+	}
+	return os.Chmod(name, os.FileMode(perm))
 }
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <KEY>",
+	Short: "Show previous versions of a secret",
+	Long: `List previous versions of a secret with timestamps and authors.
+
+A version is saved every time a secret's value is replaced, so each entry shows
+when that value stopped being current and who replaced it. Values are never
+displayed. Use 'keyway rollback' to restore one.
+
+Examples:
+  keyway history API_KEY
+  keyway history DATABASE_URL -e production
+  keyway history DATABASE_URL -e production --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringP("env", "e", "development", "Environment name")
+	historyCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// HistoryOptions contains the parsed flags for the history command
+type HistoryOptions struct {
+	Key        string
+	EnvName    string
+	JSONOutput bool
+}
+
+// HistoryEntry is one line of a secret's history (JSON output)
+type HistoryEntry struct {
+	Version   int       `json:"version"`
+	ID        string    `json:"id"`
+	Current   bool      `json:"current"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    string    `json:"author,omitempty"`
+}
+
+// runHistory is the entry point for the history command (uses default dependencies)
+func runHistory(cmd *cobra.Command, args []string) error {
+	opts := HistoryOptions{Key: args[0]}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runHistoryWithDeps(opts, defaultDeps)
+}
+
+// runHistoryWithDeps is the testable version of runHistory
+func runHistoryWithDeps(opts HistoryOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("history")
+	}
+
+	envName := normalizeEnvName(opts.EnvName)
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	var secret *api.SecretItem
+	var versions []api.SecretVersion
+	fetch := func() error {
+		return deps.UI.Spin("Fetching history...", func() error {
+			var findErr error
+			secret, findErr = findSecretItem(ctx, client, repo, opts.Key, envName)
+			if findErr != nil {
+				return findErr
+			}
+			versions, findErr = client.GetSecretVersions(ctx, repo, secret.ID)
+			return findErr
+		})
+	}
+
+	err = fetch()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	entries := buildHistoryEntries(secret, versions)
+
+	if opts.JSONOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+	deps.UI.Message("")
+
+	for _, e := range entries {
+		label := fmt.Sprintf("v%d", e.Version)
+		when := e.Timestamp.Local().Format("2006-01-02 15:04:05")
+		author := e.Author
+		if author == "" {
+			author = "unknown"
+		}
+		if e.Current {
+			deps.UI.Message(fmt.Sprintf("%s  %s  %s  %s", deps.UI.Bold(label), when, author, deps.UI.Dim("(current)")))
+		} else {
+			deps.UI.Message(fmt.Sprintf("%s  %s  %s", label, when, deps.UI.Dim("replaced by "+author)))
+		}
+	}
+
+	if len(versions) == 0 {
+		deps.UI.Message("")
+		deps.UI.Info("No previous versions")
+		return nil
+	}
+
+	deps.UI.Message("")
+	deps.UI.Outro(fmt.Sprintf("Restore with: %s", deps.UI.Command(fmt.Sprintf("keyway rollback %s --to <version> -e %s", opts.Key, envName))))
+	return nil
+}
+
+// buildHistoryEntries lists the current value first, then previous versions newest first.
+// The current value is numbered right after the latest saved version.
+func buildHistoryEntries(secret *api.SecretItem, versions []api.SecretVersion) []HistoryEntry {
+	current := HistoryEntry{
+		Version:   1,
+		ID:        secret.ID,
+		Current:   true,
+		Timestamp: secret.UpdatedAt,
+	}
+	if secret.LastModifiedBy != nil {
+		current.Author = secret.LastModifiedBy.Username
+	}
+
+	entries := []HistoryEntry{current}
+	for _, v := range versions {
+		if v.VersionNumber >= entries[0].Version {
+			entries[0].Version = v.VersionNumber + 1
+		}
+		entry := HistoryEntry{
+			Version:   v.VersionNumber,
+			ID:        v.ID,
+			Timestamp: v.CreatedAt,
+		}
+		if v.CreatedBy != nil {
+			entry.Author = v.CreatedBy.Username
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// findSecretItem looks up a secret's metadata by key and environment
+func findSecretItem(ctx context.Context, client api.APIClient, repo, key, envName string) (*api.SecretItem, error) {
+	items, err := client.ListSecrets(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Key == key && items[i].Environment == envName {
+			return &items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("secret %s not found in %s", key, envName)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestBuildHistoryEntries(t *testing.T) {
+	secret := &api.SecretItem{
+		ID:             "s1",
+		Key:            "API_KEY",
+		UpdatedAt:      time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC),
+		LastModifiedBy: &api.UserRef{Username: "alice"},
+	}
+	versions := []api.SecretVersion{
+		{ID: "v2", VersionNumber: 2, CreatedAt: time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC), CreatedBy: &api.UserRef{Username: "alice"}},
+		{ID: "v1", VersionNumber: 1, CreatedAt: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)},
+	}
+
+	entries := buildHistoryEntries(secret, versions)
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if !entries[0].Current || entries[0].Version != 3 || entries[0].Author != "alice" {
+		t.Errorf("unexpected current entry: %+v", entries[0])
+	}
+	if entries[1].Version != 2 || entries[1].Author != "alice" {
+		t.Errorf("unexpected entry: %+v", entries[1])
+	}
+	if entries[2].Version != 1 || entries[2].Author != "" {
+		t.Errorf("unexpected entry: %+v", entries[2])
+	}
+}
+
+func TestBuildHistoryEntries_NoVersions(t *testing.T) {
+	entries := buildHistoryEntries(&api.SecretItem{ID: "s1"}, nil)
+	if len(entries) != 1 || !entries[0].Current || entries[0].Version != 1 {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestRunHistoryWithDeps_Success(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.SecretItems = []api.SecretItem{
+		{ID: "dev", Key: "API_KEY", Environment: "development"},
+		{ID: "prod", Key: "API_KEY", Environment: "production"},
+	}
+	apiMock.SecretVersions = map[string][]api.SecretVersion{
+		"prod": {{ID: "v1", VersionNumber: 1}},
+	}
+
+	err := runHistoryWithDeps(HistoryOptions{Key: "API_KEY", EnvName: "prod"}, deps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Current value + 1 version
+	versionLines := 0
+	for _, m := range uiMock.MessageCalls {
+		if len(m) > 1 && m[0] == 'v' {
+			versionLines++
+		}
+	}
+	if versionLines != 2 {
+		t.Errorf("expected 2 version lines, got %d: %v", versionLines, uiMock.MessageCalls)
+	}
+}
+
+func TestRunHistoryWithDeps_SecretNotFound(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.SecretItems = []api.SecretItem{
+		{ID: "dev", Key: "API_KEY", Environment: "development"},
+	}
+
+	err := runHistoryWithDeps(HistoryOptions{Key: "API_KEY", EnvName: "production"}, deps)
+	if err == nil {
+		t.Fatal("expected error for missing secret")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunHistoryWithDeps_APIError(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.SecretItemsError = errors.New("network error")
+
+	if err := runHistoryWithDeps(HistoryOptions{Key: "API_KEY", EnvName: "development"}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/git"
+	"github.com/keywaysh/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage the git hooks that keep secrets out of commits",
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook that blocks commits adding secrets",
+	Long: `Install a pre-commit hook that runs keyway scan --staged, which only looks
+at the lines the commit adds, and blocks the commit when it finds a secret.
+
+A pre-commit hook already in place keeps running, before keyway's. In a
+repository using the pre-commit framework (.pre-commit-config.yaml), the scan is
+added to its configuration instead.
+
+The hook fails closed: when keyway is not in the PATH of git (as can happen
+in graphical git clients), the commit is blocked rather than left unscanned.
+Skip the check for one commit with git commit --no-verify.`,
+	Args: cobra.NoArgs,
+	RunE: runHooksInstall,
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksInstallCmd)
+}
+
+const (
+	// hookMarker identifies the hooks that keyway installed
+	hookMarker = "# Installed by keyway hooks install"
+	// chainedHookSuffix is added to the name of the hook that keyway's
+	// replaced, which keyway's runs first
+	chainedHookSuffix = ".pre-keyway"
+	// preCommitConfig is the configuration of the pre-commit framework
+	preCommitConfig = ".pre-commit-config.yaml"
+	// preCommitHookID identifies keyway's hook in preCommitConfig
+	preCommitHookID = "keyway-scan"
+)
+
+// preCommitHook is the script of keyway's pre-commit hook
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `: blocks commits that add secrets.
+# Skip it once with git commit --no-verify.
+
+chained="$(dirname "$0")/pre-commit` + chainedHookSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+
+if ! command -v keyway >/dev/null 2>&1; then
+	echo "keyway not found in PATH: staged changes can't be scanned for secrets" >&2
+	echo "Install keyway, or skip the check once with git commit --no-verify" >&2
+	exit 1
+fi
+exec keyway scan --staged
+`
+
+// hookInstall describes what installPreCommitHook did
+type hookInstall struct {
+	// Path is the file that was written
+	Path string
+	// Chained is the hook that was already there, and now runs first
+	Chained string
+	// Framework is set when the hook was added to the pre-commit framework
+	Framework bool
+	// Already is set when keyway's hook was installed already
+	Already bool
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	ui.Intro("hooks install")
+
+	root, err := git.GetGitRoot()
+	if err != nil {
+		ui.Error("Not in a git repository")
+		return err
+	}
+	hooksDir, err := git.HooksDir(root)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+
+	result, err := installPreCommitHook(root, hooksDir)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+
+	analytics.Track(analytics.EventHooks, map[string]interface{}{
+		"framework": result.Framework,
+		"chained":   result.Chained != "",
+	})
+
+	switch {
+	case result.Already:
+		ui.Success(fmt.Sprintf("The keyway hook is already installed in %s", ui.File(result.Path)))
+	case result.Framework:
+		ui.Success(fmt.Sprintf("Added keyway scan --staged to %s", ui.File(result.Path)))
+		ui.Step("Run pre-commit install if the framework's hook isn't installed yet")
+	default:
+		ui.Success(fmt.Sprintf("Installed %s", ui.File(result.Path)))
+		if result.Chained != "" {
+			ui.Step(fmt.Sprintf("Your existing hook keeps running first, from %s", ui.File(result.Chained)))
+		}
+	}
+	ui.Outro("Commits adding secrets are now blocked - skip the check once with git commit --no-verify")
+	return nil
+}
+
+// installPreCommitHook installs keyway's pre-commit hook in the repository at
+// root: in the pre-commit framework's configuration when it has one, or in
+// hooksDir, moving an existing hook aside to run it first
+func installPreCommitHook(root, hooksDir string) (hookInstall, error) {
+	configPath := filepath.Join(root, preCommitConfig)
+	if config, err := os.ReadFile(configPath); err == nil {
+		updated, already, err := addPreCommitEntry(string(config))
+		if err != nil {
+			return hookInstall{}, fmt.Errorf("%s: %w", preCommitConfig, err)
+		}
+		if already {
+			return hookInstall{Path: configPath, Framework: true, Already: true}, nil
+		}
+		if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+			return hookInstall{}, err
+		}
+		return hookInstall{Path: configPath, Framework: true}, nil
+	}
+
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	result := hookInstall{Path: hookPath}
+	if existing, err := os.ReadFile(hookPath); err == nil {
+		if strings.Contains(string(existing), hookMarker) {
+			result.Already = true
+			return result, nil
+		}
+		chained := hookPath + chainedHookSuffix
+		if _, err := os.Stat(chained); err == nil {
+			return hookInstall{}, fmt.Errorf("%s already exists: move it away first", chained)
+		}
+		if err := os.Rename(hookPath, chained); err != nil {
+			return hookInstall{}, err
+		}
+		result.Chained = chained
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return hookInstall{}, err
+	}
+	if err := os.WriteFile(hookPath, []byte(preCommitHook), 0755); err != nil {
+		return hookInstall{}, err
+	}
+	// WriteFile leaves the mode of an existing file alone
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return hookInstall{}, err
+	}
+	return result, nil
+}
+
+// preCommitRepos matches the top-level repos key of a pre-commit
+// configuration, and the indentation of its first item
+var preCommitRepos = regexp.MustCompile(`(?m)^repos:[ \t]*\n(?:[ \t]*(?:#.*)?\n)*([ \t]*)-`)
+
+// addPreCommitEntry adds keyway's hook, as a local repository, at the top of
+// the repos of a pre-commit configuration. The rest of the file is left as
+// it is, comments included.
+func addPreCommitEntry(config string) (string, bool, error) {
+	if strings.Contains(config, "id: "+preCommitHookID) {
+		return config, true, nil
+	}
+	m := preCommitRepos.FindStringSubmatchIndex(config)
+	if m == nil {
+		return "", false, fmt.Errorf("no repos list found: add keyway scan --staged as a local hook by hand")
+	}
+	indent := config[m[2]:m[3]]
+	entry := indent + "- repo: local\n" +
+		indent + "  hooks:\n" +
+		indent + "    - id: " + preCommitHookID + "\n" +
+		indent + "      name: keyway scan\n" +
+		indent + "      entry: keyway scan --staged\n" +
+		indent + "      language: system\n" +
+		indent + "      pass_filenames: false\n"
+	// Insert right after the repos: line
+	at := strings.Index(config[m[0]:], "\n") + m[0] + 1
+	return config[:at] + entry + config[at:], false, nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestInstallPreCommitHook(t *testing.T) {
+	root := t.TempDir()
+	hooksDir := filepath.Join(root, ".git", "hooks")
+
+	result, err := installPreCommitHook(root, hooksDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	if result.Path != hookPath || result.Chained != "" || result.Already {
+		t.Errorf("unexpected result %+v", result)
+	}
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Error("expected the hook to be executable")
+	}
+	data, _ := os.ReadFile(hookPath)
+	if !strings.Contains(string(data), "keyway scan --staged") {
+		t.Errorf("expected the hook to scan staged changes, got:\n%s", data)
+	}
+
+	result, err = installPreCommitHook(root, hooksDir)
+	if err != nil || !result.Already {
+		t.Errorf("expected the hook to be installed already, got %+v, %v", result, err)
+	}
+}
+
+func TestPreCommitHook_FailsWithoutKeyway(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook is a shell script")
+	}
+	hookPath := filepath.Join(t.TempDir(), "pre-commit")
+	if err := os.WriteFile(hookPath, []byte(preCommitHook), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A PATH with sh and dirname, but no keyway
+	for _, dir := range []string{"/usr/bin", "/bin"} {
+		if _, err := os.Stat(filepath.Join(dir, "keyway")); err == nil {
+			t.Skip("keyway is installed system-wide")
+		}
+	}
+	cmd := exec.Command("/bin/sh", hookPath)
+	cmd.Env = []string{"PATH=/usr/bin:/bin"}
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() == 0 {
+		t.Fatalf("expected the hook to block the commit, got %v: %s", err, out)
+	}
+	if !strings.Contains(string(out), "--no-verify") {
+		t.Errorf("expected the hook to mention --no-verify, got:\n%s", out)
+	}
+}
+
+func TestInstallPreCommitHook_ChainsExistingHook(t *testing.T) {
+	root := t.TempDir()
+	hooksDir := filepath.Join(root, ".git", "hooks")
+	_ = os.MkdirAll(hooksDir, 0755)
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	existing := "#!/bin/sh\nnpm run lint\n"
+	_ = os.WriteFile(hookPath, []byte(existing), 0700)
+
+	result, err := installPreCommitHook(root, hooksDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Chained != hookPath+chainedHookSuffix {
+		t.Errorf("expected the existing hook to be chained, got %+v", result)
+	}
+	if data, _ := os.ReadFile(result.Chained); string(data) != existing {
+		t.Errorf("expected the existing hook to be kept, got:\n%s", data)
+	}
+	if data, _ := os.ReadFile(hookPath); !strings.Contains(string(data), hookMarker) {
+		t.Errorf("expected keyway's hook, got:\n%s", data)
+	}
+}
+
+func TestInstallPreCommitHook_Framework(t *testing.T) {
+	root := t.TempDir()
+	config := "repos:\n  - repo: https://github.com/pre-commit/pre-commit-hooks\n    rev: v4.5.0\n    hooks:\n      - id: trailing-whitespace\n"
+	_ = os.WriteFile(filepath.Join(root, preCommitConfig), []byte(config), 0644)
+
+	result, err := installPreCommitHook(root, filepath.Join(root, ".git", "hooks"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Framework {
+		t.Errorf("expected the framework's configuration to be used, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git", "hooks", "pre-commit")); err == nil {
+		t.Error("expected no git hook to be written")
+	}
+	data, _ := os.ReadFile(filepath.Join(root, preCommitConfig))
+	if !strings.Contains(string(data), "entry: keyway scan --staged") || !strings.HasSuffix(string(data), config[len("repos:\n"):]) {
+		t.Errorf("expected keyway's hook before the others, got:\n%s", data)
+	}
+}
+
+func TestAddPreCommitEntry(t *testing.T) {
+	config := "# hooks\nrepos:\n# formatting\n-   repo: local\n    hooks: []\nci:\n  autofix_prs: false\n"
+
+	got, already, err := addPreCommitEntry(config)
+	if err != nil || already {
+		t.Fatalf("expected the entry to be added, got %v, %v", already, err)
+	}
+	want := "# hooks\nrepos:\n- repo: local\n  hooks:\n    - id: keyway-scan\n      name: keyway scan\n      entry: keyway scan --staged\n      language: system\n      pass_filenames: false\n# formatting\n-   repo: local\n    hooks: []\nci:\n  autofix_prs: false\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, already, _ := addPreCommitEntry(got); !already {
+		t.Error("expected the entry to be found the second time")
+	}
+	if _, _, err := addPreCommitEntry("repos: []\n"); err == nil {
+		t.Error("expected an error without a repos list")
+	}
+}
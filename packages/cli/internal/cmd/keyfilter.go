@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// keyFilter selects and renames the secrets given to a command, so that it
+// only sees the keys it needs
+type keyFilter struct {
+	// Only keeps keys matching one of these globs (all keys when empty)
+	Only []string
+	// Exclude drops keys matching one of these globs
+	Exclude []string
+	// StripPrefix is removed from the keys that start with it
+	StripPrefix string
+	// Prefix is added to every key, after StripPrefix is removed
+	Prefix string
+}
+
+// validate reports malformed globs before anything is fetched
+func (f keyFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.Only...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// active reports whether the filter changes anything
+func (f keyFilter) active() bool {
+	return len(f.Only) > 0 || len(f.Exclude) > 0 || f.StripPrefix != "" || f.Prefix != ""
+}
+
+// selects reports whether a key is kept by --only and --exclude
+func (f keyFilter) selects(key string) bool {
+	if len(f.Only) > 0 && !matchAny(f.Only, key) {
+		return false
+	}
+	return !matchAny(f.Exclude, key)
+}
+
+// apply returns the selected secrets under their new names. When stripping a
+// prefix makes two keys collide, the one that had the prefix wins.
+func (f keyFilter) apply(secrets map[string]string) map[string]string {
+	if !f.active() {
+		return secrets
+	}
+	out := make(map[string]string, len(secrets))
+	stripped := make(map[string]bool)
+	for key, value := range secrets {
+		if !f.selects(key) {
+			continue
+		}
+		name := key
+		if f.StripPrefix != "" && strings.HasPrefix(key, f.StripPrefix) && key != f.StripPrefix {
+			name = strings.TrimPrefix(key, f.StripPrefix)
+			stripped[name] = true
+		} else if stripped[name] {
+			continue
+		}
+		out[f.Prefix+name] = value
+	}
+	return out
+}
+
+// matchAny reports whether key matches one of the globs
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyFilter_Apply(t *testing.T) {
+	secrets := map[string]string{
+		"DB_URL":                "postgres://prod",
+		"DB_PASSWORD":           "hunter2",
+		"STRIPE_KEY":            "sk_live_123",
+		"NEXT_PUBLIC_API_URL":   "https://api",
+		"NEXT_PUBLIC_SITE_NAME": "Keyway",
+		"API_URL":               "https://internal",
+	}
+
+	tests := []struct {
+		name   string
+		filter keyFilter
+		want   map[string]string
+	}{
+		{
+			name:   "no filter",
+			filter: keyFilter{},
+			want:   secrets,
+		},
+		{
+			name:   "only",
+			filter: keyFilter{Only: []string{"DB_*", "STRIPE_KEY"}},
+			want:   map[string]string{"DB_URL": "postgres://prod", "DB_PASSWORD": "hunter2", "STRIPE_KEY": "sk_live_123"},
+		},
+		{
+			name:   "exclude wins over only",
+			filter: keyFilter{Only: []string{"DB_*"}, Exclude: []string{"*PASSWORD"}},
+			want:   map[string]string{"DB_URL": "postgres://prod"},
+		},
+		{
+			name:   "strip and add prefix",
+			filter: keyFilter{Only: []string{"NEXT_PUBLIC_*"}, StripPrefix: "NEXT_PUBLIC_", Prefix: "VITE_"},
+			want:   map[string]string{"VITE_API_URL": "https://api", "VITE_SITE_NAME": "Keyway"},
+		},
+		{
+			name:   "stripped key wins a collision",
+			filter: keyFilter{Only: []string{"*API_URL"}, StripPrefix: "NEXT_PUBLIC_"},
+			want:   map[string]string{"API_URL": "https://api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.apply(secrets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyFilter_Validate(t *testing.T) {
+	if err := (keyFilter{Only: []string{"DB_*"}, Exclude: []string{"[A-Z]*_OLD"}}).validate(); err != nil {
+		t.Errorf("expected valid globs, got %v", err)
+	}
+	if err := (keyFilter{Exclude: []string{"DB_["}}).validate(); err == nil {
+		t.Error("expected error for a malformed glob")
+	}
+}
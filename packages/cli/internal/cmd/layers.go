@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// fetchEnvLayers pulls each environment with its keyway:// references
+// resolved, and layers them in order so later environments override earlier
+// ones. It also returns how many references were resolved.
+func fetchEnvLayers(ctx context.Context, client api.APIClient, repo string, envNames []string) (map[string]string, int, error) {
+	contents := make(map[string]string, len(envNames))
+	for _, envName := range envNames {
+		resp, err := client.PullSecrets(ctx, repo, envName)
+		if err != nil {
+			return nil, 0, err
+		}
+		contents[envName] = resp.Content
+	}
+	return layerContents(ctx, client, repo, envNames, contents)
+}
+
+// layerContents resolves the references in the pulled content of each
+// environment and layers them in order
+func layerContents(ctx context.Context, client api.APIClient, repo string, envNames []string, contents map[string]string) (map[string]string, int, error) {
+	layers := make([]map[string]string, 0, len(envNames))
+	refs := 0
+	for _, envName := range envNames {
+		secrets, refKeys, err := resolveSecretRefs(ctx, client, repo, envName, env.Parse(contents[envName]))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to resolve references in %s: %w", envName, err)
+		}
+		refs += len(refKeys)
+		layers = append(layers, secrets)
+	}
+	return env.Layer(layers...), refs, nil
+}
+
+// envNamesFlag splits the values of a repeatable --env flag into the base
+// layers and the main environment, which is the last one given
+func envNamesFlag(values []string) (base []string, main string) {
+	if len(values) == 0 {
+		return nil, "development"
+	}
+	return values[:len(values)-1], values[len(values)-1]
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Work with the .keyway.lock file",
+	Long: `keyway pull records salted fingerprints of the pulled secrets in .keyway.lock.
+The file never contains values. Commit it so reviewers see which keys changed
+in a PR, and CI can check the vault still matches it.
+
+Fingerprints are slow Argon2id hashes, so every guess at a value is expensive,
+but anyone who can read the file can still confirm a guess. Short or guessable
+values (PINs, enum-like flags) are exposed that way: keep the lockfile out of
+public repositories that hold them.
+
+Examples:
+  keyway lock verify
+  keyway lock verify -e production`,
+}
+
+var lockVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the vault matches .keyway.lock",
+	Long: `Check that the vault matches .keyway.lock.
+
+Every environment in the lockfile is compared with the vault, or only the one
+given with --env. Exits with a non-zero status if any key was added, changed or
+removed since the lockfile was written.`,
+	Args: cobra.NoArgs,
+	RunE: runLockVerify,
+}
+
+func init() {
+	lockVerifyCmd.Flags().StringP("env", "e", "", "Only verify this environment")
+
+	lockCmd.AddCommand(lockVerifyCmd)
+}
+
+// LockOptions contains the parsed flags for the lock subcommands
+type LockOptions struct {
+	EnvName string
+}
+
+// runLockVerify is the entry point for the lock verify command (uses default dependencies)
+func runLockVerify(cmd *cobra.Command, args []string) error {
+	opts := LockOptions{}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+
+	return runLockVerifyWithDeps(opts, defaultDeps)
+}
+
+// runLockVerifyWithDeps is the testable version of runLockVerify
+func runLockVerifyWithDeps(opts LockOptions, deps *Dependencies) error {
+	deps.UI.Intro("lock verify")
+
+	data, err := deps.FS.ReadFile(env.LockfileName)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("No %s found", env.LockfileName))
+		deps.UI.Message(deps.UI.Dim("Run keyway pull to create it"))
+		return fmt.Errorf("no %s found", env.LockfileName)
+	}
+	lock, err := env.ParseLockfile(data)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if lock.Outdated() {
+		deps.UI.Warn(fmt.Sprintf("%s uses fingerprints that are fast to brute-force - run keyway pull to rewrite it", env.LockfileName))
+	}
+
+	envNames := lock.EnvironmentNames()
+	if opts.EnvName != "" {
+		envName := env.NormalizeEnvName(opts.EnvName)
+		if _, ok := lock.Environments[envName]; !ok {
+			deps.UI.Error(fmt.Sprintf("Environment %s is not in %s", envName, env.LockfileName))
+			return fmt.Errorf("environment %q is not locked", envName)
+		}
+		envNames = []string{envName}
+	}
+	if len(envNames) == 0 {
+		deps.UI.Warn(fmt.Sprintf("%s has no environments", env.LockfileName))
+		return nil
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	vaults := make(map[string]*api.PullSecretsResponse, len(envNames))
+	fetch := func() error {
+		for _, envName := range envNames {
+			resp, err := client.PullSecrets(ctx, repo, envName)
+			if err != nil {
+				// An environment emptied since the lock was written shows up as removed keys
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+					resp = &api.PullSecretsResponse{}
+				} else {
+					return err
+				}
+			}
+			vaults[envName] = resp
+		}
+		return nil
+	}
+
+	err = deps.UI.Spin("Fetching vault state...", fetch)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = deps.UI.Spin("Fetching vault state...", fetch)
+	}
+	if err != nil {
+		analytics.Track(analytics.EventError, map[string]interface{}{
+			"command": "lock verify",
+			"error":   err.Error(),
+		})
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	mismatched := 0
+	for _, envName := range envNames {
+		resp := vaults[envName]
+		diff, err := lock.Verify(envName, env.Parse(resp.Content))
+		if err != nil {
+			return err
+		}
+
+		deps.UI.Message("")
+		if !diff.HasChanges() {
+			deps.UI.Message(fmt.Sprintf("%s matches the lockfile", deps.UI.Bold(envName)))
+			continue
+		}
+
+		mismatched++
+		deps.UI.Message(fmt.Sprintf("%s differs from the lockfile:", deps.UI.Bold(envName)))
+		for _, key := range diff.Added {
+			deps.UI.DiffAdded(key)
+		}
+		for _, key := range diff.Changed {
+			deps.UI.DiffChanged(key)
+		}
+		for _, key := range diff.Removed {
+			deps.UI.DiffRemoved(key)
+		}
+		if locked := lock.Environments[envName].Revision; locked != "" && resp.Revision != "" && locked != resp.Revision {
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Locked at revision %s, vault is at %s", locked, resp.Revision)))
+		}
+	}
+	deps.UI.Message("")
+
+	analytics.Track(analytics.EventLockVerify, map[string]interface{}{
+		"repoFullName": repo,
+		"environments": len(envNames),
+		"mismatched":   mismatched,
+	})
+
+	if mismatched > 0 {
+		deps.UI.Error(fmt.Sprintf("%d environment(s) do not match %s", mismatched, env.LockfileName))
+		deps.UI.Message(deps.UI.Dim("Run keyway pull to update the lockfile"))
+		return fmt.Errorf("vault does not match %s", env.LockfileName)
+	}
+
+	deps.UI.Outro(fmt.Sprintf("Vault matches %s", env.LockfileName))
+	return nil
+}
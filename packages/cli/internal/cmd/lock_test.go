@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// writeTestLockfile locks the given environments into the mock file system
+func writeTestLockfile(t *testing.T, fsMock *MockFileSystem, envs map[string]map[string]string) {
+	t.Helper()
+	lock, err := env.NewLockfile()
+	if err != nil {
+		t.Fatalf("NewLockfile failed: %v", err)
+	}
+	for name, secrets := range envs {
+		lock.Record(name, "rev1", secrets)
+	}
+	data, err := lock.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	fsMock.Files[env.LockfileName] = data
+}
+
+func TestRunLockVerifyWithDeps_Matches(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	writeTestLockfile(t, fsMock, map[string]map[string]string{
+		"production": {"API_KEY": "prod"},
+		"staging":    {"API_KEY": "stg"},
+	})
+	apiMock.PullByEnv = map[string]string{"production": "API_KEY=prod", "staging": "API_KEY=stg"}
+
+	if err := runLockVerifyWithDeps(LockOptions{}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.OutroCalls) != 1 {
+		t.Errorf("expected success outro, got %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunLockVerifyWithDeps_Mismatch(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	writeTestLockfile(t, fsMock, map[string]map[string]string{
+		"production": {"API_KEY": "prod", "GONE": "x"},
+	})
+	apiMock.PullByEnv = map[string]string{"production": "API_KEY=rotated\nNEW=1"}
+
+	if err := runLockVerifyWithDeps(LockOptions{}, deps); err == nil {
+		t.Fatal("expected mismatch error")
+	}
+	if len(uiMock.DiffAddedCalls) != 1 || len(uiMock.DiffChangedCalls) != 1 || len(uiMock.DiffRemovedCalls) != 1 {
+		t.Errorf("unexpected diff: added=%v changed=%v removed=%v",
+			uiMock.DiffAddedCalls, uiMock.DiffChangedCalls, uiMock.DiffRemovedCalls)
+	}
+}
+
+func TestRunLockVerifyWithDeps_SingleEnvironment(t *testing.T) {
+	deps, _, _, _, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	writeTestLockfile(t, fsMock, map[string]map[string]string{
+		"production": {"API_KEY": "prod"},
+		"staging":    {"API_KEY": "stg"},
+	})
+	// staging drifted, but only production is verified
+	apiMock.PullByEnv = map[string]string{"production": "API_KEY=prod", "staging": "API_KEY=other"}
+
+	if err := runLockVerifyWithDeps(LockOptions{EnvName: "prod"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := runLockVerifyWithDeps(LockOptions{EnvName: "preview"}, deps); err == nil {
+		t.Error("expected error for an environment missing from the lockfile")
+	}
+}
+
+func TestRunLockVerifyWithDeps_NoLockfile(t *testing.T) {
+	deps, _, _, uiMock, _, _, _ := NewTestDepsWithEnv()
+
+	if err := runLockVerifyWithDeps(LockOptions{}, deps); err == nil {
+		t.Fatal("expected error without a lockfile")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected error to be shown")
+	}
+}
+
+func TestRunPullWithDeps_WritesLockfile(t *testing.T) {
+	deps, _, _, _, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	writeTestLockfile(t, fsMock, map[string]map[string]string{"staging": {"K": "v"}})
+	before, _ := env.ParseLockfile(fsMock.Files[env.LockfileName])
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123", Revision: "rev2"}
+
+	opts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data := fsMock.Written[env.LockfileName]
+	if strings.Contains(string(data), "secret123") {
+		t.Error("lockfile must not contain secret values")
+	}
+	lock, err := env.ParseLockfile(data)
+	if err != nil {
+		t.Fatalf("ParseLockfile failed: %v", err)
+	}
+	if lock.Salt != before.Salt {
+		t.Error("expected the existing salt to be kept")
+	}
+	if _, ok := lock.Environments["staging"]; !ok {
+		t.Error("expected other environments to be kept")
+	}
+	dev := lock.Environments["development"]
+	if dev == nil || dev.Revision != "rev2" || dev.Secrets["API_KEY"] != lock.Hash("API_KEY", "secret123") {
+		t.Errorf("unexpected development entry: %+v", dev)
+	}
+}
+
+func TestRunPullWithDeps_RewritesOutdatedLockfile(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[env.LockfileName] = []byte(`{"version": 1, "salt": "ab", "environments": {"staging": {"secrets": {"K": "00"}}}}`)
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123", Revision: "rev2"}
+
+	opts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	lock, err := env.ParseLockfile(fsMock.Written[env.LockfileName])
+	if err != nil {
+		t.Fatalf("ParseLockfile failed: %v", err)
+	}
+	if lock.Outdated() || lock.Salt == "ab" {
+		t.Errorf("expected a new lockfile in the current format, got version %d", lock.Version)
+	}
+	if _, ok := lock.Environments["staging"]; ok {
+		t.Error("expected the outdated fingerprints to be dropped")
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning about the rewrite")
+	}
+}
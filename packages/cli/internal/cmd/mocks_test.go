@@ -3,8 +3,15 @@ package cmd
 import (
 	"context"
 	"errors"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/keywaysh/cli/internal/agent"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/e2e"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/injector"
 )
 
 // MockGitClient is a mock implementation of GitClient
@@ -120,6 +127,7 @@ type MockFileSystem struct {
 	WriteError error
 	ReadError  error
 	Written    map[string][]byte
+	Perms      map[string]uint32 // Permissions of written files
 }
 
 func NewMockFileSystem() *MockFileSystem {
@@ -144,6 +152,10 @@ func (m *MockFileSystem) WriteFile(name string, data []byte, perm uint32) error
 		return m.WriteError
 	}
 	m.Written[name] = data
+	if m.Perms == nil {
+		m.Perms = make(map[string]uint32)
+	}
+	m.Perms[name] = perm
 	return nil
 }
 
@@ -153,9 +165,14 @@ type MockAPIClient struct {
 	VaultEnvsError                     error
 	PullResponse                       *api.PullSecretsResponse
 	PullError                          error
+	PullByEnv                          map[string]string // Per-environment content, takes precedence over PullResponse
+	PullByRef                          map[string]string // Content of other vaults by "owner/repo/env", for references
+	PullRefError                       error             // Returned for other vaults missing from PullByRef
 	PushResponse                       *api.PushSecretsResponse
 	PushError                          error
 	PushedSecrets                      map[string]string // Captures secrets sent in PushSecrets call
+	PushedEnv                          string            // Captures environment sent in PushSecrets call
+	PushedByEnv                        map[string]map[string]string // Captures secrets of every PushSecrets call by environment
 	InitResponse                       *api.InitVaultResponse
 	InitError                          error
 	VaultExists                        bool
@@ -166,6 +183,30 @@ type MockAPIClient struct {
 	ValidateTokenError                 error
 	CheckGitHubAppInstallationResponse *api.GitHubAppInstallationStatus
 	CheckGitHubAppInstallationError    error
+	SecretItems                        []api.SecretItem
+	SecretItemsError                   error
+	SecretVersions                     map[string][]api.SecretVersion // Keyed by secret ID
+	SecretVersionsError                error
+	RestoreVersionError                error
+	RestoredVersions                   []string // Captures "secretID@versionID" for each restore
+	TrashedSecrets                     []api.TrashedSecret
+	RestoreTrashError                  error
+	RestoredTrash                      []string // Captures secret IDs restored from trash
+	ActivityEvents                     []api.ActivityEvent // Newest first, filtered like the server
+	ActivityError                      error
+	ActivityFn                         func(filter api.ActivityFilter) ([]api.ActivityEvent, error) // Overrides ActivityEvents when set
+	ActivityFilters                    []api.ActivityFilter // Captures the filter of each ListVaultActivity call
+	PullIfChangedFn                    func(revision string) (*api.PullSecretsResponse, error) // Overrides PullSecretsIfChanged when set
+	Environments                       []api.Environment
+	EnvironmentsError                  error
+	EnvironmentMutationError           error    // Returned by Create/Rename/DeleteEnvironment
+	EnvironmentCalls                   []string // Captures "create:name", "rename:old:new", "delete:name"
+	VaultKeys                          *api.VaultKeys
+	VaultKeysError                     error
+	RegisteredPublicKey                string           // Captures the key sent in RegisterPublicKey
+	PutKeys                            []api.WrappedKey // Captures keys sent in PutVaultKeys
+	PutKeysEnable                      bool
+	PutKeysError                       error
 }
 
 func (m *MockAPIClient) StartDeviceLogin(ctx context.Context, repository string, repoIds *api.RepoIds) (*api.DeviceStartResponse, error) {
@@ -195,13 +236,126 @@ func (m *MockAPIClient) GetVaultDetails(ctx context.Context, repoFullName string
 func (m *MockAPIClient) GetVaultEnvironments(ctx context.Context, repoFullName string) ([]string, error) {
 	return m.VaultEnvs, m.VaultEnvsError
 }
+func (m *MockAPIClient) GetVaultKeys(ctx context.Context, repoFullName string) (*api.VaultKeys, error) {
+	if m.VaultKeys == nil && m.VaultKeysError == nil {
+		return &api.VaultKeys{}, nil
+	}
+	return m.VaultKeys, m.VaultKeysError
+}
+func (m *MockAPIClient) RegisterPublicKey(ctx context.Context, repoFullName, publicKey string) error {
+	m.RegisteredPublicKey = publicKey
+	return nil
+}
+func (m *MockAPIClient) PutVaultKeys(ctx context.Context, repoFullName string, keys []api.WrappedKey, enable bool) error {
+	m.PutKeys = append(m.PutKeys, keys...)
+	m.PutKeysEnable = m.PutKeysEnable || enable
+	return m.PutKeysError
+}
+func (m *MockAPIClient) ListEnvironments(ctx context.Context, repoFullName string) ([]api.Environment, error) {
+	return m.Environments, m.EnvironmentsError
+}
+func (m *MockAPIClient) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	if m.EnvironmentMutationError != nil {
+		return m.EnvironmentMutationError
+	}
+	m.EnvironmentCalls = append(m.EnvironmentCalls, "create:"+name)
+	return nil
+}
+func (m *MockAPIClient) RenameEnvironment(ctx context.Context, repoFullName, oldName, newName string) error {
+	if m.EnvironmentMutationError != nil {
+		return m.EnvironmentMutationError
+	}
+	m.EnvironmentCalls = append(m.EnvironmentCalls, "rename:"+oldName+":"+newName)
+	return nil
+}
+func (m *MockAPIClient) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	if m.EnvironmentMutationError != nil {
+		return m.EnvironmentMutationError
+	}
+	m.EnvironmentCalls = append(m.EnvironmentCalls, "delete:"+name)
+	return nil
+}
 func (m *MockAPIClient) PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
 	m.PushedSecrets = secrets
+	m.PushedEnv = env
+	if m.PushedByEnv == nil {
+		m.PushedByEnv = make(map[string]map[string]string)
+	}
+	m.PushedByEnv[env] = secrets
 	return m.PushResponse, m.PushError
 }
 func (m *MockAPIClient) PullSecrets(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+	if content, ok := m.PullByRef[repo+"/"+env]; ok {
+		return &api.PullSecretsResponse{Content: content}, nil
+	}
+	if m.PullRefError != nil && repo != "owner/repo" {
+		return nil, m.PullRefError
+	}
+	if m.PullByEnv != nil {
+		content, ok := m.PullByEnv[env]
+		if !ok {
+			return nil, &api.APIError{StatusCode: 404, Detail: "Environment not found"}
+		}
+		return &api.PullSecretsResponse{Content: content}, nil
+	}
 	return m.PullResponse, m.PullError
 }
+func (m *MockAPIClient) PullSecretsIfChanged(ctx context.Context, repo, env, revision string) (*api.PullSecretsResponse, error) {
+	if m.PullIfChangedFn != nil {
+		return m.PullIfChangedFn(revision)
+	}
+	resp, err := m.PullSecrets(ctx, repo, env)
+	if err == nil && resp != nil && revision != "" && resp.Revision == revision {
+		return nil, nil
+	}
+	return resp, err
+}
+func (m *MockAPIClient) ListSecrets(ctx context.Context, repoFullName string) ([]api.SecretItem, error) {
+	return m.SecretItems, m.SecretItemsError
+}
+func (m *MockAPIClient) GetSecretVersions(ctx context.Context, repoFullName, secretID string) ([]api.SecretVersion, error) {
+	return m.SecretVersions[secretID], m.SecretVersionsError
+}
+func (m *MockAPIClient) RestoreSecretVersion(ctx context.Context, repoFullName, secretID, versionID string) (*api.RestoreVersionResponse, error) {
+	if m.RestoreVersionError != nil {
+		return nil, m.RestoreVersionError
+	}
+	m.RestoredVersions = append(m.RestoredVersions, secretID+"@"+versionID)
+	return &api.RestoreVersionResponse{Message: "Restored"}, nil
+}
+func (m *MockAPIClient) ListTrashedSecrets(ctx context.Context, repoFullName string) ([]api.TrashedSecret, error) {
+	return m.TrashedSecrets, nil
+}
+func (m *MockAPIClient) RestoreTrashedSecret(ctx context.Context, repoFullName, secretID string) error {
+	if m.RestoreTrashError != nil {
+		return m.RestoreTrashError
+	}
+	m.RestoredTrash = append(m.RestoredTrash, secretID)
+	return nil
+}
+func (m *MockAPIClient) ListVaultActivity(ctx context.Context, repoFullName string, filter api.ActivityFilter) ([]api.ActivityEvent, error) {
+	m.ActivityFilters = append(m.ActivityFilters, filter)
+	if m.ActivityFn != nil {
+		return m.ActivityFn(filter)
+	}
+	if m.ActivityError != nil {
+		return nil, m.ActivityError
+	}
+	var events []api.ActivityEvent
+	for _, e := range m.ActivityEvents {
+		if !filter.Since.IsZero() && !e.Timestamp.After(filter.Since) {
+			continue
+		}
+		if filter.Environment != "" && e.Environment() != filter.Environment {
+			continue
+		}
+		if filter.Actor != "" && !strings.EqualFold(filter.Actor, e.Actor.Username) {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
 func (m *MockAPIClient) GetProviders(ctx context.Context) ([]api.Provider, error) {
 	return nil, nil
 }
@@ -268,12 +422,43 @@ type MockCommandRunner struct {
 	LastCommand   string
 	LastArgs      []string
 	LastSecrets   map[string]string
+	LastOptions   injector.Options
+	WatchUpdates  int                 // Updates RunCommandWatched waits for before returning
+	Updates       []map[string]string // Captures updates received by RunCommandWatched
+	Execed        bool                // Whether the command was started with Exec
+	LastProcesses []injector.Process  // Captures the processes of RunProcesses
 }
 
-func (m *MockCommandRunner) RunCommand(name string, args []string, secrets map[string]string) error {
+func (m *MockCommandRunner) RunCommand(name string, args []string, secrets map[string]string, opts injector.Options) error {
 	m.LastCommand = name
 	m.LastArgs = args
 	m.LastSecrets = secrets
+	m.LastOptions = opts
+	return m.RunError
+}
+
+func (m *MockCommandRunner) RunCommandWatched(name string, args []string, secrets map[string]string, opts injector.Options, updates <-chan map[string]string) error {
+	m.RunCommand(name, args, secrets, opts)
+	timeout := time.After(5 * time.Second)
+	for len(m.Updates) < m.WatchUpdates {
+		select {
+		case next := <-updates:
+			m.Updates = append(m.Updates, next)
+		case <-timeout:
+			return errors.New("timed out waiting for updates")
+		}
+	}
+	return m.RunError
+}
+
+func (m *MockCommandRunner) Exec(name string, args []string, secrets map[string]string, opts injector.Options) error {
+	m.Execed = true
+	return m.RunCommand(name, args, secrets, opts)
+}
+
+func (m *MockCommandRunner) RunProcesses(procs []injector.Process, opts injector.Options) error {
+	m.LastProcesses = procs
+	m.LastOptions = opts
 	return m.RunError
 }
 
@@ -365,6 +550,160 @@ func (m *MockFileStat) Stat(name string) (FileInfo, error) {
 	return nil, errors.New("file not found")
 }
 
+// MockBaselineStore is a mock implementation of BaselineStore
+type MockBaselineStore struct {
+	Baselines map[string]*env.Baseline
+	LoadError error
+	SaveError error
+}
+
+func NewMockBaselineStore() *MockBaselineStore {
+	return &MockBaselineStore{
+		Baselines: make(map[string]*env.Baseline),
+	}
+}
+
+func baselineKey(repo, envName, file string) string {
+	return repo + "|" + envName + "|" + file
+}
+
+func (m *MockBaselineStore) Load(repo, envName, file string) (*env.Baseline, error) {
+	if m.LoadError != nil {
+		return nil, m.LoadError
+	}
+	return m.Baselines[baselineKey(repo, envName, file)], nil
+}
+
+func (m *MockBaselineStore) Save(repo, envName, file string, b *env.Baseline) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	m.Baselines[baselineKey(repo, envName, file)] = b
+	return nil
+}
+
+// MockIdentityStore is a mock implementation of IdentityStore
+type MockIdentityStore struct {
+	Identity *e2e.Identity
+	Error    error
+	Recorded map[string]bool // repos recorded as end-to-end encrypted
+}
+
+func (m *MockIdentityStore) Load() (*e2e.Identity, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+	if m.Identity == nil {
+		return nil, os.ErrNotExist
+	}
+	return m.Identity, nil
+}
+
+func (m *MockIdentityStore) LoadOrCreate() (*e2e.Identity, bool, error) {
+	if m.Error != nil {
+		return nil, false, m.Error
+	}
+	if m.Identity != nil {
+		return m.Identity, false, nil
+	}
+	id, err := e2e.NewIdentity()
+	if err != nil {
+		return nil, false, err
+	}
+	m.Identity = id
+	return id, true, nil
+}
+
+func (m *MockIdentityStore) IsRecorded(repo string) (bool, error) {
+	return m.Recorded[repo], nil
+}
+
+func (m *MockIdentityStore) Record(repo string) error {
+	if m.Recorded == nil {
+		m.Recorded = make(map[string]bool)
+	}
+	m.Recorded[repo] = true
+	return nil
+}
+
+// MockAgent is a mock implementation of AgentClient
+type MockAgent struct {
+	Running      bool
+	Contents     map[string]string // "repo:env" -> env file content served by the agent
+	StatusResult *agent.Status
+	StatusError  error
+	LockError    error
+	Locked       bool
+	EvictError   error
+	Evicted      []string // "repo:env" of each eviction, "repo:" for a whole repository
+	StartPID     int
+	StartError   error
+	StartedTTL   time.Duration
+}
+
+func (m *MockAgent) Available() bool { return m.Running }
+
+func (m *MockAgent) Status(ctx context.Context) (*agent.Status, error) {
+	if m.StatusError != nil {
+		return nil, m.StatusError
+	}
+	if m.StatusResult == nil {
+		return &agent.Status{Locked: true}, nil
+	}
+	return m.StatusResult, nil
+}
+
+func (m *MockAgent) Lock(ctx context.Context) error {
+	if m.LockError != nil {
+		return m.LockError
+	}
+	m.Locked = true
+	m.Running = false
+	return nil
+}
+
+func (m *MockAgent) Evict(ctx context.Context, repo, env string) error {
+	if !m.Running {
+		return agent.ErrNotRunning
+	}
+	if m.EvictError != nil {
+		return m.EvictError
+	}
+	m.Evicted = append(m.Evicted, repo+":"+env)
+	delete(m.Contents, repo+":"+env)
+	return nil
+}
+
+func (m *MockAgent) Start(ttl time.Duration) (int, error) {
+	m.StartedTTL = ttl
+	if m.StartError != nil {
+		return 0, m.StartError
+	}
+	m.Running = true
+	return m.StartPID, nil
+}
+
+func (m *MockAgent) WrapAPI(client api.APIClient) AgentAPIClient {
+	return &mockAgentAPIClient{APIClient: client, agent: m}
+}
+
+// mockAgentAPIClient serves PullSecrets from MockAgent.Contents when it has them
+type mockAgentAPIClient struct {
+	api.APIClient
+	agent  *MockAgent
+	served int
+}
+
+func (c *mockAgentAPIClient) PullSecrets(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+	if content, ok := c.agent.Contents[repo+":"+env]; ok {
+		c.served++
+		return &api.PullSecretsResponse{Content: content}, nil
+	}
+	return c.APIClient.PullSecrets(ctx, repo, env)
+}
+
+func (c *mockAgentAPIClient) Served() int { return c.served }
+
 // NewTestDeps creates a Dependencies with all mocks for testing
 func NewTestDeps() (*Dependencies, *MockGitClient, *MockAuthProvider, *MockUIProvider, *MockFileSystem, *MockAPIClient) {
 	git := &MockGitClient{
@@ -398,6 +737,9 @@ func NewTestDeps() (*Dependencies, *MockGitClient, *MockAuthProvider, *MockUIPro
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Baselines:  NewMockBaselineStore(),
+		Identity:   &MockIdentityStore{},
+		Agent:      &MockAgent{},
 	}
 
 	return deps, git, auth, ui, fs, apiClient
@@ -436,6 +778,9 @@ func NewTestDepsWithEnv() (*Dependencies, *MockGitClient, *MockAuthProvider, *Mo
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Baselines:  NewMockBaselineStore(),
+		Identity:   &MockIdentityStore{},
+		Agent:      &MockAgent{},
 	}
 
 	return deps, git, auth, ui, fs, envHelper, apiClient
@@ -474,6 +819,9 @@ func NewTestDepsWithRunner() (*Dependencies, *MockGitClient, *MockAuthProvider,
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Baselines:  NewMockBaselineStore(),
+		Identity:   &MockIdentityStore{},
+		Agent:      &MockAgent{},
 	}
 
 	return deps, git, auth, ui, cmdRunner, apiClient
@@ -522,6 +870,9 @@ func NewTestDepsForDoctor() (*Dependencies, *MockGitClient, *MockUIProvider, *Mo
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Baselines:  NewMockBaselineStore(),
+		Identity:   &MockIdentityStore{},
+		Agent:      &MockAgent{},
 	}
 
 	return deps, git, ui, stat, authStore, httpClient, apiClient
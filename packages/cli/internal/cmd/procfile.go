@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/injector"
+	"github.com/spf13/pflag"
+)
+
+// procfileProcess is a line of a Procfile: a named command, with the
+// environments and key filter it gets
+type procfileProcess struct {
+	Name    string
+	Command string
+	Envs    []string
+	Filter  keyFilter
+}
+
+// procfileLine matches "name: command"
+var procfileLine = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.*)$`)
+
+// parseProcfile reads the processes of a Procfile. A command may start with
+// keyway run options, ended by --, that replace those of the run for that
+// process:
+//
+//	web: npm run dev
+//	worker: -e staging --only 'REDIS_*' -- node worker.js
+func parseProcfile(data []byte, envs []string, filter keyFilter) ([]procfileProcess, error) {
+	var procs []procfileProcess
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := procfileLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected name: command", n)
+		}
+		if seen[m[1]] {
+			return nil, fmt.Errorf("line %d: %s is defined twice", n, m[1])
+		}
+		seen[m[1]] = true
+
+		p := procfileProcess{Name: m[1], Envs: envs, Filter: filter}
+		options, command, err := splitProcOptions(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if command == "" {
+			return nil, fmt.Errorf("line %d: %s has no command", n, p.Name)
+		}
+		p.Command = command
+		if len(options) > 0 {
+			fs := pflag.NewFlagSet(p.Name, pflag.ContinueOnError)
+			fs.SetOutput(&bytes.Buffer{})
+			envNames := fs.StringArrayP("env", "e", envs, "")
+			fs.StringSliceVar(&p.Filter.Only, "only", filter.Only, "")
+			fs.StringSliceVar(&p.Filter.Exclude, "exclude", filter.Exclude, "")
+			fs.StringVar(&p.Filter.StripPrefix, "strip-prefix", filter.StripPrefix, "")
+			fs.StringVar(&p.Filter.Prefix, "prefix", filter.Prefix, "")
+			if err := fs.Parse(options); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			if fs.NArg() > 0 {
+				return nil, fmt.Errorf("line %d: unexpected %q before --", n, fs.Arg(0))
+			}
+			p.Envs = *envNames
+		}
+		if err := p.Filter.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		procs = append(procs, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(procs) == 0 {
+		return nil, fmt.Errorf("no processes defined")
+	}
+	return procs, nil
+}
+
+// splitProcOptions splits the keyway options off the front of a Procfile
+// command, up to --. Options are split into words like a shell would; the
+// command is left as is, for the shell to run.
+func splitProcOptions(line string) (options []string, command string, err error) {
+	if !strings.HasPrefix(line, "-") {
+		return nil, line, nil
+	}
+	var word strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			} else if ch == '\\' && quote == '"' && i+1 < len(line) {
+				i++
+				word.WriteByte(line[i])
+			} else {
+				word.WriteByte(ch)
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			inWord = true
+		case ch == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		case ch == ' ' || ch == '\t':
+			if !inWord {
+				continue
+			}
+			if word.String() == "--" {
+				return options, strings.TrimSpace(line[i:]), nil
+			}
+			options = append(options, word.String())
+			word.Reset()
+			inWord = false
+		default:
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, "", fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord && word.String() == "--" {
+		return options, "", nil
+	}
+	return nil, "", fmt.Errorf("keyway options must be followed by -- and the command")
+}
+
+// shellCommand returns how to run a Procfile command through the shell, so
+// that it can use variables, pipes and the like
+func shellCommand(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
+// runProcfileWithDeps runs the processes of opts.Procfile side by side, each
+// with the secrets of its own environments and filter
+func runProcfileWithDeps(opts RunOptions, deps *Dependencies) error {
+	data, err := deps.FS.ReadFile(opts.Procfile)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to read %s: %v", opts.Procfile, err))
+		return err
+	}
+	filter := keyFilter{Only: opts.Only, Exclude: opts.Exclude, StripPrefix: opts.StripPrefix, Prefix: opts.Prefix}
+	defs, err := parseProcfile(data, append(append([]string{}, opts.BaseEnvs...), opts.EnvName), filter)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("%s: %v", opts.Procfile, err))
+		return err
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	var client api.APIClient = deps.APIFactory.NewClient(token)
+	if !opts.NoAgent && deps.Agent != nil && deps.Agent.Available() {
+		client = deps.Agent.WrapAPI(client)
+	}
+	ctx := context.Background()
+
+	// Processes sharing environments share a fetch
+	layered := map[string]map[string]string{}
+	fetch := func() error {
+		return deps.UI.Spin("Fetching secrets...", func() error {
+			for _, d := range defs {
+				key := strings.Join(d.Envs, "\x00")
+				if _, ok := layered[key]; ok {
+					continue
+				}
+				secrets, _, err := fetchEnvLayers(ctx, client, repo, d.Envs)
+				if isAuthError(err) {
+					return err
+				}
+				if err != nil {
+					return fmt.Errorf("%s: %w", strings.Join(d.Envs, " → "), err)
+				}
+				layered[key] = secrets
+			}
+			return nil
+		})
+	}
+
+	err = fetch()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		if !opts.NoAgent && deps.Agent != nil && deps.Agent.Available() {
+			client = deps.Agent.WrapAPI(client)
+		}
+		err = fetch()
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	procs := make([]injector.Process, 0, len(defs))
+	for _, d := range defs {
+		secrets := d.Filter.apply(layered[strings.Join(d.Envs, "\x00")])
+		deps.UI.Step(fmt.Sprintf("%s: %s, %d secrets", deps.UI.Value(d.Name), strings.Join(d.Envs, " → "), len(secrets)))
+		command, args := shellCommand(d.Command)
+		procs = append(procs, injector.Process{Name: d.Name, Command: command, Args: args, Secrets: secrets})
+	}
+	deps.UI.Success(fmt.Sprintf("Starting %d processes from %s", len(procs), opts.Procfile))
+
+	return deps.CmdRunner.RunProcesses(procs, injector.Options{
+		NoOverride:  opts.NoOverride,
+		CleanEnv:    opts.CleanEnv,
+		Keep:        opts.Keep,
+		MaskOutput:  opts.MaskOutput,
+		GracePeriod: opts.GracePeriod,
+	})
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestParseProcfile(t *testing.T) {
+	data := []byte(`# local stack
+web: npm run dev
+
+worker: -e base -e staging --only 'REDIS_*' --only "DB_*" -- node worker.js --queue default
+scheduler: --strip-prefix CRON_ -- node "scheduler.js"
+`)
+	procs, err := parseProcfile(data, []string{"development"}, keyFilter{Exclude: []string{"STRIPE_*"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []procfileProcess{
+		{Name: "web", Command: "npm run dev", Envs: []string{"development"}, Filter: keyFilter{Exclude: []string{"STRIPE_*"}}},
+		{Name: "worker", Command: "node worker.js --queue default", Envs: []string{"base", "staging"}, Filter: keyFilter{Only: []string{"REDIS_*", "DB_*"}, Exclude: []string{"STRIPE_*"}}},
+		{Name: "scheduler", Command: `node "scheduler.js"`, Envs: []string{"development"}, Filter: keyFilter{Exclude: []string{"STRIPE_*"}, StripPrefix: "CRON_"}},
+	}
+	if !reflect.DeepEqual(procs, want) {
+		t.Errorf("got %+v\nwant %+v", procs, want)
+	}
+}
+
+func TestParseProcfile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", "# nothing\n"},
+		{"no name", "npm run dev\n"},
+		{"duplicate", "web: a\nweb: b\n"},
+		{"no command", "web:\n"},
+		{"missing --", "web: -e staging node app.js\n"},
+		{"unknown option", "web: --watch -- node app.js\n"},
+		{"unterminated quote", "web: --only 'DB_* -- node app.js\n"},
+		{"bad glob", "web: --only '[' -- node app.js\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseProcfile([]byte(tt.data), []string{"development"}, keyFilter{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRunRunWithDeps_Procfile(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	deps.FS.(*MockFileSystem).Files["Procfile.dev"] = []byte("web: npm run dev\nworker: -e staging --only 'REDIS_*' -- node worker.js\n")
+	apiMock.PullByEnv = map[string]string{
+		"development": "API_KEY=dev\nREDIS_URL=redis://dev",
+		"staging":     "API_KEY=staging\nREDIS_URL=redis://staging",
+	}
+
+	opts := RunOptions{EnvName: "development", Procfile: "Procfile.dev", MaskOutput: true}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(cmdRunner.LastProcesses) != 2 {
+		t.Fatalf("expected 2 processes, got %+v", cmdRunner.LastProcesses)
+	}
+	web, worker := cmdRunner.LastProcesses[0], cmdRunner.LastProcesses[1]
+	if web.Name != "web" || web.Args[len(web.Args)-1] != "npm run dev" {
+		t.Errorf("unexpected web process %+v", web)
+	}
+	if !reflect.DeepEqual(web.Secrets, map[string]string{"API_KEY": "dev", "REDIS_URL": "redis://dev"}) {
+		t.Errorf("unexpected web secrets %v", web.Secrets)
+	}
+	if !reflect.DeepEqual(worker.Secrets, map[string]string{"REDIS_URL": "redis://staging"}) {
+		t.Errorf("unexpected worker secrets %v", worker.Secrets)
+	}
+	if !cmdRunner.LastOptions.MaskOutput {
+		t.Error("expected --mask-output to apply to the processes")
+	}
+}
+
+func TestRunRunWithDeps_ProcfileConflicts(t *testing.T) {
+	deps, _, _, _, cmdRunner, _ := NewTestDepsWithRunner()
+	deps.FS.(*MockFileSystem).Files["Procfile"] = []byte("web: npm run dev\n")
+
+	opts := RunOptions{EnvName: "development", Procfile: "Procfile", Watch: true}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected --procfile and --watch to be rejected")
+	}
+	if cmdRunner.LastProcesses != nil {
+		t.Error("expected nothing to run")
+	}
+}
+
+func TestRunRunWithDeps_ProcfileAPIError(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	deps.FS.(*MockFileSystem).Files["Procfile"] = []byte("web: npm run dev\n")
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Vault not found"}
+
+	if err := runRunWithDeps(RunOptions{EnvName: "development", Procfile: "Procfile"}, deps); err == nil {
+		t.Fatal("expected an error")
+	}
+	if cmdRunner.LastProcesses != nil || len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected an error and nothing to run")
+	}
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote <from> <to>",
+	Short: "Copy secrets from one environment to another",
+	Long: `Copy secrets from one environment to another, e.g. staging to production.
+
+Shows a preview of what will be added or updated in the target environment
+before pushing. Secrets that only exist in the target environment are kept.
+
+Keys can be selected with --keys and excluded with --exclude. Both accept
+exact names or glob patterns (e.g. "STRIPE_*").
+
+Examples:
+  keyway promote staging production
+  keyway promote staging production --keys API_URL,SENTRY_DSN
+  keyway promote staging production --keys 'STRIPE_*' --exclude STRIPE_WEBHOOK_SECRET
+  keyway promote stg prod --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPromote,
+}
+
+func init() {
+	promoteCmd.Flags().StringSliceP("keys", "k", nil, "Keys or glob patterns to promote (default: all)")
+	promoteCmd.Flags().StringSliceP("exclude", "x", nil, "Keys or glob patterns to skip")
+	promoteCmd.Flags().Bool("dry-run", false, "Show what would be promoted without pushing")
+	promoteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+}
+
+// PromoteOptions contains the parsed flags for the promote command
+type PromoteOptions struct {
+	From    string
+	To      string
+	Keys    []string
+	Exclude []string
+	DryRun  bool
+	Yes     bool
+}
+
+// runPromote is the entry point for the promote command (uses default dependencies)
+func runPromote(cmd *cobra.Command, args []string) error {
+	opts := PromoteOptions{
+		From: args[0],
+		To:   args[1],
+	}
+	opts.Keys, _ = cmd.Flags().GetStringSlice("keys")
+	opts.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+
+	return runPromoteWithDeps(opts, defaultDeps)
+}
+
+// runPromoteWithDeps is the testable version of runPromote
+func runPromoteWithDeps(opts PromoteOptions, deps *Dependencies) error {
+	deps.UI.Intro("promote")
+
+	from := normalizeEnvName(opts.From)
+	to := normalizeEnvName(opts.To)
+	if from == "" || to == "" {
+		deps.UI.Error("Source and target environments are required")
+		return fmt.Errorf("missing environments")
+	}
+	if from == to {
+		deps.UI.Error("Cannot promote an environment to itself")
+		return fmt.Errorf("same environment")
+	}
+
+	for _, pattern := range append(append([]string{}, opts.Keys...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			deps.UI.Error(fmt.Sprintf("Invalid key pattern: %s", pattern))
+			return fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	deps.UI.Step(fmt.Sprintf("Promote: %s → %s", deps.UI.Value(from), deps.UI.Value(to)))
+
+	var source, target map[string]string
+	fetch := func() error {
+		return deps.UI.Spin(fmt.Sprintf("Fetching %s and %s...", from, to), func() error {
+			resp, err := client.PullSecrets(ctx, repo, from)
+			if err != nil {
+				return err
+			}
+			source = env.Parse(resp.Content)
+
+			resp, err = client.PullSecrets(ctx, repo, to)
+			if err != nil {
+				// Promoting into a new environment is fine
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+					target = make(map[string]string)
+					return nil
+				}
+				return err
+			}
+			target = env.Parse(resp.Content)
+			return nil
+		})
+	}
+
+	err = fetch()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to fetch secrets: %s", err.Error()))
+		return err
+	}
+
+	if len(source) == 0 {
+		deps.UI.Error(fmt.Sprintf("Environment '%s' has no secrets to promote", from))
+		return fmt.Errorf("source environment is empty")
+	}
+
+	selected := selectPromotedSecrets(source, opts.Keys, opts.Exclude)
+	if len(selected) == 0 {
+		deps.UI.Warn("No secrets match the selected keys")
+		return nil
+	}
+
+	// Keys only in the target end up in OnlyInEnv2 and are left untouched
+	result := compareSecrets(from, to, selected, target, false)
+
+	if result.Stats.OnlyInEnv1 == 0 && result.Stats.Different == 0 {
+		deps.UI.Success(fmt.Sprintf("%s is already up to date with %s", to, from))
+		return nil
+	}
+
+	deps.UI.Message("")
+	deps.UI.Message(fmt.Sprintf("Will be promoted to %s:", deps.UI.Bold(to)))
+	for _, key := range result.OnlyInEnv1 {
+		deps.UI.DiffAdded(key)
+	}
+	for _, entry := range result.Different {
+		deps.UI.DiffChanged(entry.Key)
+	}
+	if len(result.Same) > 0 {
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("%d selected secret(s) already identical", len(result.Same))))
+	}
+	deps.UI.Message("")
+
+	if opts.DryRun {
+		deps.UI.Info("Dry run: no changes pushed")
+		return nil
+	}
+
+	changed := result.Stats.OnlyInEnv1 + result.Stats.Different
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Promote %d secret(s) from %s to %s?", changed, from, to), false)
+		if !confirm {
+			deps.UI.Warn("Promote aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	// Merge: keep everything already in the target, overlay the promoted keys
+	merged := make(map[string]string, len(target)+len(selected))
+	for k, v := range target {
+		merged[k] = v
+	}
+	for k, v := range selected {
+		merged[k] = v
+	}
+
+	err = deps.UI.Spin(fmt.Sprintf("Pushing to %s...", to), func() error {
+		_, pushErr := client.PushSecrets(ctx, repo, to, merged)
+		return pushErr
+	})
+	if err != nil {
+		analytics.Track(analytics.EventError, map[string]interface{}{
+			"command": "promote",
+			"error":   err.Error(),
+		})
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+			if apiErr.UpgradeURL != "" {
+				deps.UI.Message(fmt.Sprintf("Upgrade: %s", deps.UI.Link(apiErr.UpgradeURL)))
+			}
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+	evictAgentCache(deps, repo, to)
+
+	// Track promote event
+	analytics.Track(analytics.EventPromote, map[string]interface{}{
+		"repoFullName": repo,
+		"from":         from,
+		"to":           to,
+		"added":        result.Stats.OnlyInEnv1,
+		"updated":      result.Stats.Different,
+	})
+
+	deps.UI.Success(fmt.Sprintf("Promoted %d secret(s) from %s to %s", changed, from, to))
+
+	dashboardURL := fmt.Sprintf("%s/vaults/%s", config.GetDashboardURL(), repo)
+	deps.UI.Outro(fmt.Sprintf("Dashboard: %s", deps.UI.Link(dashboardURL)))
+	return nil
+}
+
+// selectPromotedSecrets returns the subset of secrets matching the include
+// patterns (all keys when empty) and none of the exclude patterns.
+func selectPromotedSecrets(secrets map[string]string, include, exclude []string) map[string]string {
+	selected := make(map[string]string)
+	for k, v := range secrets {
+		if len(include) > 0 && !matchesAnyKeyPattern(k, include) {
+			continue
+		}
+		if matchesAnyKeyPattern(k, exclude) {
+			continue
+		}
+		selected[k] = v
+	}
+	return selected
+}
+
+// matchesAnyKeyPattern reports whether key equals or glob-matches any pattern
+func matchesAnyKeyPattern(key string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if p == key {
+			return true
+		}
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
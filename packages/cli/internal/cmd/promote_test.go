@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestSelectPromotedSecrets(t *testing.T) {
+	secrets := map[string]string{
+		"API_URL":               "https://api",
+		"STRIPE_KEY":            "sk_live",
+		"STRIPE_WEBHOOK_SECRET": "whsec",
+		"SENTRY_DSN":            "https://sentry",
+	}
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{"all", nil, nil, []string{"API_URL", "STRIPE_KEY", "STRIPE_WEBHOOK_SECRET", "SENTRY_DSN"}},
+		{"exact keys", []string{"API_URL", "SENTRY_DSN"}, nil, []string{"API_URL", "SENTRY_DSN"}},
+		{"glob", []string{"STRIPE_*"}, nil, []string{"STRIPE_KEY", "STRIPE_WEBHOOK_SECRET"}},
+		{"glob with exclude", []string{"STRIPE_*"}, []string{"STRIPE_WEBHOOK_SECRET"}, []string{"STRIPE_KEY"}},
+		{"exclude only", nil, []string{"S*"}, []string{"API_URL"}},
+		{"no match", []string{"MISSING"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := selectPromotedSecrets(secrets, tt.include, tt.exclude)
+			if len(selected) != len(tt.expected) {
+				t.Fatalf("expected %d keys, got %d: %v", len(tt.expected), len(selected), selected)
+			}
+			for _, k := range tt.expected {
+				if selected[k] != secrets[k] {
+					t.Errorf("expected %s to be selected", k)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPromoteWithDeps_Success(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "API_URL=https://staging\nNEW_FLAG=on\nSAME=1\n",
+		"production": "API_URL=https://prod\nSAME=1\nPROD_ONLY=keep\n",
+	}
+	apiMock.PushResponse = &api.PushSecretsResponse{Success: true}
+
+	err := runPromoteWithDeps(PromoteOptions{From: "stg", To: "prod", Yes: true}, deps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.PushedEnv != "production" {
+		t.Errorf("expected push to production, got %q", apiMock.PushedEnv)
+	}
+	expected := map[string]string{
+		"API_URL":   "https://staging",
+		"NEW_FLAG":  "on",
+		"SAME":      "1",
+		"PROD_ONLY": "keep",
+	}
+	if len(apiMock.PushedSecrets) != len(expected) {
+		t.Fatalf("expected %d pushed secrets, got %v", len(expected), apiMock.PushedSecrets)
+	}
+	for k, v := range expected {
+		if apiMock.PushedSecrets[k] != v {
+			t.Errorf("expected %s=%s, got %s", k, v, apiMock.PushedSecrets[k])
+		}
+	}
+
+	if len(uiMock.DiffAddedCalls) != 1 || uiMock.DiffAddedCalls[0] != "NEW_FLAG" {
+		t.Errorf("expected NEW_FLAG added, got %v", uiMock.DiffAddedCalls)
+	}
+	if len(uiMock.DiffChangedCalls) != 1 || uiMock.DiffChangedCalls[0] != "API_URL" {
+		t.Errorf("expected API_URL changed, got %v", uiMock.DiffChangedCalls)
+	}
+}
+
+func TestRunPromoteWithDeps_KeySelection(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "STRIPE_KEY=sk_test\nSTRIPE_WEBHOOK_SECRET=whsec_test\nAPI_URL=https://staging\n",
+		"production": "API_URL=https://prod\n",
+	}
+	apiMock.PushResponse = &api.PushSecretsResponse{Success: true}
+
+	opts := PromoteOptions{
+		From:    "staging",
+		To:      "production",
+		Keys:    []string{"STRIPE_*"},
+		Exclude: []string{"STRIPE_WEBHOOK_SECRET"},
+		Yes:     true,
+	}
+	if err := runPromoteWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.PushedSecrets["STRIPE_KEY"] != "sk_test" {
+		t.Errorf("expected STRIPE_KEY to be promoted, got %v", apiMock.PushedSecrets)
+	}
+	if _, ok := apiMock.PushedSecrets["STRIPE_WEBHOOK_SECRET"]; ok {
+		t.Error("expected STRIPE_WEBHOOK_SECRET to be excluded")
+	}
+	if apiMock.PushedSecrets["API_URL"] != "https://prod" {
+		t.Errorf("expected API_URL to keep production value, got %s", apiMock.PushedSecrets["API_URL"])
+	}
+}
+
+func TestRunPromoteWithDeps_NewTargetEnvironment(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging": "A=1\n",
+	}
+	apiMock.PushResponse = &api.PushSecretsResponse{Success: true}
+
+	if err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "preview", Yes: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedEnv != "preview" || apiMock.PushedSecrets["A"] != "1" {
+		t.Errorf("expected A=1 pushed to preview, got %s %v", apiMock.PushedEnv, apiMock.PushedSecrets)
+	}
+}
+
+func TestRunPromoteWithDeps_DryRun(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "A=1\n",
+		"production": "A=2\n",
+	}
+
+	if err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production", DryRun: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected no push in dry-run mode")
+	}
+	if len(uiMock.DiffChangedCalls) != 1 {
+		t.Errorf("expected preview to be shown, got %v", uiMock.DiffChangedCalls)
+	}
+}
+
+func TestRunPromoteWithDeps_AlreadyUpToDate(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "A=1\n",
+		"production": "A=1\nB=2\n",
+	}
+
+	if err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production", Yes: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected no push when nothing changed")
+	}
+	if len(uiMock.SuccessCalls) != 1 {
+		t.Errorf("expected up-to-date message, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunPromoteWithDeps_SameEnvironment(t *testing.T) {
+	deps, _, _, uiMock, _, _, _ := NewTestDepsWithEnv()
+
+	err := runPromoteWithDeps(PromoteOptions{From: "prod", To: "production"}, deps)
+	if err == nil || err.Error() != "same environment" {
+		t.Fatalf("expected same environment error, got %v", err)
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunPromoteWithDeps_InvalidPattern(t *testing.T) {
+	deps, _, _, _, _, _, _ := NewTestDepsWithEnv()
+
+	err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production", Keys: []string{"["}}, deps)
+	if err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+}
+
+func TestRunPromoteWithDeps_EmptySource(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "",
+		"production": "A=1\n",
+	}
+
+	err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production", Yes: true}, deps)
+	if err == nil {
+		t.Fatal("expected error for empty source")
+	}
+}
+
+func TestRunPromoteWithDeps_RequiresConfirmationNonInteractive(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "A=1\n",
+		"production": "",
+	}
+
+	err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production"}, deps)
+	if err == nil {
+		t.Fatal("expected confirmation error")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected no push without confirmation")
+	}
+}
+
+func TestRunPromoteWithDeps_InteractiveDecline(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	uiMock.Interactive = true
+	uiMock.ConfirmResult = false
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "A=1\n",
+		"production": "",
+	}
+
+	if err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected no push after declining")
+	}
+}
+
+func TestRunPromoteWithDeps_PushError(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullByEnv = map[string]string{
+		"staging":    "A=1\n",
+		"production": "",
+	}
+	apiMock.PushError = errors.New("network error")
+
+	err := runPromoteWithDeps(PromoteOptions{From: "staging", To: "production", Yes: true}, deps)
+	if err == nil {
+		t.Fatal("expected push error")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
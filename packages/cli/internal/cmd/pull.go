@@ -3,7 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/keywaysh/cli/internal/analytics"
 	"github.com/keywaysh/cli/internal/api"
@@ -18,11 +22,16 @@ var pullCmd = &cobra.Command{
 	RunE:  runPull,
 }
 
+// pullWatchInterval is how often --watch checks the vault for changes
+const pullWatchInterval = 10 * time.Second
+
 func init() {
-	pullCmd.Flags().StringP("env", "e", "development", "Environment name")
+	pullCmd.Flags().StringArrayP("env", "e", []string{"development"}, "Environment name (repeat to layer environments, later ones win)")
 	pullCmd.Flags().StringP("file", "f", ".env", "Env file to write to")
 	pullCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	pullCmd.Flags().Bool("force", false, "Replace entire file instead of merging")
+	pullCmd.Flags().BoolP("watch", "w", false, "Keep the file in sync with the vault until interrupted")
+	pullCmd.Flags().Duration("interval", pullWatchInterval, "How often --watch checks the vault")
 }
 
 // PullOptions contains the parsed flags for the pull command
@@ -31,7 +40,16 @@ type PullOptions struct {
 	File       string
 	Yes        bool
 	Force      bool
+	Watch      bool
 	EnvFlagSet bool
+
+	// BaseEnvs are layered under EnvName, in order
+	BaseEnvs []string
+
+	// Context stops --watch when cancelled (defaults to context.Background)
+	Context context.Context
+	// Interval overrides pullWatchInterval
+	Interval time.Duration
 }
 
 // runPull is the entry point for the pull command (uses default dependencies)
@@ -39,10 +57,17 @@ func runPull(cmd *cobra.Command, args []string) error {
 	opts := PullOptions{
 		EnvFlagSet: cmd.Flags().Changed("env"),
 	}
-	opts.EnvName, _ = cmd.Flags().GetString("env")
+	envNames, _ := cmd.Flags().GetStringArray("env")
+	opts.BaseEnvs, opts.EnvName = envNamesFlag(envNames)
 	opts.File, _ = cmd.Flags().GetString("file")
 	opts.Yes, _ = cmd.Flags().GetBool("yes")
 	opts.Force, _ = cmd.Flags().GetBool("force")
+	opts.Watch, _ = cmd.Flags().GetBool("watch")
+	opts.Interval, _ = cmd.Flags().GetDuration("interval")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	opts.Context = ctx
 
 	return runPullWithDeps(opts, defaultDeps)
 }
@@ -51,6 +76,16 @@ func runPull(cmd *cobra.Command, args []string) error {
 func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 	deps.UI.Intro("pull")
 
+	if strings.HasSuffix(opts.File, env.SealedSuffix) {
+		deps.UI.Error(fmt.Sprintf("%s is a sealed file - pull into a plain env file, then run keyway seal", opts.File))
+		return fmt.Errorf("cannot pull into a sealed file")
+	}
+	layered := len(opts.BaseEnvs) > 0
+	if layered && opts.Watch {
+		deps.UI.Error("--watch works with a single environment")
+		return fmt.Errorf("--watch cannot be combined with layered environments")
+	}
+
 	// Check gitignore
 	if !deps.Git.CheckEnvGitignore() {
 		deps.UI.Warn(".env files are not in .gitignore - secrets may be committed")
@@ -111,7 +146,11 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		envName = selected
 	}
 
-	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+	if layered {
+		deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(strings.Join(append(append([]string{}, opts.BaseEnvs...), envName), " → "))))
+	} else {
+		deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+	}
 
 	// Track pull event
 	analytics.Track(analytics.EventPull, map[string]interface{}{
@@ -119,13 +158,14 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		"environment":  envName,
 	})
 
-	var vaultContent string
+	var vaultContent, vaultRevision string
 	err = deps.UI.Spin("Downloading secrets...", func() error {
 		resp, err := client.PullSecrets(ctx, repo, envName)
 		if err != nil {
 			return err
 		}
 		vaultContent = resp.Content
+		vaultRevision = resp.Revision
 		return nil
 	})
 
@@ -144,6 +184,7 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 					return pullErr
 				}
 				vaultContent = resp.Content
+				vaultRevision = resp.Revision
 				return nil
 			})
 		}
@@ -164,6 +205,50 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		}
 	}
 
+	// Resolve keyway:// references against the vaults they point at
+	rawSecrets := env.Parse(vaultContent)
+	vaultSecrets, refKeys, err := resolveSecretRefs(ctx, client, repo, envName, rawSecrets)
+	if err != nil {
+		analytics.Track(analytics.EventError, map[string]interface{}{
+			"command": "pull",
+			"error":   err.Error(),
+		})
+		deps.UI.Error(fmt.Sprintf("Failed to resolve references: %s", err.Error()))
+		return err
+	}
+	if len(refKeys) > 0 {
+		vaultContent = withResolvedRefs(vaultContent, vaultSecrets, refKeys)
+		deps.UI.Step(fmt.Sprintf("Resolved %s reference(s)", deps.UI.Value(len(refKeys))))
+	}
+
+	// Layer the base environments under the main one
+	mainSecrets := vaultSecrets
+	var baseOnly map[string]string
+	if layered {
+		var base map[string]string
+		err := deps.UI.Spin("Downloading base environments...", func() error {
+			var err error
+			base, _, err = fetchEnvLayers(ctx, client, repo, opts.BaseEnvs)
+			return err
+		})
+		if err != nil {
+			analytics.Track(analytics.EventError, map[string]interface{}{
+				"command": "pull",
+				"error":   err.Error(),
+			})
+			deps.UI.Error(err.Error())
+			return err
+		}
+		vaultSecrets = env.Layer(base, vaultSecrets)
+		vaultContent = env.Format(vaultSecrets)
+		baseOnly = make(map[string]string)
+		for k, v := range base {
+			if _, ok := mainSecrets[k]; !ok {
+				baseOnly[k] = v
+			}
+		}
+	}
+
 	// Tip about keyway run (Zero-Trust)
 	if deps.UI.IsInteractive() {
 		deps.UI.Message("")
@@ -172,7 +257,6 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		deps.UI.Message("")
 	}
 
-	vaultSecrets := env.Parse(vaultContent)
 	envFilePath := filepath.Join(".", opts.File)
 
 	// Read existing local file if it exists
@@ -255,6 +339,23 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		return err
 	}
 
+	// The baseline only covers the main environment; the keys of a layered
+	// file that come from base environments are set apart. A layered file
+	// doesn't match any single environment, so it has no lockfile entry.
+	var kept map[string]string
+	if !opts.Force {
+		kept = make(map[string]string, len(localSecrets))
+		for k, v := range localSecrets {
+			if _, ok := baseOnly[k]; !ok {
+				kept[k] = v
+			}
+		}
+	}
+	recordBaseline(deps, repo, envName, envFilePath, mainSecrets, kept, baseOnly)
+	if !layered {
+		updateLockfile(deps, envName, vaultRevision, rawSecrets)
+	}
+
 	lines := env.CountLines(finalContent)
 	deps.UI.Success(fmt.Sprintf("Secrets downloaded to %s", deps.UI.File(opts.File)))
 	deps.UI.Message(fmt.Sprintf("Variables: %s", deps.UI.Value(lines)))
@@ -263,7 +364,189 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		deps.UI.Message(fmt.Sprintf("Kept %s local-only variables", deps.UI.Value(len(diff.LocalOnly))))
 	}
 
+	if opts.Watch {
+		w := &pullWatcher{
+			opts:     opts,
+			deps:     deps,
+			client:   client,
+			repo:     repo,
+			envName:  envName,
+			path:     envFilePath,
+			revision: vaultRevision,
+			vault:    vaultSecrets,
+		}
+		return w.run()
+	}
+
 	deps.UI.Outro("Secrets synced!")
 
 	return nil
 }
+
+// pullWatcher keeps a pulled file in sync with the vault for pull --watch
+type pullWatcher struct {
+	opts     PullOptions
+	deps     *Dependencies
+	client   api.APIClient
+	repo     string
+	envName  string
+	path     string
+	revision string
+	vault    map[string]string
+}
+
+// run polls the vault until the context is cancelled. Polls are conditional
+// on the last revision, so an unchanged vault costs a 304 and nothing else.
+// Errors are reported and retried: a network blip must not end a dev session.
+func (w *pullWatcher) run() error {
+	if w.opts.Context == nil {
+		w.opts.Context = context.Background()
+	}
+	ctx := w.opts.Context
+	interval := w.opts.Interval
+	if interval <= 0 {
+		interval = pullWatchInterval
+	}
+
+	w.deps.UI.Info(fmt.Sprintf("Watching %s for changes (every %s, Ctrl+C to stop)...", w.envName, interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.deps.UI.Outro("Stopped watching")
+			return nil
+		case <-ticker.C:
+		}
+
+		resp, err := w.client.PullSecretsIfChanged(ctx, w.repo, w.envName, w.revision)
+		if err != nil && isAuthError(err) {
+			newToken, authErr := handleAuthError(err, w.deps)
+			if authErr != nil {
+				return authErr
+			}
+			w.client = w.deps.APIFactory.NewClient(newToken)
+			resp, err = w.client.PullSecretsIfChanged(ctx, w.repo, w.envName, w.revision)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+				// Every secret was removed; keep the file as is
+				continue
+			}
+			w.deps.UI.Warn(fmt.Sprintf("Failed to check for changes: %s", err.Error()))
+			continue
+		}
+		if resp == nil {
+			continue
+		}
+
+		if err := w.apply(resp); err != nil {
+			w.deps.UI.Warn(fmt.Sprintf("Failed to update %s: %s", w.opts.File, err.Error()))
+		}
+	}
+}
+
+// apply rewrites the file with the new vault state, using the same merge
+// semantics as a normal pull, and prints a masked diff of what changed
+func (w *pullWatcher) apply(resp *api.PullSecretsResponse) error {
+	rawSecrets := env.Parse(resp.Content)
+	vaultSecrets, refKeys, err := resolveSecretRefs(w.opts.Context, w.client, w.repo, w.envName, rawSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to resolve references: %w", err)
+	}
+	content := withResolvedRefs(resp.Content, vaultSecrets, refKeys)
+	w.revision = resp.Revision
+
+	changes := compareSecrets("before", "after", w.vault, vaultSecrets, false)
+	if len(changes.OnlyInEnv1) == 0 && len(changes.OnlyInEnv2) == 0 && len(changes.Different) == 0 {
+		w.vault = vaultSecrets
+		return nil
+	}
+
+	finalContent := content
+	var localSecrets map[string]string
+	if !w.opts.Force {
+		localSecrets = make(map[string]string)
+		if data, err := w.deps.FS.ReadFile(w.path); err == nil {
+			localSecrets = env.Parse(string(data))
+		}
+		finalContent = env.Merge(content, localSecrets, vaultSecrets)
+	}
+	if err := w.deps.FS.WriteFile(w.path, []byte(finalContent), 0600); err != nil {
+		return err
+	}
+	w.vault = vaultSecrets
+
+	w.deps.UI.Message("")
+	w.deps.UI.Message(fmt.Sprintf("%s %s", w.deps.UI.Dim(time.Now().Format("15:04:05")), "Vault changed:"))
+	for _, key := range changes.OnlyInEnv2 {
+		w.deps.UI.DiffAdded(key)
+	}
+	for _, entry := range changes.Different {
+		w.deps.UI.DiffChanged(fmt.Sprintf("%s %s", entry.Key, w.deps.UI.Dim(fmt.Sprintf("%s → %s", entry.Preview1, entry.Preview2))))
+	}
+	for _, key := range changes.OnlyInEnv1 {
+		w.deps.UI.DiffRemoved(key)
+	}
+	if !w.opts.Force && len(changes.OnlyInEnv1) > 0 {
+		w.deps.UI.Message(w.deps.UI.Dim("Removed keys are kept in the file as local-only variables"))
+	}
+	w.deps.UI.Success(fmt.Sprintf("Updated %s", w.deps.UI.File(w.opts.File)))
+
+	recordBaseline(w.deps, w.repo, w.envName, w.path, vaultSecrets, localSecrets, nil)
+	updateLockfile(w.deps, w.envName, w.revision, rawSecrets)
+	return nil
+}
+
+// recordBaseline remembers the vault state that a local file was synced with,
+// so the next push can tell local edits from changes made by someone else.
+// local is what the file held before a merging pull, whose local-only keys
+// stay in the file (see env.PullBaseline), and layers what a layered pull
+// took from base environments only. Failing to record the baseline only
+// costs conflict detection, so it is not fatal.
+func recordBaseline(deps *Dependencies, repo, envName, file string, secrets, local, layers map[string]string) {
+	var prev *env.Baseline
+	if len(local) > 0 {
+		prev, _ = deps.Baselines.Load(repo, envName, file)
+	}
+	baseline, err := env.PullBaseline(prev, secrets, local)
+	if err == nil {
+		baseline.SetLayered(layers)
+		err = deps.Baselines.Save(repo, envName, file, baseline)
+	}
+	if err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not record sync baseline: %s", err.Error()))
+	}
+}
+
+// updateLockfile records the fingerprints of the pulled environment in
+// .keyway.lock, keeping the existing salt so unchanged keys keep their hash.
+// A lockfile in an outdated format is started over, as its fingerprints
+// can't be rehashed without the values of the other environments.
+func updateLockfile(deps *Dependencies, envName, revision string, secrets map[string]string) {
+	var lock *env.Lockfile
+	data, err := deps.FS.ReadFile(env.LockfileName)
+	if err == nil {
+		lock, err = env.ParseLockfile(data)
+		if err == nil && lock.Outdated() {
+			deps.UI.Warn(fmt.Sprintf("Rewrote %s with stronger fingerprints - pull its other environments again to record them", env.LockfileName))
+			lock, err = env.NewLockfile()
+		}
+	} else {
+		lock, err = env.NewLockfile()
+	}
+	if err == nil {
+		lock.Record(envName, revision, secrets)
+		data, err = lock.Marshal()
+	}
+	if err == nil {
+		err = deps.FS.WriteFile(env.LockfileName, data, 0644)
+	}
+	if err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not update %s: %s", env.LockfileName, err.Error()))
+	}
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/env"
@@ -467,3 +469,148 @@ func TestRunPullWithDeps_APIErrorWithUpgradeURL(t *testing.T) {
 		t.Error("expected UI.Message to be called for upgrade URL")
 	}
 }
+
+func TestRunPullWithDeps_RecordsBaseline(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	b, _ := deps.Baselines.Load("owner/repo", "development", ".env")
+	if b == nil || b.Hashes["API_KEY"] != b.Hash("secret123") {
+		t.Errorf("expected baseline of the vault state, got %+v", b)
+	}
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("expected no warnings, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunPullWithDeps_RemoteDeletionNotPushedBack(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	// X was in the vault at the last pull, and a teammate deleted it since
+	withPushBaseline(t, deps, map[string]string{"API_KEY": "a", "X": "old"})
+	fsMock.Files[".env"] = []byte("API_KEY=a\nX=old\nNEW=mine")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=a"}
+
+	pullOpts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(pullOpts, deps); err != nil {
+		t.Fatalf("pull: expected no error, got %v", err)
+	}
+	if !strings.Contains(string(fsMock.Files[".env"]), "X=old") {
+		t.Fatalf("expected X to be kept as a local-only variable, got %q", fsMock.Files[".env"])
+	}
+
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	pushOpts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(pushOpts, deps); err != nil {
+		t.Fatalf("push: expected no error, got %v", err)
+	}
+	if _, ok := apiMock.PushedSecrets["X"]; ok {
+		t.Errorf("expected the deleted key not to be pushed back, got %v", apiMock.PushedSecrets)
+	}
+	if apiMock.PushedSecrets["NEW"] != "mine" {
+		t.Errorf("expected the local addition to be pushed, got %v", apiMock.PushedSecrets)
+	}
+}
+
+func TestRunPullWithDeps_Watch(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=old\nLOCAL_ONLY=keep")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old\nGONE=x", Revision: "rev1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var revisions []string
+	apiMock.PullIfChangedFn = func(revision string) (*api.PullSecretsResponse, error) {
+		revisions = append(revisions, revision)
+		switch len(revisions) {
+		case 1:
+			return nil, nil // unchanged
+		case 2:
+			return nil, errors.New("network down")
+		case 3:
+			return &api.PullSecretsResponse{Content: "API_KEY=rotated-value\nNEW=1", Revision: "rev2"}, nil
+		default:
+			cancel()
+			return nil, nil
+		}
+	}
+
+	opts := PullOptions{
+		EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true,
+		Watch: true, Context: ctx, Interval: time.Millisecond,
+	}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(revisions) < 4 || revisions[0] != "rev1" || revisions[3] != "rev2" {
+		t.Errorf("expected polls conditional on the last revision, got %v", revisions)
+	}
+
+	written := env.Parse(string(fsMock.Written[".env"]))
+	if written["API_KEY"] != "rotated-value" || written["NEW"] != "1" || written["LOCAL_ONLY"] != "keep" {
+		t.Errorf("unexpected file after change: %v", written)
+	}
+
+	// The initial pull reports GONE as added; the watcher then reports NEW
+	if n := len(uiMock.DiffAddedCalls); n == 0 || uiMock.DiffAddedCalls[n-1] != "NEW" {
+		t.Errorf("expected NEW to be reported as added, got %v", uiMock.DiffAddedCalls)
+	}
+	if len(uiMock.DiffRemovedCalls) != 1 || uiMock.DiffRemovedCalls[0] != "GONE" {
+		t.Errorf("expected GONE to be reported as removed, got %v", uiMock.DiffRemovedCalls)
+	}
+	for _, c := range uiMock.DiffChangedCalls {
+		if strings.Contains(c, "rotated-value") {
+			t.Errorf("diff must not print raw values: %q", c)
+		}
+	}
+	if len(uiMock.WarnCalls) != 1 {
+		t.Errorf("expected the failed poll to be reported once, got %v", uiMock.WarnCalls)
+	}
+	if len(uiMock.OutroCalls) != 1 || uiMock.OutroCalls[0] != "Stopped watching" {
+		t.Errorf("unexpected outro: %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunPullWithDeps_LayeredEnvironments(t *testing.T) {
+	deps, _, _, _, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullByEnv = map[string]string{
+		"base":        "LOG_LEVEL=info\nDB_URL=postgres://base",
+		"development": "DB_URL=postgres://dev",
+	}
+
+	opts := PullOptions{EnvName: "development", BaseEnvs: []string{"base"}, File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	written := env.Parse(string(fsMock.Written[".env"]))
+	if written["LOG_LEVEL"] != "info" || written["DB_URL"] != "postgres://dev" {
+		t.Errorf("unexpected layered file: %v", written)
+	}
+	if _, ok := fsMock.Written[env.LockfileName]; ok {
+		t.Error("layered pulls should not be recorded in the lockfile")
+	}
+}
+
+func TestRunPullWithDeps_LayeredWatchRejected(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+
+	opts := PullOptions{EnvName: "development", BaseEnvs: []string{"base"}, File: ".env", Yes: true, EnvFlagSet: true, Watch: true}
+	if err := runPullWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error combining --watch with layered environments")
+	}
+	if len(fsMock.Written) != 0 {
+		t.Error("expected nothing to be written")
+	}
+}
@@ -3,11 +3,13 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/keywaysh/cli/internal/analytics"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/e2e"
 	"github.com/keywaysh/cli/internal/env"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +26,7 @@ func init() {
 	pushCmd.Flags().StringP("file", "f", "", "Env file to push")
 	pushCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	pushCmd.Flags().Bool("prune", false, "Remove secrets from vault that are not in local file")
+	pushCmd.Flags().Bool("force", false, "Overwrite vault changes made since the last pull")
 }
 
 // PushOptions contains the parsed flags for the push command
@@ -32,6 +35,7 @@ type PushOptions struct {
 	File       string
 	Yes        bool
 	Prune      bool
+	Force      bool
 	EnvFlagSet bool
 }
 
@@ -44,6 +48,7 @@ func runPush(cmd *cobra.Command, args []string) error {
 	opts.File, _ = cmd.Flags().GetString("file")
 	opts.Yes, _ = cmd.Flags().GetBool("yes")
 	opts.Prune, _ = cmd.Flags().GetBool("prune")
+	opts.Force, _ = cmd.Flags().GetBool("force")
 
 	return runPushWithDeps(opts, defaultDeps)
 }
@@ -135,13 +140,32 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		return fmt.Errorf("file is empty")
 	}
 
-	secrets := env.Parse(string(content))
+	// Sealed files are decrypted locally and pushed like plain env files
+	sealed := e2e.IsSealed(content)
+	var secrets map[string]string
+	if sealed {
+		id, err := deps.Identity.Load()
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("%s is sealed but your identity can't be loaded", file))
+			return err
+		}
+		if secrets, err = e2e.Unseal(content, id); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	} else {
+		secrets = env.Parse(string(content))
+	}
 	if len(secrets) == 0 {
 		deps.UI.Error("No valid environment variables found in file")
 		return fmt.Errorf("no variables found")
 	}
 
-	deps.UI.Step(fmt.Sprintf("File: %s", deps.UI.File(file)))
+	if sealed {
+		deps.UI.Step(fmt.Sprintf("File: %s %s", deps.UI.File(file), deps.UI.Dim("(sealed)")))
+	} else {
+		deps.UI.Step(fmt.Sprintf("File: %s", deps.UI.File(file)))
+	}
 	deps.UI.Step(fmt.Sprintf("Variables: %s", deps.UI.Value(len(secrets))))
 
 	repo, err := deps.Git.DetectRepo()
@@ -248,54 +272,94 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		}
 	}
 
-	// Calculate and show diff
-	diff := env.CalculatePushDiff(secrets, vaultSecrets)
+	// The local file holds resolved values, so compare against resolved
+	// references and send the references back unless they were edited
+	rawVaultSecrets := vaultSecrets
+	var refKeys []string
+	vaultSecrets, refKeys, err = resolveSecretRefs(ctx, client, repo, envName, rawVaultSecrets)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to resolve references: %s", err.Error()))
+		return err
+	}
 
-	// When --prune is NOT set, merge vault secrets into local (additive mode)
-	// This preserves vault-only secrets instead of deleting them
-	secretsToSend := secrets
-	if !opts.Prune && len(diff.Removed) > 0 {
-		// Merge: start with vault secrets, overlay local secrets
-		secretsToSend = make(map[string]string)
-		for k, v := range vaultSecrets {
-			secretsToSend[k] = v
-		}
-		for k, v := range secrets {
-			secretsToSend[k] = v
-		}
+	// With a baseline from the last pull, only keys changed locally since
+	// then are pushed; changes made in the vault meanwhile are kept
+	baselineFile := filepath.Join(".", file)
+	baseline, err := deps.Baselines.Load(repo, envName, baselineFile)
+	if err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not read sync baseline: %s", err.Error()))
+		baseline = nil
 	}
 
-	if diff.HasChanges() {
-		// Show additions and updates
-		if len(diff.Added) > 0 || len(diff.Changed) > 0 {
-			deps.UI.Message("")
-			deps.UI.Message("Will be pushed to vault:")
-			for _, key := range diff.Added {
-				deps.UI.DiffAdded(key)
+	if baseline != nil {
+		// Keys a layered pull took from base environments aren't pushed
+		secrets = baseline.WithoutLayered(secrets)
+	}
+
+	var secretsToSend map[string]string
+	var nextBaseline *env.Baseline
+	if baseline != nil && !opts.Force {
+		merge, err := mergeWithBaseline(opts, deps, baseline, secrets, vaultSecrets)
+		if err != nil {
+			return err
+		}
+		secretsToSend = merge.Result
+		nextBaseline = merge.Baseline(baseline)
+	} else {
+		// Calculate and show diff
+		diff := env.CalculatePushDiff(secrets, vaultSecrets)
+
+		// When --prune is NOT set, merge vault secrets into local (additive mode)
+		// This preserves vault-only secrets instead of deleting them
+		secretsToSend = secrets
+		if !opts.Prune && len(diff.Removed) > 0 {
+			// Merge: start with vault secrets, overlay local secrets
+			secretsToSend = make(map[string]string)
+			for k, v := range vaultSecrets {
+				secretsToSend[k] = v
 			}
-			for _, key := range diff.Changed {
-				deps.UI.DiffChanged(key)
+			for k, v := range secrets {
+				secretsToSend[k] = v
 			}
 		}
 
-		// Show removals only when --prune is set
-		if opts.Prune && len(diff.Removed) > 0 {
-			deps.UI.Message("")
-			deps.UI.Message("Will be moved to trash (not in local file):")
-			for _, key := range diff.Removed {
-				deps.UI.DiffRemoved(key)
+		if diff.HasChanges() {
+			// Show additions and updates
+			if len(diff.Added) > 0 || len(diff.Changed) > 0 {
+				deps.UI.Message("")
+				deps.UI.Message("Will be pushed to vault:")
+				for _, key := range diff.Added {
+					deps.UI.DiffAdded(key)
+				}
+				for _, key := range diff.Changed {
+					deps.UI.DiffChanged(key)
+				}
 			}
-		}
 
-		// Warn about vault-only secrets when --prune is NOT set
-		if !opts.Prune && len(diff.Removed) > 0 {
+			// Show removals only when --prune is set
+			if opts.Prune && len(diff.Removed) > 0 {
+				deps.UI.Message("")
+				deps.UI.Message("Will be moved to trash (not in local file):")
+				for _, key := range diff.Removed {
+					deps.UI.DiffRemoved(key)
+				}
+			}
+
+			// Warn about vault-only secrets when --prune is NOT set
+			if !opts.Prune && len(diff.Removed) > 0 {
+				deps.UI.Message("")
+				deps.UI.Warn(fmt.Sprintf("%d secret(s) in vault not in local file: %s", len(diff.Removed), strings.Join(diff.Removed, ", ")))
+				deps.UI.Message(deps.UI.Dim("Use --prune to remove them, or keyway pull to fetch them"))
+			}
 			deps.UI.Message("")
-			deps.UI.Warn(fmt.Sprintf("%d secret(s) in vault not in local file: %s", len(diff.Removed), strings.Join(diff.Removed, ", ")))
-			deps.UI.Message(deps.UI.Dim("Use --prune to remove them, or keyway pull to fetch them"))
+		} else {
+			deps.UI.Info("No changes detected")
 		}
-		deps.UI.Message("")
-	} else {
-		deps.UI.Info("No changes detected")
+	}
+
+	payload, replacedRefs := keepRefs(secretsToSend, rawVaultSecrets, vaultSecrets, refKeys)
+	if len(replacedRefs) > 0 {
+		deps.UI.Warn(fmt.Sprintf("%d reference(s) will be replaced by a literal value: %s", len(replacedRefs), strings.Join(replacedRefs, ", ")))
 	}
 
 	// Confirm
@@ -319,7 +383,7 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 	var resp *api.PushSecretsResponse
 	err = deps.UI.Spin("Uploading secrets...", func() error {
 		var err error
-		resp, err = client.PushSecrets(ctx, repo, envName, secretsToSend)
+		resp, err = client.PushSecrets(ctx, repo, envName, payload)
 		return err
 	})
 
@@ -334,7 +398,7 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 			client = deps.APIFactory.NewClient(newToken)
 			err = deps.UI.Spin("Uploading secrets...", func() error {
 				var pushErr error
-				resp, pushErr = client.PushSecrets(ctx, repo, envName, secretsToSend)
+				resp, pushErr = client.PushSecrets(ctx, repo, envName, payload)
 				return pushErr
 			})
 		}
@@ -358,6 +422,15 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 			return err
 		}
 	}
+	evictAgentCache(deps, repo, envName)
+
+	if nextBaseline != nil {
+		if err := deps.Baselines.Save(repo, envName, baselineFile, nextBaseline); err != nil {
+			deps.UI.Warn(fmt.Sprintf("Could not record sync baseline: %s", err.Error()))
+		}
+	} else {
+		recordBaseline(deps, repo, envName, baselineFile, secretsToSend, nil, nil)
+	}
 
 	deps.UI.Success(resp.Message)
 	if resp.Stats != nil {
@@ -381,3 +454,64 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 
 	return nil
 }
+
+// mergeWithBaseline runs a three-way merge between the baseline, the local
+// file and the vault, shows the preview and resolves conflicts. Conflicts are
+// prompted for in interactive mode and fail otherwise, even with --yes.
+func mergeWithBaseline(opts PushOptions, deps *Dependencies, baseline *env.Baseline, secrets, vaultSecrets map[string]string) (*env.MergeResult, error) {
+	merge := env.ThreeWayMerge(baseline, secrets, vaultSecrets, opts.Prune)
+
+	if len(merge.Conflicts) > 0 {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error(fmt.Sprintf("%d secret(s) changed both locally and in the vault since your last pull: %s", len(merge.Conflicts), strings.Join(merge.Conflicts, ", ")))
+			deps.UI.Message(deps.UI.Dim("Run keyway pull to merge them, or use --force to overwrite the vault"))
+			return nil, fmt.Errorf("push conflicts with vault changes")
+		}
+		deps.UI.Message("")
+		deps.UI.Warn(fmt.Sprintf("%d secret(s) changed both locally and in the vault since your last pull", len(merge.Conflicts)))
+		for _, key := range merge.Conflicts {
+			choice, err := deps.UI.Select(fmt.Sprintf("%s:", key), []string{"Keep vault value", "Use local value"})
+			if err != nil {
+				return nil, err
+			}
+			merge.Resolve(key, choice == "Use local value")
+		}
+	}
+
+	if len(merge.Pushed) == 0 && len(merge.Pruned) == 0 && len(merge.Kept) == 0 {
+		deps.UI.Info("No changes detected")
+		return merge, nil
+	}
+
+	if len(merge.Pushed) > 0 {
+		deps.UI.Message("")
+		deps.UI.Message("Will be pushed to vault:")
+		for _, key := range merge.Pushed {
+			if _, ok := vaultSecrets[key]; ok {
+				deps.UI.DiffChanged(key)
+			} else {
+				deps.UI.DiffAdded(key)
+			}
+		}
+	}
+
+	if len(merge.Pruned) > 0 {
+		deps.UI.Message("")
+		deps.UI.Message("Will be moved to trash (not in local file):")
+		for _, key := range merge.Pruned {
+			deps.UI.DiffRemoved(key)
+		}
+	}
+
+	if len(merge.Kept) > 0 {
+		deps.UI.Message("")
+		deps.UI.Message("Changed in vault since your last pull (kept):")
+		for _, key := range merge.Kept {
+			deps.UI.DiffKept(key)
+		}
+		deps.UI.Message(deps.UI.Dim("Run keyway pull to fetch them"))
+	}
+	deps.UI.Message("")
+
+	return merge, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
 )
 
 func TestRunPushWithDeps_Success(t *testing.T) {
@@ -746,3 +747,163 @@ func TestRunPushWithDeps_NoVaultOnlySecrets_NoPruneWarning(t *testing.T) {
 		t.Error("did not expect prune warning when there are no vault-only secrets")
 	}
 }
+
+// withPushBaseline records a baseline for .env/development as if pulled earlier
+func withPushBaseline(t *testing.T, deps *Dependencies, secrets map[string]string) *env.Baseline {
+	t.Helper()
+	b, err := env.NewBaseline(secrets)
+	if err != nil {
+		t.Fatalf("NewBaseline failed: %v", err)
+	}
+	if err := deps.Baselines.Save("owner/repo", "development", ".env", b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return b
+}
+
+func TestRunPushWithDeps_ThreeWayKeepsRemoteChanges(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	withPushBaseline(t, deps, map[string]string{"LOCAL": "old", "REMOTE": "old"})
+	fsMock.Files[".env"] = []byte("LOCAL=new\nREMOTE=old")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "LOCAL=old\nREMOTE=theirs\nADDED=r"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]string{"LOCAL": "new", "REMOTE": "theirs", "ADDED": "r"}
+	for k, v := range want {
+		if apiMock.PushedSecrets[k] != v {
+			t.Errorf("pushed %s = %q, want %q", k, apiMock.PushedSecrets[k], v)
+		}
+	}
+	if len(uiMock.DiffChangedCalls) != 1 || uiMock.DiffChangedCalls[0] != "LOCAL" {
+		t.Errorf("expected only LOCAL to be pushed, got %v", uiMock.DiffChangedCalls)
+	}
+	if len(uiMock.DiffKeptCalls) != 2 {
+		t.Errorf("expected remote changes to be listed as kept, got %v", uiMock.DiffKeptCalls)
+	}
+}
+
+func TestRunPushWithDeps_ThreeWayConflictNonInteractive(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	withPushBaseline(t, deps, map[string]string{"API_KEY": "old"})
+	fsMock.Files[".env"] = []byte("API_KEY=mine")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=theirs"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	uiMock.Interactive = false
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected conflict error even with --yes")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected nothing to be pushed")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected conflict to be reported")
+	}
+}
+
+func TestRunPushWithDeps_ThreeWayConflictInteractive(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	withPushBaseline(t, deps, map[string]string{"API_KEY": "old"})
+	fsMock.Files[".env"] = []byte("API_KEY=mine")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=theirs"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	uiMock.Interactive = true
+	uiMock.SelectResult = "Use local value"
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.SelectCalls) != 1 {
+		t.Errorf("expected one conflict prompt, got %v", uiMock.SelectCalls)
+	}
+	if apiMock.PushedSecrets["API_KEY"] != "mine" {
+		t.Errorf("expected local value to be pushed, got %q", apiMock.PushedSecrets["API_KEY"])
+	}
+}
+
+func TestRunPushWithDeps_ForceIgnoresBaseline(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	withPushBaseline(t, deps, map[string]string{"API_KEY": "old"})
+	fsMock.Files[".env"] = []byte("API_KEY=mine")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=theirs"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	uiMock.Interactive = false
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, Force: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedSecrets["API_KEY"] != "mine" {
+		t.Errorf("expected --force to push the local value, got %q", apiMock.PushedSecrets["API_KEY"])
+	}
+}
+
+func TestRunPushWithDeps_RecordsBaseline(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	b, _ := deps.Baselines.Load("owner/repo", "development", ".env")
+	if b == nil || b.Hashes["API_KEY"] != b.Hash("secret123") {
+		t.Errorf("expected baseline of the pushed state, got %+v", b)
+	}
+}
+
+func TestRunPushWithDeps_EvictsAgentCache(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "staging"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+
+	opts := PushOptions{EnvName: "staging", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(agentMock.Evicted) != 1 || agentMock.Evicted[0] != "owner/repo:staging" {
+		t.Errorf("expected the environment to be evicted from the agent, got %v", agentMock.Evicted)
+	}
+}
+
+func TestRunPushWithDeps_PushFailureKeepsAgentCache(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushError = errors.New("server error")
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(agentMock.Evicted) != 0 {
+		t.Errorf("expected no eviction after a failed push, got %v", agentMock.Evicted)
+	}
+}
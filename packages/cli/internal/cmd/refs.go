@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// resolveSecretRefs replaces keyway:// references in secrets with the values
+// they point at, fetching the referenced vaults with the caller's own token.
+// It returns the resolved secrets and the keys that were references.
+func resolveSecretRefs(ctx context.Context, client api.APIClient, repo, envName string, secrets map[string]string) (map[string]string, []string, error) {
+	return env.ResolveRefs(repo, envName, secrets, func(refRepo, refEnv string) (map[string]string, error) {
+		resp, err := client.PullSecrets(ctx, refRepo, refEnv)
+		if err != nil {
+			if apiErr, ok := err.(*api.APIError); ok {
+				switch apiErr.StatusCode {
+				case 403:
+					return nil, fmt.Errorf("you don't have access to %s", refRepo)
+				case 404:
+					return nil, fmt.Errorf("%s has no %s environment, or you don't have access to it", refRepo, refEnv)
+				}
+			}
+			return nil, err
+		}
+		return env.Parse(resp.Content), nil
+	})
+}
+
+// keepRefs returns the secrets to push with references preserved: a key whose
+// local value is still the resolved value of a vault reference is sent as the
+// reference, so pushing a pulled file doesn't replace references with copies.
+// It also returns the referencing keys that get a literal value instead.
+func keepRefs(secrets, rawVault, resolvedVault map[string]string, refKeys []string) (map[string]string, []string) {
+	out := make(map[string]string, len(secrets))
+	for k, v := range secrets {
+		out[k] = v
+	}
+	var replaced []string
+	for _, key := range refKeys {
+		value, ok := out[key]
+		if !ok {
+			continue
+		}
+		if value == resolvedVault[key] {
+			out[key] = rawVault[key]
+		} else if !env.IsRef(value) {
+			replaced = append(replaced, key)
+		}
+	}
+	return out, replaced
+}
+
+// withResolvedRefs rewrites the referencing lines of env content with their
+// resolved values
+func withResolvedRefs(content string, resolved map[string]string, refKeys []string) string {
+	if len(refKeys) == 0 {
+		return content
+	}
+	values := make(map[string]string, len(refKeys))
+	for _, key := range refKeys {
+		values[key] = resolved[key]
+	}
+	return env.ReplaceValues(content, values)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+)
+
+const sentryRef = "keyway://acme/shared-infra/production/SENTRY_DSN"
+
+func TestRunPullWithDeps_ResolvesRefs(t *testing.T) {
+	deps, _, _, _, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=k\nSENTRY_DSN=" + sentryRef, Revision: "rev1"}
+	apiMock.PullByRef = map[string]string{"acme/shared-infra/production": "SENTRY_DSN=https://key@sentry.io/1"}
+
+	opts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	written := string(fsMock.Written[".env"])
+	if !strings.Contains(written, "SENTRY_DSN=https://key@sentry.io/1") || strings.Contains(written, "keyway://") {
+		t.Errorf("expected the reference to be resolved, got %q", written)
+	}
+
+	// The lockfile fingerprints what the vault stores: the reference itself
+	lock, err := env.ParseLockfile(fsMock.Written[env.LockfileName])
+	if err != nil {
+		t.Fatalf("ParseLockfile failed: %v", err)
+	}
+	if lock.Environments["development"].Secrets["SENTRY_DSN"] != lock.Hash("SENTRY_DSN", sentryRef) {
+		t.Error("expected the lockfile to record the reference")
+	}
+}
+
+func TestRunPullWithDeps_RefWithoutAccess(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "SENTRY_DSN=" + sentryRef}
+	apiMock.PullRefError = &api.APIError{StatusCode: 403, Detail: "Forbidden"}
+
+	opts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	err := runPullWithDeps(opts, deps)
+	if err == nil || !strings.Contains(err.Error(), "you don't have access to acme/shared-infra") {
+		t.Fatalf("expected an access error, got %v", err)
+	}
+	if _, ok := fsMock.Written[".env"]; ok {
+		t.Error("expected nothing to be written")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected error to be shown")
+	}
+}
+
+func TestRunRunWithDeps_ResolvesRefs(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "SENTRY_DSN=" + sentryRef}
+	apiMock.PullByRef = map[string]string{"acme/shared-infra/production": "SENTRY_DSN=https://key@sentry.io/1"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "node", Args: []string{"app.js"}}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cmdRunner.LastSecrets["SENTRY_DSN"] != "https://key@sentry.io/1" {
+		t.Errorf("expected the resolved value, got %q", cmdRunner.LastSecrets["SENTRY_DSN"])
+	}
+}
+
+func TestRunRunWithDeps_RefSessionExpired(t *testing.T) {
+	// handleAuthError clears the stored session
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "SENTRY_DSN=" + sentryRef}
+	apiMock.PullRefError = &api.APIError{StatusCode: 401, Detail: "Unauthorized"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "node"}
+	err := runRunWithDeps(opts, deps)
+	if !isAuthError(err) {
+		t.Fatalf("expected the 401 of the reference to be an auth error, got %v", err)
+	}
+	if len(uiMock.ErrorCalls) == 0 || uiMock.ErrorCalls[0] != "Session expired or invalid" {
+		t.Errorf("expected the expired session to be reported, got %v", uiMock.ErrorCalls)
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected the command not to run")
+	}
+}
+
+func TestIsAuthError_Wrapped(t *testing.T) {
+	unauthorized := &api.APIError{StatusCode: 401}
+	if !isAuthError(fmt.Errorf("failed to resolve references in development: %w", unauthorized)) {
+		t.Error("expected a wrapped 401 to be an auth error")
+	}
+	if isAuthError(fmt.Errorf("wrapped: %w", &api.APIError{StatusCode: 403})) {
+		t.Error("expected a 403 not to be an auth error")
+	}
+}
+
+func TestRunRunWithDeps_RefCycle(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=" + sentryRef}
+	apiMock.PullByRef = map[string]string{"acme/shared-infra/production": "SENTRY_DSN=keyway://owner/repo/development/A"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "node"}
+	err := runRunWithDeps(opts, deps)
+	if err == nil || !strings.Contains(err.Error(), "reference cycle") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected the command not to run")
+	}
+}
+
+func TestRunPushWithDeps_KeepsRefs(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("SENTRY_DSN=https://key@sentry.io/1\nOTHER_DSN=literal\nAPI_KEY=new")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "SENTRY_DSN=" + sentryRef + "\nOTHER_DSN=" + sentryRef + "\nAPI_KEY=old"}
+	apiMock.PullByRef = map[string]string{"acme/shared-infra/production": "SENTRY_DSN=https://key@sentry.io/1"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "ok"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.PushedSecrets["SENTRY_DSN"] != sentryRef {
+		t.Errorf("expected the unchanged reference to be pushed as is, got %q", apiMock.PushedSecrets["SENTRY_DSN"])
+	}
+	if apiMock.PushedSecrets["OTHER_DSN"] != "literal" || apiMock.PushedSecrets["API_KEY"] != "new" {
+		t.Errorf("unexpected pushed secrets: %v", apiMock.PushedSecrets)
+	}
+
+	warned := false
+	for _, w := range uiMock.WarnCalls {
+		if strings.Contains(w, "OTHER_DSN") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("expected a warning about the replaced reference, got %v", uiMock.WarnCalls)
+	}
+}
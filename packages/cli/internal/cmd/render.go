@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a config template with secrets",
+	Long: `Render a Go text/template with the secrets of an environment, for services
+that read YAML, TOML or JSON config files instead of environment variables.
+
+Secrets are available as {{ .KEY }}. Referencing a key that is not in the vault
+is an error. Helper functions:
+  b64enc, b64dec   base64 encode/decode: {{ .TLS_CERT | b64enc }}
+  json             quote as a JSON string (valid in YAML and TOML): {{ .PASSWORD | json }}
+  default          fallback for empty values: {{ get "LOG_LEVEL" | default "info" }}
+  get              look up a key that may be missing
+  unescape         turn literal \n into newlines (single-line PEM keys)
+
+The output is written with 0600 permissions, or to stdout without --output.`,
+	Example: `  keyway render -e production -i config.tmpl -o config.yaml
+  keyway render -i config.tmpl | kubectl apply -f -`,
+	Args: cobra.NoArgs,
+	RunE: runRender,
+}
+
+func init() {
+	renderCmd.Flags().StringP("env", "e", "development", "Environment name")
+	renderCmd.Flags().StringP("input", "i", "", "Template file")
+	renderCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	_ = renderCmd.MarkFlagRequired("input")
+}
+
+// RenderOptions contains the parsed flags for the render command
+type RenderOptions struct {
+	EnvName string
+	Input   string
+	Output  string
+
+	// Stdout receives the output when no --output is given (defaults to os.Stdout)
+	Stdout io.Writer
+}
+
+// runRender is the entry point for the render command (uses default dependencies)
+func runRender(cmd *cobra.Command, args []string) error {
+	opts := RenderOptions{}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Input, _ = cmd.Flags().GetString("input")
+	opts.Output, _ = cmd.Flags().GetString("output")
+
+	return runRenderWithDeps(opts, defaultDeps)
+}
+
+// runRenderWithDeps is the testable version of runRender
+func runRenderWithDeps(opts RenderOptions, deps *Dependencies) error {
+	// When streaming to stdout, keep the UI quiet so the output can be piped
+	toStdout := opts.Output == "" || opts.Output == "-"
+	if !toStdout {
+		deps.UI.Intro("render")
+	}
+
+	tmpl, err := deps.FS.ReadFile(opts.Input)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Cannot read template %s", opts.Input))
+		return err
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+	envName := env.NormalizeEnvName(opts.EnvName)
+
+	if !toStdout {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+		deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+	}
+
+	analytics.Track(analytics.EventRender, map[string]interface{}{
+		"repoFullName": repo,
+		"environment":  envName,
+	})
+
+	var secrets map[string]string
+	fetch := func() error {
+		resp, err := client.PullSecrets(ctx, repo, envName)
+		if err != nil {
+			return err
+		}
+		secrets, _, err = resolveSecretRefs(ctx, client, repo, envName, env.Parse(resp.Content))
+		return err
+	}
+	if toStdout {
+		err = fetch()
+	} else {
+		err = deps.UI.Spin("Fetching secrets...", fetch)
+	}
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		analytics.Track(analytics.EventError, map[string]interface{}{
+			"command": "render",
+			"error":   err.Error(),
+		})
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+
+	out, err := render.Render(filepath.Base(opts.Input), string(tmpl), secrets)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to render template: %s", err.Error()))
+		return err
+	}
+
+	if toStdout {
+		w := opts.Stdout
+		if w == nil {
+			w = os.Stdout
+		}
+		_, err = w.Write(out)
+		return err
+	}
+
+	if err := deps.FS.WriteFile(opts.Output, out, 0600); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write file: %s", err.Error()))
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Rendered %s to %s", deps.UI.File(opts.Input), deps.UI.File(opts.Output)))
+	deps.UI.Outro("Keep rendered files out of git, they contain secrets")
+	return nil
+}
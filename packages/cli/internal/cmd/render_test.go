@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunRenderWithDeps_WritesFile(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files["config.tmpl"] = []byte("db:\n  url: {{ .DB_URL }}\n  password: {{ .DB_PASSWORD | json }}\n")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DB_URL=postgres://db\nDB_PASSWORD=p\"w"}
+
+	opts := RenderOptions{EnvName: "production", Input: "config.tmpl", Output: "config.yaml"}
+	if err := runRenderWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "db:\n  url: postgres://db\n  password: \"p\\\"w\"\n"
+	if got := string(fsMock.Written["config.yaml"]); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if fsMock.Perms["config.yaml"] != 0600 {
+		t.Errorf("expected 0600 permissions, got %o", fsMock.Perms["config.yaml"])
+	}
+	if len(uiMock.SuccessCalls) != 1 {
+		t.Errorf("expected success message, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunRenderWithDeps_Stdout(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files["config.tmpl"] = []byte("token = {{ .TOKEN | json }}")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "TOKEN=abc"}
+
+	var out bytes.Buffer
+	opts := RenderOptions{EnvName: "production", Input: "config.tmpl", Stdout: &out}
+	if err := runRenderWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if out.String() != `token = "abc"` {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if len(fsMock.Written) != 0 {
+		t.Error("expected nothing to be written to disk")
+	}
+	if len(uiMock.IntroCalls) != 0 || len(uiMock.StepCalls) != 0 {
+		t.Error("expected no UI output when streaming to stdout")
+	}
+}
+
+func TestRunRenderWithDeps_MissingKey(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files["config.tmpl"] = []byte("url: {{ .DB_ULR }}")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DB_URL=postgres://db"}
+
+	opts := RenderOptions{EnvName: "production", Input: "config.tmpl", Output: "config.yaml"}
+	err := runRenderWithDeps(opts, deps)
+	if err == nil || !strings.Contains(err.Error(), "DB_ULR") {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+	if _, ok := fsMock.Written["config.yaml"]; ok {
+		t.Error("expected no output file")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected error to be shown")
+	}
+}
+
+func TestRunRenderWithDeps_MissingTemplate(t *testing.T) {
+	deps, _, _, _, _, _, _ := NewTestDepsWithEnv()
+
+	if err := runRenderWithDeps(RenderOptions{Input: "nope.tmpl"}, deps); err == nil {
+		t.Fatal("expected error for a missing template")
+	}
+}
@@ -0,0 +1,495 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [KEY]",
+	Short: "Restore a secret or a whole environment to a previous state",
+	Long: `Restore a secret to a previous version, or a whole environment to a point in time.
+
+With a KEY, restores that secret to the version given by --to (see 'keyway history').
+
+Without a KEY, --at restores every secret in the environment to the value it had
+at that time, and brings back secrets that were deleted since (e.g. by push --prune).
+Secrets created after that time are reported but left in place.
+
+The current values are kept in history, so a rollback can itself be rolled back.
+
+Examples:
+  keyway rollback API_KEY --to 3 -e production
+  keyway rollback -e production --at 2h                  # 2 hours ago
+  keyway rollback -e production --at 2026-01-15T09:30:00Z
+  keyway rollback -e staging --at 2026-01-15 --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRollback,
+}
+
+func init() {
+	rollbackCmd.Flags().StringP("env", "e", "development", "Environment name")
+	rollbackCmd.Flags().String("to", "", "Version to restore a single secret to (e.g. 3 or v3)")
+	rollbackCmd.Flags().String("at", "", "Restore the environment to this time (RFC 3339, YYYY-MM-DD, or a duration ago like 2h, 3d)")
+	rollbackCmd.Flags().Bool("dry-run", false, "Show what would be restored without changing anything")
+	rollbackCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+}
+
+// RollbackOptions contains the parsed flags for the rollback command
+type RollbackOptions struct {
+	Key       string
+	EnvName   string
+	ToVersion string
+	At        string
+	DryRun    bool
+	Yes       bool
+	Now       time.Time // Reference time for relative --at values (defaults to time.Now)
+}
+
+// rollbackPlan describes the changes an environment-wide rollback will make
+type rollbackPlan struct {
+	Versions      []rollbackVersion   // Secrets to restore to a previous version
+	Trashed       []api.TrashedSecret // Deleted secrets to bring back
+	CreatedAfter  []string            // Secrets that didn't exist yet (left in place)
+	Unrecoverable []string            // Secrets whose value at that time was pruned from history
+}
+
+type rollbackVersion struct {
+	Key           string
+	SecretID      string
+	VersionID     string
+	VersionNumber int
+}
+
+func (p *rollbackPlan) isEmpty() bool {
+	return len(p.Versions) == 0 && len(p.Trashed) == 0
+}
+
+// runRollback is the entry point for the rollback command (uses default dependencies)
+func runRollback(cmd *cobra.Command, args []string) error {
+	opts := RollbackOptions{}
+	if len(args) > 0 {
+		opts.Key = args[0]
+	}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.ToVersion, _ = cmd.Flags().GetString("to")
+	opts.At, _ = cmd.Flags().GetString("at")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+
+	return runRollbackWithDeps(opts, defaultDeps)
+}
+
+// runRollbackWithDeps is the testable version of runRollback
+func runRollbackWithDeps(opts RollbackOptions, deps *Dependencies) error {
+	deps.UI.Intro("rollback")
+
+	if opts.Key != "" && opts.ToVersion == "" {
+		deps.UI.Error("--to is required when rolling back a single secret")
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("List versions with: keyway history %s", opts.Key)))
+		return fmt.Errorf("missing --to")
+	}
+	if opts.Key == "" && opts.At == "" {
+		deps.UI.Error("Specify a KEY with --to, or --at to roll back the whole environment")
+		return fmt.Errorf("missing rollback target")
+	}
+	if opts.Key != "" && opts.At != "" {
+		deps.UI.Error("--at rolls back the whole environment and cannot be combined with a KEY")
+		return fmt.Errorf("conflicting rollback target")
+	}
+
+	envName := normalizeEnvName(opts.EnvName)
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+
+	if opts.Key != "" {
+		return rollbackSecret(opts, envName, repo, client, deps)
+	}
+	return rollbackEnvironment(opts, envName, repo, client, deps)
+}
+
+// rollbackSecret restores a single secret to the version given by --to
+func rollbackSecret(opts RollbackOptions, envName, repo string, client api.APIClient, deps *Dependencies) error {
+	ctx := context.Background()
+
+	versionNumber, err := parseVersionNumber(opts.ToVersion)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	var secret *api.SecretItem
+	var versions []api.SecretVersion
+	fetch := func() error {
+		return deps.UI.Spin("Fetching history...", func() error {
+			var findErr error
+			secret, findErr = findSecretItem(ctx, client, repo, opts.Key, envName)
+			if findErr != nil {
+				return findErr
+			}
+			versions, findErr = client.GetSecretVersions(ctx, repo, secret.ID)
+			return findErr
+		})
+	}
+
+	err = fetch()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	var target *api.SecretVersion
+	for i := range versions {
+		if versions[i].VersionNumber == versionNumber {
+			target = &versions[i]
+			break
+		}
+	}
+	if target == nil {
+		deps.UI.Error(fmt.Sprintf("Version %d not found for %s", versionNumber, opts.Key))
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("List versions with: keyway history %s -e %s", opts.Key, envName)))
+		return fmt.Errorf("version not found")
+	}
+
+	deps.UI.Message("")
+	deps.UI.DiffChanged(fmt.Sprintf("%s → v%d", opts.Key, versionNumber))
+	deps.UI.Message("")
+
+	if opts.DryRun {
+		deps.UI.Info("Dry run: nothing restored")
+		return nil
+	}
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Restore %s to version %d in %s?", opts.Key, versionNumber, envName), false)
+		if !confirm {
+			deps.UI.Warn("Rollback aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	restore := func() error {
+		return deps.UI.Spin("Restoring...", func() error {
+			_, restoreErr := client.RestoreSecretVersion(ctx, repo, secret.ID, target.ID)
+			return restoreErr
+		})
+	}
+
+	err = restore()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = restore()
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	evictAgentCache(deps, repo, envName)
+
+	deps.UI.Success(fmt.Sprintf("Restored %s to version %d", opts.Key, versionNumber))
+	deps.UI.Outro(deps.UI.Dim(fmt.Sprintf("Undo with: keyway history %s -e %s", opts.Key, envName)))
+	return nil
+}
+
+// rollbackEnvironment restores every secret in an environment to its state at --at
+func rollbackEnvironment(opts RollbackOptions, envName, repo string, client api.APIClient, deps *Dependencies) error {
+	ctx := context.Background()
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	at, err := parseRollbackTime(opts.At, now)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Restore to: %s", deps.UI.Value(at.Local().Format(time.RFC3339))))
+
+	var plan *rollbackPlan
+	computePlan := func() error {
+		return deps.UI.Spin("Computing rollback...", func() error {
+			var planErr error
+			plan, planErr = planEnvironmentRollback(ctx, client, repo, envName, at)
+			return planErr
+		})
+	}
+
+	err = computePlan()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = computePlan()
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if len(plan.CreatedAfter) > 0 {
+		deps.UI.Warn(fmt.Sprintf("Created after that time, left in place: %s", strings.Join(plan.CreatedAfter, ", ")))
+	}
+	if len(plan.Unrecoverable) > 0 {
+		deps.UI.Warn(fmt.Sprintf("Value at that time no longer in history: %s", strings.Join(plan.Unrecoverable, ", ")))
+	}
+
+	if plan.isEmpty() {
+		deps.UI.Success(fmt.Sprintf("Nothing to roll back in %s", envName))
+		return nil
+	}
+
+	deps.UI.Message("")
+	deps.UI.Message("Will be restored:")
+	for _, v := range plan.Versions {
+		deps.UI.DiffChanged(fmt.Sprintf("%s → v%d", v.Key, v.VersionNumber))
+	}
+	for _, t := range plan.Trashed {
+		deps.UI.DiffAdded(fmt.Sprintf("%s (from trash)", t.Key))
+	}
+	deps.UI.Message("")
+
+	if opts.DryRun {
+		deps.UI.Info("Dry run: nothing restored")
+		return nil
+	}
+
+	total := len(plan.Versions) + len(plan.Trashed)
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Restore %d secret(s) in %s?", total, envName), false)
+		if !confirm {
+			deps.UI.Warn("Rollback aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	var failed []string
+	done := 0
+	restore := func() error {
+		return deps.UI.Spin("Restoring...", func() error {
+			// An expired session stops the restore, which resumes where it
+			// stopped after signing in again
+			for ; done < total; done++ {
+				var key string
+				var restoreErr error
+				if done < len(plan.Trashed) {
+					t := plan.Trashed[done]
+					key, restoreErr = t.Key, client.RestoreTrashedSecret(ctx, repo, t.ID)
+				} else {
+					v := plan.Versions[done-len(plan.Trashed)]
+					key = v.Key
+					_, restoreErr = client.RestoreSecretVersion(ctx, repo, v.SecretID, v.VersionID)
+				}
+				if isAuthError(restoreErr) {
+					return restoreErr
+				}
+				if restoreErr != nil {
+					failed = append(failed, fmt.Sprintf("%s (%v)", key, restoreErr))
+				}
+			}
+			return nil
+		})
+	}
+
+	err = restore()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = restore()
+	}
+	if done > len(failed) {
+		evictAgentCache(deps, repo, envName)
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if len(failed) > 0 {
+		deps.UI.Error(fmt.Sprintf("Failed to restore %d secret(s): %s", len(failed), strings.Join(failed, ", ")))
+		return fmt.Errorf("rollback incomplete")
+	}
+
+	deps.UI.Success(fmt.Sprintf("Restored %d secret(s) in %s", total, envName))
+	deps.UI.Outro("")
+	return nil
+}
+
+// planEnvironmentRollback works out, for each secret in envName, which version
+// holds its value at time at.
+//
+// A version is saved when its value gets replaced, so the value current at a
+// given time is the oldest version saved after that time.
+func planEnvironmentRollback(ctx context.Context, client api.APIClient, repo, envName string, at time.Time) (*rollbackPlan, error) {
+	items, err := client.ListSecrets(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &rollbackPlan{}
+	active := make(map[string]bool)
+
+	for _, item := range items {
+		if item.Environment != envName {
+			continue
+		}
+		active[item.Key] = true
+
+		if !item.UpdatedAt.After(at) {
+			continue
+		}
+		if item.CreatedAt.After(at) {
+			plan.CreatedAfter = append(plan.CreatedAfter, item.Key)
+			continue
+		}
+
+		versions, err := client.GetSecretVersions(ctx, repo, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch history for %s: %w", item.Key, err)
+		}
+
+		var target *api.SecretVersion
+		oldest := 0
+		for i := range versions {
+			v := &versions[i]
+			if oldest == 0 || v.VersionNumber < oldest {
+				oldest = v.VersionNumber
+			}
+			if v.CreatedAt.After(at) && (target == nil || v.CreatedAt.Before(target.CreatedAt)) {
+				target = v
+			}
+		}
+
+		if target == nil {
+			// Touched (e.g. environment renamed) but value unchanged since
+			continue
+		}
+		// Every remaining version is newer than at and older ones were pruned:
+		// the value at that time may be one of the pruned ones
+		if target.VersionNumber == oldest && oldest > 1 {
+			plan.Unrecoverable = append(plan.Unrecoverable, item.Key)
+			continue
+		}
+
+		plan.Versions = append(plan.Versions, rollbackVersion{
+			Key:           item.Key,
+			SecretID:      item.ID,
+			VersionID:     target.ID,
+			VersionNumber: target.VersionNumber,
+		})
+	}
+
+	trashed, err := client.ListTrashedSecrets(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trashed {
+		if t.Environment != envName || !t.DeletedAt.After(at) || active[t.Key] {
+			continue
+		}
+		// A key can be deleted more than once; bring back the first deletion after at
+		replaced := false
+		for i, existing := range plan.Trashed {
+			if existing.Key == t.Key {
+				if t.DeletedAt.Before(existing.DeletedAt) {
+					plan.Trashed[i] = t
+				}
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			plan.Trashed = append(plan.Trashed, t)
+		}
+	}
+
+	sort.Slice(plan.Versions, func(i, j int) bool { return plan.Versions[i].Key < plan.Versions[j].Key })
+	sort.Slice(plan.Trashed, func(i, j int) bool { return plan.Trashed[i].Key < plan.Trashed[j].Key })
+	sort.Strings(plan.CreatedAfter)
+	sort.Strings(plan.Unrecoverable)
+
+	return plan, nil
+}
+
+// parseVersionNumber parses "3" or "v3"
+func parseVersionNumber(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "v"))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid version: %s", value)
+	}
+	return n, nil
+}
+
+// parseRollbackTime parses an absolute time (RFC 3339, "YYYY-MM-DD HH:MM", "YYYY-MM-DD")
+// or a duration ago ("90m", "2h", "3d")
+func parseRollbackTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("time is required")
+	}
+
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid time: %s", value)
+		}
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %s (use RFC 3339, YYYY-MM-DD, or a duration like 2h or 3d)", value)
+}
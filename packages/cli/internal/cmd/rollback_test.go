@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestParseVersionNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{"3", 3, false},
+		{"v3", 3, false},
+		{"V12", 12, false},
+		{"0", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := parseVersionNumber(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVersionNumber(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if n != tt.expected {
+				t.Errorf("parseVersionNumber(%q) = %d, want %d", tt.input, n, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRollbackTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"2h", now.Add(-2 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"3d", now.AddDate(0, 0, -3), false},
+		{"2026-03-01T08:30:00Z", time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2026-03-01 08:30", time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC), false},
+		{"-2h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseRollbackTime(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRollbackTime(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("parseRollbackTime(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// rollbackFixture sets up a vault where a bad push happened at 12:00
+func rollbackFixture(apiMock *MockAPIClient) time.Time {
+	day := func(h int) time.Time { return time.Date(2026, 3, 10, h, 0, 0, 0, time.UTC) }
+
+	apiMock.SecretItems = []api.SecretItem{
+		// Changed twice after 11:00: roll back to the oldest version saved after 11:00
+		{ID: "s-api", Key: "API_KEY", Environment: "production", CreatedAt: day(8), UpdatedAt: day(13)},
+		// Untouched since 9:00
+		{ID: "s-db", Key: "DB_URL", Environment: "production", CreatedAt: day(8), UpdatedAt: day(9)},
+		// Created by the bad push
+		{ID: "s-new", Key: "NEW_KEY", Environment: "production", CreatedAt: day(12), UpdatedAt: day(12)},
+		// Another environment is ignored
+		{ID: "s-stg", Key: "API_KEY", Environment: "staging", CreatedAt: day(8), UpdatedAt: day(13)},
+	}
+	apiMock.SecretVersions = map[string][]api.SecretVersion{
+		"s-api": {
+			{ID: "v3", VersionNumber: 3, CreatedAt: day(13)},
+			{ID: "v2", VersionNumber: 2, CreatedAt: day(12)},
+			{ID: "v1", VersionNumber: 1, CreatedAt: day(10)},
+		},
+	}
+	apiMock.TrashedSecrets = []api.TrashedSecret{
+		// Pruned by the bad push
+		{ID: "t-pruned", Key: "PRUNED_KEY", Environment: "production", DeletedAt: day(12)},
+		// Deleted long before
+		{ID: "t-old", Key: "ANCIENT_KEY", Environment: "production", DeletedAt: day(7)},
+		// Deleted in another environment
+		{ID: "t-stg", Key: "PRUNED_KEY", Environment: "staging", DeletedAt: day(12)},
+	}
+	return day(11)
+}
+
+func TestPlanEnvironmentRollback(t *testing.T) {
+	apiMock := &MockAPIClient{}
+	at := rollbackFixture(apiMock)
+
+	plan, err := planEnvironmentRollback(context.Background(), apiMock, "owner/repo", "production", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(plan.Versions) != 1 || plan.Versions[0].Key != "API_KEY" || plan.Versions[0].VersionID != "v2" {
+		t.Errorf("expected API_KEY restored to v2, got %+v", plan.Versions)
+	}
+	if len(plan.Trashed) != 1 || plan.Trashed[0].ID != "t-pruned" {
+		t.Errorf("expected PRUNED_KEY restored from trash, got %+v", plan.Trashed)
+	}
+	if len(plan.CreatedAfter) != 1 || plan.CreatedAfter[0] != "NEW_KEY" {
+		t.Errorf("expected NEW_KEY reported as created after, got %v", plan.CreatedAfter)
+	}
+	if len(plan.Unrecoverable) != 0 {
+		t.Errorf("expected nothing unrecoverable, got %v", plan.Unrecoverable)
+	}
+}
+
+func TestPlanEnvironmentRollback_PrunedHistory(t *testing.T) {
+	at := time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC)
+	apiMock := &MockAPIClient{
+		SecretItems: []api.SecretItem{
+			{ID: "s1", Key: "HOT_KEY", Environment: "production", CreatedAt: at.Add(-time.Hour), UpdatedAt: at.Add(3 * time.Hour)},
+		},
+		SecretVersions: map[string][]api.SecretVersion{
+			// Versions 1-4 were pruned; all remaining ones are newer than at
+			"s1": {
+				{ID: "v6", VersionNumber: 6, CreatedAt: at.Add(2 * time.Hour)},
+				{ID: "v5", VersionNumber: 5, CreatedAt: at.Add(time.Hour)},
+			},
+		},
+	}
+
+	plan, err := planEnvironmentRollback(context.Background(), apiMock, "owner/repo", "production", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Versions) != 0 {
+		t.Errorf("expected no restorable versions, got %+v", plan.Versions)
+	}
+	if len(plan.Unrecoverable) != 1 || plan.Unrecoverable[0] != "HOT_KEY" {
+		t.Errorf("expected HOT_KEY unrecoverable, got %v", plan.Unrecoverable)
+	}
+}
+
+func TestPlanEnvironmentRollback_SkipsTrashedKeyThatExists(t *testing.T) {
+	at := time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC)
+	apiMock := &MockAPIClient{
+		SecretItems: []api.SecretItem{
+			{ID: "s1", Key: "API_KEY", Environment: "production", CreatedAt: at.Add(-time.Hour), UpdatedAt: at.Add(-time.Hour)},
+		},
+		TrashedSecrets: []api.TrashedSecret{
+			{ID: "t1", Key: "API_KEY", Environment: "production", DeletedAt: at.Add(time.Hour)},
+		},
+	}
+
+	plan, err := planEnvironmentRollback(context.Background(), apiMock, "owner/repo", "production", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Trashed) != 0 {
+		t.Errorf("expected trashed duplicate to be skipped, got %+v", plan.Trashed)
+	}
+}
+
+func TestRunRollbackWithDeps_SingleSecret(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.SecretItems = []api.SecretItem{{ID: "s1", Key: "API_KEY", Environment: "production"}}
+	apiMock.SecretVersions = map[string][]api.SecretVersion{
+		"s1": {{ID: "v2", VersionNumber: 2}, {ID: "v1", VersionNumber: 1}},
+	}
+
+	err := runRollbackWithDeps(RollbackOptions{Key: "API_KEY", EnvName: "prod", ToVersion: "v1", Yes: true}, deps)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.RestoredVersions) != 1 || apiMock.RestoredVersions[0] != "s1@v1" {
+		t.Errorf("expected s1@v1 restored, got %v", apiMock.RestoredVersions)
+	}
+	if len(uiMock.SuccessCalls) == 0 {
+		t.Error("expected Success to be called")
+	}
+}
+
+func TestRunRollbackWithDeps_VersionNotFound(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.SecretItems = []api.SecretItem{{ID: "s1", Key: "API_KEY", Environment: "development"}}
+	apiMock.SecretVersions = map[string][]api.SecretVersion{
+		"s1": {{ID: "v1", VersionNumber: 1}},
+	}
+
+	err := runRollbackWithDeps(RollbackOptions{Key: "API_KEY", EnvName: "development", ToVersion: "5", Yes: true}, deps)
+	if err == nil || err.Error() != "version not found" {
+		t.Fatalf("expected version not found error, got %v", err)
+	}
+	if len(apiMock.RestoredVersions) != 0 {
+		t.Error("expected nothing restored")
+	}
+}
+
+func TestRunRollbackWithDeps_MissingTarget(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RollbackOptions
+	}{
+		{"key without --to", RollbackOptions{Key: "API_KEY"}},
+		{"nothing", RollbackOptions{}},
+		{"key with --at", RollbackOptions{Key: "API_KEY", ToVersion: "1", At: "2h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, _, uiMock, _, _, _ := NewTestDepsWithEnv()
+			if err := runRollbackWithDeps(tt.opts, deps); err == nil {
+				t.Fatal("expected error")
+			}
+			if len(uiMock.ErrorCalls) == 0 {
+				t.Error("expected UI.Error to be called")
+			}
+		})
+	}
+}
+
+func TestRunRollbackWithDeps_Environment(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	at := rollbackFixture(apiMock)
+
+	opts := RollbackOptions{EnvName: "production", At: "1h", Now: at.Add(time.Hour), Yes: true}
+	if err := runRollbackWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(apiMock.RestoredVersions) != 1 || apiMock.RestoredVersions[0] != "s-api@v2" {
+		t.Errorf("expected s-api@v2 restored, got %v", apiMock.RestoredVersions)
+	}
+	if len(apiMock.RestoredTrash) != 1 || apiMock.RestoredTrash[0] != "t-pruned" {
+		t.Errorf("expected t-pruned restored, got %v", apiMock.RestoredTrash)
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected warning about NEW_KEY")
+	}
+}
+
+func TestRunRollbackWithDeps_EnvironmentDryRun(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	at := rollbackFixture(apiMock)
+
+	opts := RollbackOptions{EnvName: "production", At: at.Format(time.RFC3339), DryRun: true}
+	if err := runRollbackWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.RestoredVersions) != 0 || len(apiMock.RestoredTrash) != 0 {
+		t.Error("expected nothing restored in dry-run mode")
+	}
+}
+
+func TestRunRollbackWithDeps_RequiresConfirmationNonInteractive(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	at := rollbackFixture(apiMock)
+
+	opts := RollbackOptions{EnvName: "production", At: at.Format(time.RFC3339)}
+	if err := runRollbackWithDeps(opts, deps); err == nil {
+		t.Fatal("expected confirmation error")
+	}
+	if len(apiMock.RestoredVersions) != 0 {
+		t.Error("expected nothing restored without confirmation")
+	}
+}
+
+func TestRunRollbackWithDeps_PartialFailure(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	at := rollbackFixture(apiMock)
+	apiMock.RestoreVersionError = errors.New("forbidden")
+
+	opts := RollbackOptions{EnvName: "production", At: at.Format(time.RFC3339), Yes: true}
+	err := runRollbackWithDeps(opts, deps)
+	if err == nil || err.Error() != "rollback incomplete" {
+		t.Fatalf("expected rollback incomplete error, got %v", err)
+	}
+	if len(apiMock.RestoredTrash) != 1 {
+		t.Error("expected trash restore to still go through")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/keywaysh/cli/internal/auth"
 	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/git"
+	"github.com/keywaysh/cli/internal/injector"
 	"github.com/keywaysh/cli/internal/ui"
 	"github.com/keywaysh/cli/internal/version"
 	"github.com/pkg/browser"
@@ -186,8 +188,15 @@ func printCustomHelp(cmd *cobra.Command) {
 	fmt.Printf("    %s           %s\n", cyan("keyway init"), "Initialize vault for this repo")
 	fmt.Printf("    %s           %s\n", cyan("keyway push"), "Upload secrets to vault")
 	fmt.Printf("    %s           %s\n", cyan("keyway pull"), "Download secrets from vault")
+	fmt.Printf("    %s         %s\n", cyan("keyway status"), "Show local env files out of sync")
 	fmt.Printf("    %s            %s\n", cyan("keyway set"), "Set a single secret in vault")
 	fmt.Printf("    %s            %s\n", cyan("keyway run"), "Run command with injected secrets (Zero-Trust)")
+	fmt.Printf("    %s          %s\n", cyan("keyway shell"), "Open a shell with injected secrets")
+	fmt.Printf("    %s         %s\n", cyan("keyway render"), "Render a config template with secrets")
+	fmt.Printf("    %s            %s\n", cyan("keyway e2e"), "Manage end-to-end encryption")
+	fmt.Printf("    %s           %s\n", cyan("keyway seal"), "Encrypt an env file so it can be committed")
+	fmt.Printf("    %s         %s\n", cyan("keyway unseal"), "Decrypt a sealed env file (offline)")
+	fmt.Printf("    %s            %s\n", cyan("keyway env"), "Manage vault environments")
 	fmt.Printf("    %s           %s\n", cyan("keyway login"), "Sign in with GitHub")
 	fmt.Println()
 
@@ -202,7 +211,14 @@ func printCustomHelp(cmd *cobra.Command) {
 	// Utilities
 	fmt.Printf("  %s\n", bold("Utilities:"))
 	fmt.Printf("    %s           %s\n", cyan("keyway diff"), "Compare secrets between environments")
+	fmt.Printf("    %s        %s\n", cyan("keyway promote"), "Copy secrets between environments")
+	fmt.Printf("    %s        %s\n", cyan("keyway history"), "Show previous versions of a secret")
+	fmt.Printf("    %s       %s\n", cyan("keyway rollback"), "Restore a secret or environment")
+	fmt.Printf("    %s          %s\n", cyan("keyway audit"), "Show the vault activity log")
+	fmt.Printf("    %s    %s\n", cyan("keyway lock verify"), "Check the vault matches .keyway.lock")
 	fmt.Printf("    %s           %s\n", cyan("keyway scan"), "Scan codebase for leaked secrets")
+	fmt.Printf("    %s          %s\n", cyan("keyway hooks"), "Block commits that add secrets")
+	fmt.Printf("    %s          %s\n", cyan("keyway agent"), "Cache secrets locally for faster runs")
 	fmt.Printf("    %s         %s\n", cyan("keyway doctor"), "Check your setup")
 	fmt.Printf("    %s         %s\n", cyan("keyway logout"), "Clear stored credentials")
 	fmt.Println()
@@ -234,6 +250,14 @@ func Execute(ver string) error {
 	// Execute the command
 	err := rootCmd.Execute()
 
+	// A command run by keyway failed, or a command already reported why it
+	// fails: the exit status says it all
+	var exitErr *injector.ExitError
+	var status exitStatus
+	if errors.As(err, &exitErr) || errors.As(err, &status) {
+		return err
+	}
+
 	// Display error and help for unknown commands
 	if err != nil {
 		red := color.New(color.FgRed).SprintFunc()
@@ -256,6 +280,28 @@ func Execute(ver string) error {
 	return nil
 }
 
+// exitStatus fails a command that already reported why, with the status
+// keyway exits with and no further message
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// ExitCode returns the status keyway exits with after err: the command's own
+// status when keyway ran one, 1 otherwise
+func ExitCode(err error) int {
+	var exitErr *injector.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var status exitStatus
+	if errors.As(err, &status) {
+		return int(status)
+	}
+	return 1
+}
+
 func displayUpdateNotice(info *version.UpdateInfo) {
 	// Skip update notice for self-hosted instances (no update command)
 	if info.UpdateCommand == "" {
@@ -288,6 +334,20 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(readmeCmd)
 	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(promoteCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(lockCmd)
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(shellCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(e2eCmd)
+	rootCmd.AddCommand(sealCmd)
+	rootCmd.AddCommand(unsealCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(hooksCmd)
 }
@@ -3,9 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/keywaysh/cli/internal/api"
-	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/injector"
 	"github.com/spf13/cobra"
 )
 
@@ -22,40 +24,175 @@ This is particularly useful for:
 - Using AI agents (Claude Code, Gemini CLI, Codex) safely: the agent runs the command but cannot see the secrets on disk.`,
 	Example: `  keyway run --env development -- npm run dev
   keyway run --env development -- python3 main.py
-  keyway run --env production -- ./deploy.sh`,
+  keyway run --env production -- ./deploy.sh
+  keyway run -e base -e development -e local-overrides -- npm run dev
+  keyway run --mask-output -- npm test
+  keyway run --clean-env --only 'DB_*' -- ./migrate.sh
+  keyway run --strip-prefix NEXT_PUBLIC_ --prefix VITE_ -- npm run build
+  keyway run --file-secret GCP_SA_JSON:GOOGLE_APPLICATION_CREDENTIALS -- terraform plan
+  keyway run --watch -- npm run dev
+  keyway run --exec -e production -- node server.js
+  keyway run --env-pipe -- docker compose up
+  keyway run --procfile Procfile.dev`,
 	RunE: runRunCmd,
 }
 
 func init() {
-	runCmd.Flags().StringP("env", "e", "development", "Environment name")
+	runCmd.Flags().StringArrayP("env", "e", []string{"development"}, "Environment name (repeat to layer environments, later ones win)")
+	runCmd.Flags().Bool("no-override", false, "Keep variables already set in the shell instead of replacing them with secrets")
+	runCmd.Flags().Bool("clean-env", false, "Don't inherit the shell environment, except PATH, HOME and a few other basics")
+	runCmd.Flags().StringSlice("keep", nil, "Variables to inherit with --clean-env, as globs (repeatable)")
+	runCmd.Flags().StringSlice("only", nil, "Inject only the secrets whose key matches one of these globs (repeatable)")
+	runCmd.Flags().StringSlice("exclude", nil, "Don't inject the secrets whose key matches one of these globs (repeatable)")
+	runCmd.Flags().String("strip-prefix", "", "Remove this prefix from the keys that have it")
+	runCmd.Flags().String("prefix", "", "Add this prefix to every key")
+	runCmd.Flags().Bool("mask-output", false, "Replace secret values in the command's output with ***KEY***")
+	runCmd.Flags().StringArray("file-secret", nil, "Write secret KEY to a temporary file and set VAR to its path, as KEY:VAR (repeatable)")
+	runCmd.Flags().Bool("env-pipe", false, "Pass secrets as an env file served from a pipe, and add --env-file to docker and compose commands")
+	runCmd.Flags().Bool("no-agent", false, "Pull secrets from the vault even when a keyway agent is running")
+	runCmd.Flags().String("procfile", "", "Run the processes of a Procfile side by side, each with its own environment and filter")
+	runCmd.Flags().Bool("exec", false, "Replace keyway with the command instead of running it as a child (not on Windows)")
+	runCmd.Flags().BoolP("watch", "w", false, "Restart the command when secrets change in the vault")
+	runCmd.Flags().Duration("interval", runWatchInterval, "How often --watch checks the vault")
+	runCmd.Flags().Duration("grace", injector.DefaultGracePeriod, "How long --watch and --procfile let commands shut down before killing them")
 }
 
+// runWatchInterval is how often --watch checks the vault for changes
+const runWatchInterval = 10 * time.Second
+
 // RunOptions contains the parsed flags for the run command
 type RunOptions struct {
 	EnvName    string
 	EnvFlagSet bool
 	Command    string
 	Args       []string
+
+	// BaseEnvs are layered under EnvName, in order
+	BaseEnvs []string
+	// NoOverride keeps variables already set in the parent environment
+	NoOverride bool
+	// CleanEnv inherits only basic variables, plus Keep, from the parent
+	CleanEnv bool
+	Keep     []string
+	// Only, Exclude, StripPrefix and Prefix select and rename the secrets
+	Only        []string
+	Exclude     []string
+	StripPrefix string
+	Prefix      string
+	// MaskOutput redacts secret values from the command's output
+	MaskOutput bool
+	// FileSecrets are KEY:VAR specs of secrets to pass as temporary files
+	FileSecrets []string
+
+	// EnvPipe passes secrets through a pipe in $KEYWAY_ENV_FILE instead of
+	// the environment
+	EnvPipe bool
+	// Exec replaces keyway with the command
+	Exec bool
+	// NoAgent skips the keyway agent and pulls from the vault
+	NoAgent bool
+	// Procfile runs the processes of this file instead of Command
+	Procfile string
+	// Watch restarts the command when secrets change
+	Watch bool
+	// Interval overrides runWatchInterval
+	Interval time.Duration
+	// GracePeriod is how long the command gets to exit before it is killed
+	GracePeriod time.Duration
+	// Context stops --watch polling when cancelled (defaults to context.Background)
+	Context context.Context
 }
 
 // runRunCmd is the entry point for the run command (uses default dependencies)
 func runRunCmd(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
+	procfile, _ := cmd.Flags().GetString("procfile")
+	if procfile == "" && len(args) == 0 {
 		return fmt.Errorf("command required")
 	}
+	if procfile != "" && len(args) > 0 {
+		return fmt.Errorf("--procfile runs the commands of the Procfile: no command can be given")
+	}
 
 	opts := RunOptions{
 		EnvFlagSet: cmd.Flags().Changed("env"),
-		Command:    args[0],
-		Args:       args[1:],
+		Procfile:   procfile,
+	}
+	if len(args) > 0 {
+		opts.Command, opts.Args = args[0], args[1:]
 	}
-	opts.EnvName, _ = cmd.Flags().GetString("env")
+	envNames, _ := cmd.Flags().GetStringArray("env")
+	opts.BaseEnvs, opts.EnvName = envNamesFlag(envNames)
+	opts.NoOverride, _ = cmd.Flags().GetBool("no-override")
+	opts.CleanEnv, _ = cmd.Flags().GetBool("clean-env")
+	opts.Keep, _ = cmd.Flags().GetStringSlice("keep")
+	opts.Only, _ = cmd.Flags().GetStringSlice("only")
+	opts.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	opts.StripPrefix, _ = cmd.Flags().GetString("strip-prefix")
+	opts.Prefix, _ = cmd.Flags().GetString("prefix")
+	opts.MaskOutput, _ = cmd.Flags().GetBool("mask-output")
+	opts.FileSecrets, _ = cmd.Flags().GetStringArray("file-secret")
+	opts.EnvPipe, _ = cmd.Flags().GetBool("env-pipe")
+	opts.Exec, _ = cmd.Flags().GetBool("exec")
+	opts.NoAgent, _ = cmd.Flags().GetBool("no-agent")
+	opts.Watch, _ = cmd.Flags().GetBool("watch")
+	opts.Interval, _ = cmd.Flags().GetDuration("interval")
+	opts.GracePeriod, _ = cmd.Flags().GetDuration("grace")
 
 	return runRunWithDeps(opts, defaultDeps)
 }
 
 // runRunWithDeps is the testable version of runRun
 func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
+	fileSecrets := make([]injector.FileSecret, 0, len(opts.FileSecrets))
+	for _, spec := range opts.FileSecrets {
+		f, err := injector.ParseFileSecret(spec)
+		if err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		fileSecrets = append(fileSecrets, f)
+	}
+	if opts.Exec {
+		conflicts := []struct {
+			flag string
+			set  bool
+		}{
+			{"--watch", opts.Watch},
+			{"--mask-output", opts.MaskOutput},
+			{"--file-secret", len(opts.FileSecrets) > 0},
+			{"--env-pipe", opts.EnvPipe},
+		}
+		for _, c := range conflicts {
+			if c.set {
+				deps.UI.Error(fmt.Sprintf("--exec can't be combined with %s: keyway is gone once the command starts", c.flag))
+				return fmt.Errorf("--exec and %s are mutually exclusive", c.flag)
+			}
+		}
+	}
+	filter := keyFilter{Only: opts.Only, Exclude: opts.Exclude, StripPrefix: opts.StripPrefix, Prefix: opts.Prefix}
+	if err := filter.validate(); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if opts.Procfile != "" {
+		conflicts := []struct {
+			flag string
+			set  bool
+		}{
+			{"--exec", opts.Exec},
+			{"--watch", opts.Watch},
+			{"--file-secret", len(opts.FileSecrets) > 0},
+			{"--env-pipe", opts.EnvPipe},
+		}
+		for _, c := range conflicts {
+			if c.set {
+				deps.UI.Error(fmt.Sprintf("--procfile can't be combined with %s", c.flag))
+				return fmt.Errorf("--procfile and %s are mutually exclusive", c.flag)
+			}
+		}
+		return runProcfileWithDeps(opts, deps)
+	}
+
 	// 1. Detect Repo
 	repo, err := deps.Git.DetectRepo()
 	if err != nil {
@@ -74,6 +211,13 @@ func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
 	client := deps.APIFactory.NewClient(token)
 	ctx := context.Background()
 
+	// A running agent serves environments it already holds
+	var agentClient AgentAPIClient
+	if !opts.NoAgent && deps.Agent != nil && deps.Agent.Available() {
+		agentClient = deps.Agent.WrapAPI(client)
+		client = agentClient
+	}
+
 	// 4. Determine Environment
 	envName := opts.EnvName
 
@@ -105,19 +249,33 @@ func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
 		envName = selected
 	}
 
-	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+	envNames := append(append([]string{}, opts.BaseEnvs...), envName)
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(strings.Join(envNames, " → "))))
 
-	// 5. Fetch Secrets
-	var vaultContent string
-	err = deps.UI.Spin("Fetching secrets...", func() error {
-		resp, err := client.PullSecrets(ctx, repo, envName)
-		if err != nil {
+	// 5. Fetch Secrets, layering environments and resolving keyway:// references
+	var secrets map[string]string
+	var refs int
+	fetch := func() error {
+		return deps.UI.Spin("Fetching secrets...", func() error {
+			var err error
+			secrets, refs, err = fetchEnvLayers(ctx, client, repo, envNames)
 			return err
-		}
-		vaultContent = resp.Content
-		return nil
-	})
+		})
+	}
 
+	err = fetch()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		if agentClient != nil {
+			agentClient = deps.Agent.WrapAPI(client)
+			client = agentClient
+		}
+		err = fetch()
+	}
 	if err != nil {
 		if apiErr, ok := err.(*api.APIError); ok {
 			deps.UI.Error(apiErr.Error())
@@ -127,10 +285,178 @@ func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
 		return err
 	}
 
-	// 6. Parse Secrets
-	secrets := env.Parse(vaultContent)
+	if agentClient != nil && agentClient.Served() > 0 {
+		deps.UI.Step("Served by the keyway agent")
+	}
+	if refs > 0 {
+		deps.UI.Step(fmt.Sprintf("Resolved %s reference(s)", deps.UI.Value(refs)))
+	}
+	fetched := len(secrets)
+	secrets = filter.apply(secrets)
+	if filter.active() {
+		deps.UI.Step(fmt.Sprintf("Keys: %s of %d", deps.UI.Value(len(secrets)), fetched))
+	}
+	for _, f := range fileSecrets {
+		if _, ok := secrets[f.Key]; !ok {
+			if filter.active() {
+				deps.UI.Error(fmt.Sprintf("%s is not among the injected keys - check --only, --exclude and the prefixes", f.Key))
+			} else {
+				deps.UI.Error(fmt.Sprintf("%s is not in %s", f.Key, envName))
+			}
+			return fmt.Errorf("secret %s not found", f.Key)
+		}
+		deps.UI.Step(fmt.Sprintf("File: %s → %s", deps.UI.Value(f.Key), deps.UI.Value("$"+f.Var)))
+	}
 	deps.UI.Success(fmt.Sprintf("Injected %d secrets", len(secrets)))
+	if opts.EnvPipe {
+		deps.UI.Step(fmt.Sprintf("Env file: %s", deps.UI.Value("$"+injector.EnvFileVar)))
+	}
+
+	runOpts := injector.Options{
+		NoOverride:  opts.NoOverride,
+		CleanEnv:    opts.CleanEnv,
+		Keep:        opts.Keep,
+		MaskOutput:  opts.MaskOutput,
+		FileSecrets: fileSecrets,
+		EnvPipe:     opts.EnvPipe,
+		GracePeriod: opts.GracePeriod,
+	}
+
+	// 6. Execute Command
+	if opts.Exec {
+		return deps.CmdRunner.Exec(opts.Command, opts.Args, secrets, runOpts)
+	}
+	if !opts.Watch {
+		return deps.CmdRunner.RunCommand(opts.Command, opts.Args, secrets, runOpts)
+	}
+
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	watchCtx, stop := context.WithCancel(parent)
+
+	w := &runWatcher{
+		deps:      deps,
+		client:    client,
+		repo:      repo,
+		envNames:  envNames,
+		interval:  opts.Interval,
+		filter:    filter,
+		secrets:   secrets,
+		revisions: make(map[string]string),
+		contents:  make(map[string]string),
+	}
+	updates := make(chan map[string]string)
+	watching := make(chan struct{})
+	go func() {
+		w.run(watchCtx, updates)
+		close(watching)
+	}()
+
+	err = deps.CmdRunner.RunCommandWatched(opts.Command, opts.Args, secrets, runOpts, updates)
+	stop()
+	<-watching
+	return err
+}
+
+// runWatcher polls the vault for run --watch and sends the new secrets when
+// they change, so the command can be restarted with them
+type runWatcher struct {
+	deps      *Dependencies
+	client    api.APIClient
+	repo      string
+	envNames  []string
+	interval  time.Duration
+	filter    keyFilter
+	secrets   map[string]string // as injected, after filter
+	revisions map[string]string // last revision seen, by environment
+	contents  map[string]string // last content pulled, by environment
+}
+
+// run polls until ctx is cancelled. Polls are conditional on the last
+// revision, so an unchanged vault costs a 304. Errors are reported and
+// retried: a network blip must not stop a dev server.
+func (w *runWatcher) run(ctx context.Context, updates chan<- map[string]string) {
+	interval := w.interval
+	if interval <= 0 {
+		interval = runWatchInterval
+	}
+	w.deps.UI.Info(fmt.Sprintf("Watching %s for changes (every %s)...", strings.Join(w.envNames, ", "), interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		next, err := w.poll(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.deps.UI.Warn(fmt.Sprintf("Failed to check for changes: %s", err.Error()))
+			}
+			continue
+		}
+		if next == nil {
+			continue
+		}
+		next = w.filter.apply(next)
+
+		changes := compareSecrets("before", "after", w.secrets, next, false)
+		if len(changes.OnlyInEnv1) == 0 && len(changes.OnlyInEnv2) == 0 && len(changes.Different) == 0 {
+			continue
+		}
+		w.deps.UI.Message("")
+		w.deps.UI.Message(fmt.Sprintf("%s %s", w.deps.UI.Dim(time.Now().Format("15:04:05")), "Secrets changed, restarting:"))
+		for _, key := range changes.OnlyInEnv2 {
+			w.deps.UI.DiffAdded(key)
+		}
+		for _, entry := range changes.Different {
+			w.deps.UI.DiffChanged(fmt.Sprintf("%s %s", entry.Key, w.deps.UI.Dim(fmt.Sprintf("%s → %s", entry.Preview1, entry.Preview2))))
+		}
+		for _, key := range changes.OnlyInEnv1 {
+			w.deps.UI.DiffRemoved(key)
+		}
 
-	// 7. Execute Command
-	return deps.CmdRunner.RunCommand(opts.Command, opts.Args, secrets)
-}
\ No newline at end of file
+		select {
+		case updates <- next:
+			w.secrets = next
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poll checks every environment for changes and returns the new layered
+// secrets, or nil when nothing changed
+func (w *runWatcher) poll(ctx context.Context) (map[string]string, error) {
+	changed := false
+	for _, envName := range w.envNames {
+		resp, err := w.client.PullSecretsIfChanged(ctx, w.repo, envName, w.revisions[envName])
+		if err != nil && isAuthError(err) {
+			newToken, authErr := handleAuthError(err, w.deps)
+			if authErr != nil {
+				return nil, authErr
+			}
+			w.client = w.deps.APIFactory.NewClient(newToken)
+			resp, err = w.client.PullSecretsIfChanged(ctx, w.repo, envName, w.revisions[envName])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			continue
+		}
+		w.revisions[envName] = resp.Revision
+		w.contents[envName] = resp.Content
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	next, _, err := layerContents(ctx, w.client, w.repo, w.envNames, w.contents)
+	return next, err
+}
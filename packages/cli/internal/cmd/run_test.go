@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/injector"
 )
 
 func TestRunRunWithDeps_Success(t *testing.T) {
@@ -235,3 +239,246 @@ func TestRunRunWithDeps_MultipleArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestRunRunWithDeps_LayeredEnvironments(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+
+	apiMock.PullByEnv = map[string]string{
+		"base":            "LOG_LEVEL=info\nAPI_URL=https://api\nDB_URL=postgres://base",
+		"development":     "DB_URL=postgres://dev\nDEBUG=1",
+		"local-overrides": "LOG_LEVEL=debug",
+	}
+
+	opts := RunOptions{
+		EnvName:    "local-overrides",
+		BaseEnvs:   []string{"base", "development"},
+		EnvFlagSet: true,
+		NoOverride: true,
+		MaskOutput: true,
+		Command:    "npm",
+	}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]string{
+		"LOG_LEVEL": "debug",
+		"API_URL":   "https://api",
+		"DB_URL":    "postgres://dev",
+		"DEBUG":     "1",
+	}
+	if len(cmdRunner.LastSecrets) != len(want) {
+		t.Fatalf("got %v, want %v", cmdRunner.LastSecrets, want)
+	}
+	for k, v := range want {
+		if cmdRunner.LastSecrets[k] != v {
+			t.Errorf("%s = %q, want %q", k, cmdRunner.LastSecrets[k], v)
+		}
+	}
+	if !cmdRunner.LastOptions.NoOverride || !cmdRunner.LastOptions.MaskOutput {
+		t.Errorf("expected flags to be passed to the runner, got %+v", cmdRunner.LastOptions)
+	}
+}
+
+func TestRunRunWithDeps_LayeredMissingEnvironment(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+
+	apiMock.PullByEnv = map[string]string{"development": "A=1"}
+
+	opts := RunOptions{EnvName: "development", BaseEnvs: []string{"base"}, EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error for a missing base environment")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected command not to run")
+	}
+}
+
+func TestEnvNamesFlag(t *testing.T) {
+	base, main := envNamesFlag([]string{"base", "development", "local"})
+	if main != "local" || len(base) != 2 || base[0] != "base" || base[1] != "development" {
+		t.Errorf("got base=%v main=%q", base, main)
+	}
+	if base, main := envNamesFlag([]string{"production"}); main != "production" || len(base) != 0 {
+		t.Errorf("got base=%v main=%q", base, main)
+	}
+}
+
+func TestRunRunWithDeps_WatchRestartsOnChange(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old-value\nKEEP=1", Revision: "r1"}
+	apiMock.PullIfChangedFn = func(revision string) (*api.PullSecretsResponse, error) {
+		switch revision {
+		case "":
+			return &api.PullSecretsResponse{Content: "API_KEY=old-value\nKEEP=1", Revision: "r1"}, nil
+		case "r1":
+			return &api.PullSecretsResponse{Content: "API_KEY=rotated-value\nKEEP=1", Revision: "r2"}, nil
+		}
+		return nil, nil
+	}
+	cmdRunner.WatchUpdates = 1
+
+	opts := RunOptions{
+		EnvName:     "development",
+		EnvFlagSet:  true,
+		Command:     "npm",
+		Watch:       true,
+		Interval:    time.Millisecond,
+		GracePeriod: time.Second,
+	}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cmdRunner.LastSecrets["API_KEY"] != "old-value" {
+		t.Errorf("expected the command to start with the current secrets, got %v", cmdRunner.LastSecrets)
+	}
+	if len(cmdRunner.Updates) != 1 || cmdRunner.Updates[0]["API_KEY"] != "rotated-value" || cmdRunner.Updates[0]["KEEP"] != "1" {
+		t.Fatalf("expected one restart with the rotated value, got %v", cmdRunner.Updates)
+	}
+	if cmdRunner.LastOptions.GracePeriod != time.Second {
+		t.Errorf("expected grace period to be passed, got %v", cmdRunner.LastOptions.GracePeriod)
+	}
+	if len(uiMock.DiffChangedCalls) != 1 || !strings.HasPrefix(uiMock.DiffChangedCalls[0], "API_KEY") {
+		t.Errorf("expected API_KEY to be reported as changed, got %v", uiMock.DiffChangedCalls)
+	}
+	for _, c := range uiMock.DiffChangedCalls {
+		if strings.Contains(c, "rotated-value") {
+			t.Errorf("diff must not print raw values: %q", c)
+		}
+	}
+}
+
+func TestRunRunWithDeps_FileSecrets(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "GCP_SA_JSON={}\nKUBECONFIG=apiVersion: v1"}
+
+	opts := RunOptions{
+		EnvName:     "production",
+		EnvFlagSet:  true,
+		Command:     "terraform",
+		FileSecrets: []string{"GCP_SA_JSON:GOOGLE_APPLICATION_CREDENTIALS", "KUBECONFIG"},
+	}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []injector.FileSecret{
+		{Key: "GCP_SA_JSON", Var: "GOOGLE_APPLICATION_CREDENTIALS"},
+		{Key: "KUBECONFIG", Var: "KUBECONFIG"},
+	}
+	if !reflect.DeepEqual(cmdRunner.LastOptions.FileSecrets, want) {
+		t.Errorf("got %+v, want %+v", cmdRunner.LastOptions.FileSecrets, want)
+	}
+}
+
+func TestRunRunWithDeps_FileSecretNotInVault(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret"}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "terraform", FileSecrets: []string{"GCP_SA_JSON:CREDS"}}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error for a file secret missing from the vault")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected command not to run")
+	}
+}
+
+func TestRunRunWithDeps_CleanEnvAndKeyFilters(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DB_URL=postgres://prod\nDB_PASSWORD=hunter2\nSTRIPE_KEY=sk_live_123"}
+
+	opts := RunOptions{
+		EnvName:    "production",
+		EnvFlagSet: true,
+		Command:    "./migrate.sh",
+		CleanEnv:   true,
+		Keep:       []string{"PGSSLMODE"},
+		Only:       []string{"DB_*"},
+		Prefix:     "APP_",
+	}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]string{"APP_DB_URL": "postgres://prod", "APP_DB_PASSWORD": "hunter2"}
+	if !reflect.DeepEqual(cmdRunner.LastSecrets, want) {
+		t.Errorf("got %v, want %v", cmdRunner.LastSecrets, want)
+	}
+	if !cmdRunner.LastOptions.CleanEnv || !reflect.DeepEqual(cmdRunner.LastOptions.Keep, []string{"PGSSLMODE"}) {
+		t.Errorf("expected clean env options to be passed to the runner, got %+v", cmdRunner.LastOptions)
+	}
+}
+
+func TestRunRunWithDeps_InvalidKeyPattern(t *testing.T) {
+	deps, _, _, _, cmdRunner, _ := NewTestDepsWithRunner()
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "npm", Only: []string{"DB_["}}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error for a malformed glob")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected command not to run")
+	}
+}
+
+func TestRunRunWithDeps_Exec(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret"}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "node", Args: []string{"server.js"}, Exec: true}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cmdRunner.Execed || cmdRunner.LastCommand != "node" || cmdRunner.LastSecrets["API_KEY"] != "secret" {
+		t.Errorf("expected node to be exec'd with secrets, got %+v", cmdRunner)
+	}
+}
+
+func TestRunRunWithDeps_ExecConflicts(t *testing.T) {
+	for name, opts := range map[string]RunOptions{
+		"watch":       {Watch: true},
+		"mask-output": {MaskOutput: true},
+		"file-secret": {FileSecrets: []string{"KEY"}},
+		"env-pipe":    {EnvPipe: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			deps, _, _, _, cmdRunner, _ := NewTestDepsWithRunner()
+			opts.EnvName, opts.EnvFlagSet, opts.Command, opts.Exec = "production", true, "node", true
+
+			if err := runRunWithDeps(opts, deps); err == nil {
+				t.Fatal("expected error")
+			}
+			if cmdRunner.LastCommand != "" {
+				t.Error("expected command not to run")
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if code := ExitCode(&injector.ExitError{Code: 42}); code != 42 {
+		t.Errorf("got %d, want 42", code)
+	}
+	if code := ExitCode(exitStatus(3)); code != 3 {
+		t.Errorf("got %d, want 3", code)
+	}
+	if code := ExitCode(errors.New("network error")); code != 1 {
+		t.Errorf("got %d, want 1", code)
+	}
+}
+
+func TestRunRunWithDeps_EnvPipe(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret"}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "docker", Args: []string{"compose", "up"}, EnvPipe: true}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cmdRunner.LastOptions.EnvPipe || cmdRunner.LastSecrets["API_KEY"] != "secret" {
+		t.Errorf("expected the secrets to be piped, got %+v", cmdRunner.LastOptions)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -28,14 +29,26 @@ type Finding struct {
 	Type    string `json:"type"`
 	Match   string `json:"match,omitempty"`
 	Preview string `json:"preview"`
+
+	// Commit, Author and Date identify the commit that introduced the
+	// secret, when scanning history
+	Commit string `json:"commit,omitempty"`
+	Author string `json:"author,omitempty"`
+	Date   string `json:"date,omitempty"`
 }
 
 // ScanResult represents the complete scan output
 type ScanResult struct {
-	FilesScanned int       `json:"filesScanned"`
-	Findings     []Finding `json:"findings"`
+	FilesScanned   int       `json:"filesScanned"`
+	CommitsScanned int       `json:"commitsScanned,omitempty"`
+	Findings       []Finding `json:"findings"`
 }
 
+// errStagedFindings fails scan --staged, and the commit of a pre-commit hook
+// with it, once the findings are reported: the exit status is all that is
+// left to say
+var errStagedFindings = exitStatus(1)
+
 // Default patterns to detect secrets
 var secretPatterns = []SecretPattern{
 	// AWS
@@ -209,7 +222,10 @@ Examples:
   keyway scan                    # Scan current directory
   keyway scan ./src              # Scan specific directory
   keyway scan --json             # Output as JSON (for CI)
-  keyway scan -e test -e mocks   # Exclude additional directories`,
+  keyway scan -e test -e mocks   # Exclude additional directories
+  keyway scan --history          # Scan every commit of the git history
+  keyway scan --history --since v1.2.0
+  keyway scan --staged           # Scan what the next commit adds (for hooks)`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runScan,
 }
@@ -218,11 +234,23 @@ func init() {
 	scanCmd.Flags().StringSliceP("exclude", "e", nil, "Additional directories/patterns to exclude")
 	scanCmd.Flags().Bool("json", false, "Output as JSON")
 	scanCmd.Flags().Bool("show-all", false, "Show all matches including potential false positives")
+	scanCmd.Flags().Bool("history", false, "Scan the files of every commit in the git history instead of those on disk")
+	scanCmd.Flags().String("since", "", "With --history, only scan the commits after this revision or date")
+	scanCmd.Flags().Bool("staged", false, "Scan only the lines added by staged changes, and fail when secrets are found")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
 	excludePatterns, _ := cmd.Flags().GetStringSlice("exclude")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	history, _ := cmd.Flags().GetBool("history")
+	since, _ := cmd.Flags().GetString("since")
+	staged, _ := cmd.Flags().GetBool("staged")
+	if since != "" && !history {
+		return fmt.Errorf("--since only applies to --history")
+	}
+	if staged && history {
+		return fmt.Errorf("--staged and --history are mutually exclusive")
+	}
 
 	// Determine scan path
 	scanPath := "."
@@ -256,17 +284,30 @@ func runScan(cmd *cobra.Command, args []string) error {
 	}
 
 	// Perform scan
-	var filesScanned int
+	var filesScanned, commitsScanned int
 	var findings []Finding
+	scan := func() error {
+		var scanErr error
+		if history {
+			commitsScanned, filesScanned, findings, scanErr = scanHistory(absPath, since, allExcludes)
+		} else if staged {
+			filesScanned, findings, scanErr = scanStaged(absPath, allExcludes)
+		} else {
+			filesScanned, findings, scanErr = scanDirectory(absPath, allExcludes)
+		}
+		return scanErr
+	}
 
 	if !jsonOutput {
-		err = ui.Spin("Scanning files...", func() error {
-			var scanErr error
-			filesScanned, findings, scanErr = scanDirectory(absPath, allExcludes)
-			return scanErr
-		})
+		message := "Scanning files..."
+		if history {
+			message = "Scanning git history..."
+		} else if staged {
+			message = "Scanning staged changes..."
+		}
+		err = ui.Spin(message, scan)
 	} else {
-		filesScanned, findings, err = scanDirectory(absPath, allExcludes)
+		err = scan()
 	}
 
 	if err != nil {
@@ -280,27 +321,48 @@ func runScan(cmd *cobra.Command, args []string) error {
 	analytics.Track(analytics.EventScan, map[string]interface{}{
 		"filesScanned":  filesScanned,
 		"findingsCount": len(findings),
+		"history":       history,
+		"staged":        staged,
 	})
 
 	// Output results
 	if jsonOutput {
 		result := ScanResult{
-			FilesScanned: filesScanned,
-			Findings:     findings,
+			FilesScanned:   filesScanned,
+			CommitsScanned: commitsScanned,
+			Findings:       findings,
 		}
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
-		return encoder.Encode(result)
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+		if staged && len(findings) > 0 {
+			return errStagedFindings
+		}
+		return nil
 	}
 
 	// Interactive output
-	ui.Message(ui.Dim(fmt.Sprintf("%d files scanned", filesScanned)))
+	if history {
+		ui.Message(ui.Dim(fmt.Sprintf("%d commits, %d file versions scanned", commitsScanned, filesScanned)))
+	} else if staged {
+		ui.Message(ui.Dim(fmt.Sprintf("%d staged files scanned", filesScanned)))
+	} else {
+		ui.Message(ui.Dim(fmt.Sprintf("%d files scanned", filesScanned)))
+	}
 	fmt.Println()
 
 	if len(findings) == 0 {
 		ui.Success("No secrets detected")
 		return nil
 	}
+	if history {
+		defer ui.Message(ui.Dim("Secrets in history stay exploitable after they are removed: rotate them"))
+	}
+	if staged {
+		defer ui.Message(ui.Dim("Move them to the vault with keyway set, or commit with --no-verify if they are false positives"))
+	}
 
 	ui.Warn(fmt.Sprintf("Found %d potential secret(s):", len(findings)))
 	fmt.Println()
@@ -315,11 +377,17 @@ func runScan(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s\n", ui.File(file))
 		for _, f := range fileFindings {
 			fmt.Printf("  │ Line %d: %s\n", f.Line, ui.Dim(f.Type))
+			if f.Commit != "" {
+				fmt.Printf("  │ %s %s\n", ui.Value(shortSHA(f.Commit)), ui.Dim(fmt.Sprintf("by %s, %s", f.Author, f.Date)))
+			}
 			fmt.Printf("  │ %s\n", f.Preview)
 		}
 		fmt.Println()
 	}
 
+	if staged {
+		return errStagedFindings
+	}
 	return nil
 }
 
@@ -381,40 +449,49 @@ func scanFile(path, relPath string) ([]Finding, error) {
 	}
 	defer file.Close()
 
+	return scanReader(file, relPath)
+}
+
+// scanReader scans the content of the file at relPath
+func scanReader(r io.Reader, relPath string) ([]Finding, error) {
 	var findings []Finding
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
 
 	for scanner.Scan() {
 		lineNum++
-		line := scanner.Text()
+		findings = append(findings, scanLine(scanner.Text(), lineNum, relPath)...)
+	}
 
-		// Skip empty lines and comments
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
+	return findings, scanner.Err()
+}
 
-		// Check each pattern
-		for _, pattern := range secretPatterns {
-			matches := pattern.Regex.FindAllString(line, -1)
-			for _, match := range matches {
-				// Skip common false positives
-				if isFalsePositive(match, line, relPath) {
-					continue
-				}
+// scanLine checks a line of the file at relPath against each pattern
+func scanLine(line string, lineNum int, relPath string) []Finding {
+	// Skip empty lines and comments
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") {
+		return nil
+	}
 
-				findings = append(findings, Finding{
-					File:    relPath,
-					Line:    lineNum,
-					Type:    pattern.Name,
-					Preview: maskSecret(match),
-				})
+	var findings []Finding
+	for _, pattern := range secretPatterns {
+		matches := pattern.Regex.FindAllString(line, -1)
+		for _, match := range matches {
+			// Skip common false positives
+			if isFalsePositive(match, line, relPath) {
+				continue
 			}
+
+			findings = append(findings, Finding{
+				File:    relPath,
+				Line:    lineNum,
+				Type:    pattern.Name,
+				Preview: maskSecret(match),
+			})
 		}
 	}
-
-	return findings, scanner.Err()
+	return findings
 }
 
 // maskSecret masks the middle of a secret, showing only first 4 and last 3 chars
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/git"
+)
+
+// maxScanSize is the size above which files are not scanned
+const maxScanSize = 1024 * 1024
+
+// scanHistory scans every version of the files that the git history of dir
+// introduced, and returns how many commits and file versions it scanned. Each
+// blob is scanned once, so unchanged files cost nothing from one commit to the
+// next, and a secret is reported once per file, with the commit that
+// introduced it.
+func scanHistory(dir, since string, excludes []string) (int, int, []Finding, error) {
+	commits, blobs, err := git.HistoryBlobs(dir, since)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	reader, err := git.NewBlobReader(dir)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer reader.Close()
+
+	var scanned int
+	var findings []Finding
+	reported := map[string]bool{}
+	for _, b := range blobs {
+		if excludedPath(b.Path, excludes) || binaryExtensions[strings.ToLower(path.Ext(b.Path))] {
+			continue
+		}
+		data, err := reader.Read(b.SHA, maxScanSize)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		// Skip large and binary files, which git recognizes by a NUL byte
+		// near the start
+		if data == nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+			continue
+		}
+
+		blobFindings, _ := scanReader(bytes.NewReader(data), b.Path)
+		scanned++
+		for _, f := range blobFindings {
+			key := f.File + "\x00" + f.Type + "\x00" + f.Preview
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			f.Commit, f.Author, f.Date = b.Commit.SHA, b.Commit.Author, b.Commit.Date
+			findings = append(findings, f)
+		}
+	}
+	return commits, scanned, findings, nil
+}
+
+// excludedPath reports whether a file path is in an excluded directory, as
+// scanDirectory would skip it
+func excludedPath(relPath string, excludes []string) bool {
+	dir := path.Dir(relPath)
+	if dir == "." {
+		return false
+	}
+	for _, exclude := range excludes {
+		if strings.HasPrefix(dir, exclude) {
+			return true
+		}
+		for _, name := range strings.Split(dir, "/") {
+			if name == exclude {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanHistory_FindsRemovedSecrets(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+			"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	token := "ghp_" + strings.Repeat("a", 36)
+
+	git("init", "-q")
+	write("config.js", "const token = '"+token+"'\n")
+	write("node_modules/lib/index.js", token+"\n")
+	git("add", "-A")
+	git("commit", "-qm", "add config")
+	// Editing the file keeps the secret: it is still reported once
+	write("config.js", "const token = '"+token+"'\nconst port = 3000\n")
+	git("commit", "-qam", "add port")
+	git("rm", "-q", "config.js")
+	git("commit", "-qm", "remove config")
+
+	commits, scanned, findings, err := scanHistory(dir, "", defaultExcludes)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if commits != 3 || scanned != 2 {
+		t.Errorf("expected 3 commits and 2 file versions, got %d and %d", commits, scanned)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.File != "config.js" || f.Type != "GitHub PAT" || f.Author != "Alice <alice@example.com>" || len(f.Commit) != 40 {
+		t.Errorf("unexpected finding %+v", f)
+	}
+}
+
+func TestExcludedPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"config.js", false},
+		{"build.go", false},
+		{"node_modules/lib/index.js", true},
+		{"packages/web/node_modules/lib/index.js", true},
+		{"dist/app.js", true},
+		{"src/app.js", false},
+	}
+	for _, tt := range tests {
+		if got := excludedPath(tt.path, defaultExcludes); got != tt.want {
+			t.Errorf("excludedPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
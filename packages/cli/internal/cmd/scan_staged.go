@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"path"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/git"
+)
+
+// scanStaged scans the lines that the staged changes of dir add, so that a
+// pre-commit hook only looks at what the commit brings in. It returns how
+// many files it scanned.
+func scanStaged(dir string, excludes []string) (int, []Finding, error) {
+	lines, err := git.StagedAddedLines(dir)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var findings []Finding
+	files := map[string]bool{}
+	for _, l := range lines {
+		if excludedPath(l.Path, excludes) || binaryExtensions[strings.ToLower(path.Ext(l.Path))] {
+			continue
+		}
+		files[l.Path] = true
+		findings = append(findings, scanLine(l.Text, l.Line, l.Path)...)
+	}
+	return len(files), findings, nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanStaged_OnlyAddedLines(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+			"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	token := "ghp_" + strings.Repeat("a", 36)
+	other := "ghp_" + strings.Repeat("b", 36)
+
+	git("init", "-q")
+	// Already committed: not the next commit's problem
+	write("old.js", "const token = '"+token+"'\n")
+	git("add", "-A")
+	git("commit", "-qm", "old")
+
+	write("old.js", "const token = '"+token+"'\nconst port = 3000\n")
+	write("config.js", "const a = 1\nconst token = '"+other+"'\n")
+	write("node_modules/lib/index.js", other+"\n")
+	write("unstaged.js", other+"\n")
+	git("add", "old.js", "config.js", "node_modules")
+
+	files, findings, err := scanStaged(dir, defaultExcludes)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if files != 2 {
+		t.Errorf("expected 2 files, got %d", files)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	if f := findings[0]; f.File != "config.js" || f.Line != 2 || f.Type != "GitHub PAT" {
+		t.Errorf("unexpected finding %+v", f)
+	}
+}
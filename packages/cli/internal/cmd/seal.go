@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/e2e"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var sealCmd = &cobra.Command{
+	Use:   "seal [file]",
+	Short: "Encrypt an env file so it can be committed",
+	Long: `Encrypt the values of an env file to one or more public keys, producing a
+.sealed file that is safe to commit. Keys stay readable; values can only be
+decrypted by the recipients, with keyway unseal, without any network access.
+
+You are always a recipient unless --no-self is given. A recipient is a public
+key (see keyway unseal --public-key) or a file listing one per line.
+
+Sealed files can be pushed directly: keyway push -f .env.production.sealed`,
+	Example: `  keyway seal .env.production
+  keyway seal .env.production -r recipients.txt
+  keyway seal .env -r k8Xq...= -o backup.env.sealed`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSeal,
+}
+
+var unsealCmd = &cobra.Command{
+	Use:   "unseal [file]",
+	Short: "Decrypt a sealed env file",
+	Long: `Decrypt a file created with keyway seal, using your identity in
+~/.config/keyway/identity.key. No network access is needed.
+
+The output defaults to the file name without .sealed, written with 0600
+permissions. Use -o - to print it instead.`,
+	Example: `  keyway unseal .env.production.sealed
+  keyway unseal .env.sealed -o - | docker run --env-file /dev/stdin app
+  keyway unseal --public-key`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUnseal,
+}
+
+func init() {
+	sealCmd.Flags().StringArrayP("recipient", "r", nil, "Public key or file of public keys to seal to (repeatable)")
+	sealCmd.Flags().StringP("output", "o", "", "Output file (default: <file>.sealed)")
+	sealCmd.Flags().Bool("no-self", false, "Don't add your own public key as a recipient")
+
+	unsealCmd.Flags().StringP("output", "o", "", "Output file, - for stdout (default: file without .sealed)")
+	unsealCmd.Flags().BoolP("yes", "y", false, "Overwrite the output file without confirmation")
+	unsealCmd.Flags().Bool("public-key", false, "Print your public key, to give to people sealing files for you")
+}
+
+// SealOptions contains the parsed flags for the seal command
+type SealOptions struct {
+	File       string
+	Output     string
+	Recipients []string
+	NoSelf     bool
+}
+
+// UnsealOptions contains the parsed flags for the unseal command
+type UnsealOptions struct {
+	File      string
+	Output    string
+	Yes       bool
+	PublicKey bool
+
+	// Stdout receives the output with -o - or --public-key (defaults to os.Stdout)
+	Stdout io.Writer
+}
+
+// runSeal is the entry point for the seal command (uses default dependencies)
+func runSeal(cmd *cobra.Command, args []string) error {
+	opts := SealOptions{File: ".env"}
+	if len(args) > 0 {
+		opts.File = args[0]
+	}
+	opts.Output, _ = cmd.Flags().GetString("output")
+	opts.Recipients, _ = cmd.Flags().GetStringArray("recipient")
+	opts.NoSelf, _ = cmd.Flags().GetBool("no-self")
+
+	return runSealWithDeps(opts, defaultDeps)
+}
+
+// runUnseal is the entry point for the unseal command (uses default dependencies)
+func runUnseal(cmd *cobra.Command, args []string) error {
+	opts := UnsealOptions{File: ".env" + env.SealedSuffix}
+	if len(args) > 0 {
+		opts.File = args[0]
+	}
+	opts.Output, _ = cmd.Flags().GetString("output")
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	opts.PublicKey, _ = cmd.Flags().GetBool("public-key")
+
+	return runUnsealWithDeps(opts, defaultDeps)
+}
+
+// runSealWithDeps is the testable version of runSeal
+func runSealWithDeps(opts SealOptions, deps *Dependencies) error {
+	deps.UI.Intro("seal")
+
+	content, err := deps.FS.ReadFile(opts.File)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("File not found: %s", opts.File))
+		return err
+	}
+	if e2e.IsSealed(content) {
+		deps.UI.Error(fmt.Sprintf("%s is already sealed", opts.File))
+		return fmt.Errorf("file is already sealed")
+	}
+	secrets := env.Parse(string(content))
+	if len(secrets) == 0 {
+		deps.UI.Error("No valid environment variables found in file")
+		return fmt.Errorf("no variables found")
+	}
+
+	recipients, err := sealRecipients(opts.Recipients, deps)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if !opts.NoSelf {
+		id, created, err := deps.Identity.LoadOrCreate()
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to load identity: %s", err.Error()))
+			return err
+		}
+		if created {
+			deps.UI.Info("Created a new identity in ~/.config/keyway/identity.key - back it up, it can't be recovered")
+		}
+		recipients = append([]string{id.PublicKey()}, recipients...)
+	}
+	if len(recipients) == 0 {
+		deps.UI.Error("No recipients - pass --recipient or drop --no-self")
+		return fmt.Errorf("no recipients")
+	}
+
+	sealed, err := e2e.Seal(secrets, recipients)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = opts.File + env.SealedSuffix
+	}
+	if err := deps.FS.WriteFile(output, []byte(sealed), 0644); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write file: %s", err.Error()))
+		return err
+	}
+
+	analytics.Track(analytics.EventSeal, map[string]interface{}{
+		"secretCount": len(secrets),
+		"recipients":  len(e2e.SealedRecipients([]byte(sealed))),
+	})
+
+	deps.UI.Step(fmt.Sprintf("Variables: %s", deps.UI.Value(len(secrets))))
+	for _, r := range e2e.SealedRecipients([]byte(sealed)) {
+		deps.UI.Step(fmt.Sprintf("Recipient: %s", deps.UI.Dim(e2e.Fingerprint(r))))
+	}
+	deps.UI.Success(fmt.Sprintf("Sealed %s to %s", deps.UI.File(opts.File), deps.UI.File(output)))
+	deps.UI.Outro("Safe to commit - if .gitignore ignores it, add !" + output)
+	return nil
+}
+
+// sealRecipients expands --recipient values: each is a public key or a file
+// listing public keys, one per line
+func sealRecipients(values []string, deps *Dependencies) ([]string, error) {
+	var recipients []string
+	for _, value := range values {
+		data, err := deps.FS.ReadFile(value)
+		if err != nil {
+			recipients = append(recipients, value)
+			continue
+		}
+		found := false
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			recipients = append(recipients, line)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("no public keys in %s", value)
+		}
+	}
+	return recipients, nil
+}
+
+// runUnsealWithDeps is the testable version of runUnseal
+func runUnsealWithDeps(opts UnsealOptions, deps *Dependencies) error {
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	if opts.PublicKey {
+		id, _, err := deps.Identity.LoadOrCreate()
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to load identity: %s", err.Error()))
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, id.PublicKey())
+		return err
+	}
+
+	// When streaming to stdout, keep the UI quiet so the output can be piped
+	toStdout := opts.Output == "-"
+	if !toStdout {
+		deps.UI.Intro("unseal")
+	}
+
+	content, err := deps.FS.ReadFile(opts.File)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("File not found: %s", opts.File))
+		return err
+	}
+	if !e2e.IsSealed(content) {
+		deps.UI.Error(fmt.Sprintf("%s is not a sealed file", opts.File))
+		return fmt.Errorf("not a sealed file")
+	}
+
+	id, err := deps.Identity.Load()
+	if err != nil {
+		deps.UI.Error("No identity found in ~/.config/keyway/identity.key")
+		return err
+	}
+	secrets, err := e2e.Unseal(content, id)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	out := []byte(env.Format(secrets))
+
+	analytics.Track(analytics.EventUnseal, map[string]interface{}{
+		"secretCount": len(secrets),
+	})
+
+	if toStdout {
+		_, err = stdout.Write(out)
+		return err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = strings.TrimSuffix(opts.File, env.SealedSuffix)
+		if output == opts.File {
+			deps.UI.Error("Cannot derive the output file name - pass --output")
+			return fmt.Errorf("output file required")
+		}
+	}
+
+	if _, err := deps.FS.ReadFile(output); err == nil && !opts.Yes {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error(fmt.Sprintf("File %s exists - use --yes to overwrite", output))
+			return fmt.Errorf("file %s exists - use --yes to confirm", output)
+		}
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Overwrite %s?", output), false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	}
+
+	if err := deps.FS.WriteFile(output, out, 0600); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write file: %s", err.Error()))
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Unsealed %d variable(s) to %s", len(secrets), deps.UI.File(output)))
+	deps.UI.Outro("Keep unsealed files out of git")
+	return nil
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/e2e"
+)
+
+func TestRunSealWithDeps_SealsToSelfAndRecipients(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+
+	teammate, _ := e2e.NewIdentity()
+	fsMock.Files[".env.production"] = []byte("API_KEY=sk_live_123\nDB_URL=postgres://prod")
+	fsMock.Files["recipients.txt"] = []byte("# deploy server\n" + teammate.PublicKey() + "\n")
+
+	opts := SealOptions{File: ".env.production", Recipients: []string{"recipients.txt"}}
+	if err := runSealWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sealed := fsMock.Written[".env.production.sealed"]
+	if !e2e.IsSealed(sealed) {
+		t.Fatalf("expected sealed output, got %q", sealed)
+	}
+	if bytes.Contains(sealed, []byte("sk_live_123")) {
+		t.Fatal("sealed output contains a plaintext value")
+	}
+
+	self := deps.Identity.(*MockIdentityStore).Identity
+	for name, id := range map[string]*e2e.Identity{"self": self, "teammate": teammate} {
+		secrets, err := e2e.Unseal(sealed, id)
+		if err != nil {
+			t.Fatalf("%s cannot unseal: %v", name, err)
+		}
+		if secrets["API_KEY"] != "sk_live_123" {
+			t.Errorf("%s: API_KEY = %q", name, secrets["API_KEY"])
+		}
+	}
+}
+
+func TestRunSealWithDeps_NoRecipients(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+	fsMock.Files[".env"] = []byte("API_KEY=secret")
+
+	err := runSealWithDeps(SealOptions{File: ".env", NoSelf: true}, deps)
+	if err == nil {
+		t.Fatal("expected error without recipients")
+	}
+	if len(fsMock.Written) != 0 {
+		t.Error("expected nothing to be written")
+	}
+}
+
+func TestRunSealWithDeps_AlreadySealed(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+	id, _ := e2e.NewIdentity()
+	sealed, _ := e2e.Seal(map[string]string{"A": "1"}, []string{id.PublicKey()})
+	fsMock.Files[".env.sealed"] = []byte(sealed)
+
+	if err := runSealWithDeps(SealOptions{File: ".env.sealed"}, deps); err == nil {
+		t.Fatal("expected error for an already sealed file")
+	}
+}
+
+func TestRunUnsealWithDeps_WritesFile(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+
+	id, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: id}
+	sealed, _ := e2e.Seal(map[string]string{"API_KEY": "sk_live_123", "GREETING": "hello world"}, []string{id.PublicKey()})
+	fsMock.Files[".env.production.sealed"] = []byte(sealed)
+
+	if err := runUnsealWithDeps(UnsealOptions{File: ".env.production.sealed"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "API_KEY=sk_live_123\nGREETING=\"hello world\"\n"
+	if got := string(fsMock.Written[".env.production"]); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if fsMock.Perms[".env.production"] != 0600 {
+		t.Errorf("expected 0600 permissions, got %o", fsMock.Perms[".env.production"])
+	}
+}
+
+func TestRunUnsealWithDeps_Stdout(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, _ := NewTestDepsWithEnv()
+
+	id, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: id}
+	sealed, _ := e2e.Seal(map[string]string{"TOKEN": "abc"}, []string{id.PublicKey()})
+	fsMock.Files[".env.sealed"] = []byte(sealed)
+
+	var out bytes.Buffer
+	opts := UnsealOptions{File: ".env.sealed", Output: "-", Stdout: &out}
+	if err := runUnsealWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if out.String() != "TOKEN=abc\n" {
+		t.Errorf("got %q", out.String())
+	}
+	if len(uiMock.IntroCalls) != 0 {
+		t.Error("expected no UI output when writing to stdout")
+	}
+}
+
+func TestRunUnsealWithDeps_ExistingFileNeedsConfirmation(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+
+	id, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: id}
+	sealed, _ := e2e.Seal(map[string]string{"TOKEN": "abc"}, []string{id.PublicKey()})
+	fsMock.Files[".env.sealed"] = []byte(sealed)
+	fsMock.Files[".env"] = []byte("TOKEN=local")
+
+	err := runUnsealWithDeps(UnsealOptions{File: ".env.sealed"}, deps)
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected --yes hint, got %v", err)
+	}
+	if _, ok := fsMock.Written[".env"]; ok {
+		t.Error("expected .env to be left alone")
+	}
+}
+
+func TestRunUnsealWithDeps_NotARecipient(t *testing.T) {
+	deps, _, _, _, fsMock, _, _ := NewTestDepsWithEnv()
+
+	other, _ := e2e.NewIdentity()
+	me, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: me}
+	sealed, _ := e2e.Seal(map[string]string{"TOKEN": "abc"}, []string{other.PublicKey()})
+	fsMock.Files[".env.sealed"] = []byte(sealed)
+
+	if err := runUnsealWithDeps(UnsealOptions{File: ".env.sealed"}, deps); err == nil {
+		t.Fatal("expected error for a file sealed to someone else")
+	}
+}
+
+func TestRunUnsealWithDeps_PublicKey(t *testing.T) {
+	deps, _, _, _, _, _, _ := NewTestDepsWithEnv()
+
+	var out bytes.Buffer
+	if err := runUnsealWithDeps(UnsealOptions{PublicKey: true, Stdout: &out}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	id := deps.Identity.(*MockIdentityStore).Identity
+	if strings.TrimSpace(out.String()) != id.PublicKey() {
+		t.Errorf("got %q, want %q", out.String(), id.PublicKey())
+	}
+}
+
+func TestRunPushWithDeps_SealedFile(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _, apiMock := NewTestDepsWithEnv()
+
+	id, _ := e2e.NewIdentity()
+	deps.Identity = &MockIdentityStore{Identity: id}
+	sealed, _ := e2e.Seal(map[string]string{"API_KEY": "new", "DB_URL": "postgres://prod"}, []string{id.PublicKey()})
+	fsMock.Files[".env.production.sealed"] = []byte(sealed)
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{
+		EnvName:    "production",
+		File:       ".env.production.sealed",
+		Yes:        true,
+		EnvFlagSet: true,
+	}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.PushedSecrets["API_KEY"] != "new" || apiMock.PushedSecrets["DB_URL"] != "postgres://prod" {
+		t.Errorf("expected unsealed values to be pushed, got %v", apiMock.PushedSecrets)
+	}
+	if len(uiMock.SuccessCalls) == 0 {
+		t.Error("expected Success to be called")
+	}
+}
+
+func TestRunPullWithDeps_RefusesSealedFile(t *testing.T) {
+	deps, _, _, _, fsMock, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret"}
+
+	err := runPullWithDeps(PullOptions{EnvName: "production", File: ".env.production.sealed", Yes: true}, deps)
+	if err == nil {
+		t.Fatal("expected error when pulling into a sealed file")
+	}
+	if len(fsMock.Written) != 0 {
+		t.Error("expected nothing to be written")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/generate"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +23,16 @@ Examples:
   keyway set API_KEY                    # Prompt for value (masked)
   keyway set API_KEY=sk_live_xxx        # Set with inline value
   keyway set API_KEY -e production      # Set in specific environment
-  keyway set API_KEY -y                 # Skip confirmation if updating`,
+  keyway set API_KEY -y                 # Skip confirmation if updating
+  keyway set SESSION_SECRET --generate alnum:64
+  keyway set JWT_KEY --generate ed25519 # Also stores JWT_KEY_PUBLIC
+
+Generators (--generate):
+  ` + strings.Join(generate.Specs, "\n  ") + `
+
+Generated values go straight to the vault and are never shown unless --reveal
+is passed. Keypairs also store the public key as <KEY>_PUBLIC, and bcrypt
+stores the password as <KEY>_PASSWORD.`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runSet,
 }
@@ -31,6 +41,8 @@ func init() {
 	setCmd.Flags().StringP("env", "e", "", "Environment name (default: development)")
 	setCmd.Flags().BoolP("local", "l", false, "Write to local .env file instead of vault (legacy)")
 	setCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompts")
+	setCmd.Flags().StringP("generate", "g", "", "Generate the value (alnum[:n], hex[:n], base64[:n], uuid, rsa[:bits], ed25519, bcrypt[:cost])")
+	setCmd.Flags().Bool("reveal", false, "Print the generated value(s)")
 }
 
 // SetOptions contains the parsed flags for the set command
@@ -41,6 +53,31 @@ type SetOptions struct {
 	LocalOnly  bool
 	Yes        bool
 	EnvFlagSet bool
+	Generate   string
+	Reveal     bool
+
+	// companions are extra secrets written with Key, e.g. KEY_PUBLIC for a generated keypair
+	companions []setEntry
+}
+
+// setEntry is one key/value written by the set command
+type setEntry struct {
+	Key   string
+	Value string
+}
+
+// entries returns the key being set followed by its companions
+func (o SetOptions) entries() []setEntry {
+	return append([]setEntry{{Key: o.Key, Value: o.Value}}, o.companions...)
+}
+
+// displayValue is how a new value is shown before overwriting: masked, or
+// not at all when it was generated
+func (o SetOptions) displayValue(value string) string {
+	if o.Generate != "" && !o.Reveal {
+		return "(generated)"
+	}
+	return maskValue(value)
 }
 
 // runSet is the entry point for the set command (uses default dependencies)
@@ -64,6 +101,8 @@ func runSet(cmd *cobra.Command, args []string) error {
 	opts.EnvName, _ = cmd.Flags().GetString("env")
 	opts.LocalOnly, _ = cmd.Flags().GetBool("local")
 	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	opts.Generate, _ = cmd.Flags().GetString("generate")
+	opts.Reveal, _ = cmd.Flags().GetBool("reveal")
 
 	return runSetWithDeps(opts, defaultDeps)
 }
@@ -90,6 +129,29 @@ func runSetWithDeps(opts SetOptions, deps *Dependencies) error {
 
 	deps.UI.Step(fmt.Sprintf("Key: %s", deps.UI.Value(opts.Key)))
 
+	if opts.Reveal && opts.Generate == "" {
+		deps.UI.Error("--reveal can only be used with --generate")
+		return fmt.Errorf("--reveal requires --generate")
+	}
+
+	// Generate the value so it never has to be typed or pasted
+	if opts.Generate != "" {
+		if opts.Value != "" {
+			deps.UI.Error("Cannot use --generate with a value")
+			return fmt.Errorf("cannot use --generate with a value")
+		}
+		result, err := generate.Generate(opts.Generate)
+		if err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		opts.Value = result.Value
+		for _, c := range result.Companions {
+			opts.companions = append(opts.companions, setEntry{Key: opts.Key + c.Suffix, Value: c.Value})
+		}
+		deps.UI.Step(fmt.Sprintf("Generated: %s", result.Description))
+	}
+
 	// Prompt for value if not provided
 	if opts.Value == "" {
 		if !deps.UI.IsInteractive() {
@@ -130,27 +192,32 @@ func runSetLocal(opts SetOptions, deps *Dependencies) error {
 	}
 
 	// Check if key exists
-	if existingValue, ok := localSecrets[opts.Key]; ok {
-		if !opts.Yes {
-			deps.UI.Warn(fmt.Sprintf("%s already exists in %s", opts.Key, envFile))
+	existing := 0
+	for _, e := range opts.entries() {
+		if existingValue, ok := localSecrets[e.Key]; ok && !opts.Yes {
+			existing++
+			deps.UI.Warn(fmt.Sprintf("%s already exists in %s", e.Key, envFile))
 			deps.UI.Message(fmt.Sprintf("  Current: %s", deps.UI.Dim(maskValue(existingValue))))
-			deps.UI.Message(fmt.Sprintf("  New:     %s", deps.UI.Value(maskValue(opts.Value))))
-
-			if !deps.UI.IsInteractive() {
-				deps.UI.Error("Use --yes to update existing secret in non-interactive mode")
-				return fmt.Errorf("confirmation required")
-			}
+			deps.UI.Message(fmt.Sprintf("  New:     %s", deps.UI.Value(opts.displayValue(e.Value))))
+		}
+	}
+	if existing > 0 {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error("Use --yes to update existing secret in non-interactive mode")
+			return fmt.Errorf("confirmation required")
+		}
 
-			confirm, _ := deps.UI.Confirm("Update this secret?", false)
-			if !confirm {
-				deps.UI.Warn("Aborted.")
-				return nil
-			}
+		confirm, _ := deps.UI.Confirm("Update this secret?", false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
 		}
 	}
 
 	// Update and write
-	localSecrets[opts.Key] = opts.Value
+	for _, e := range opts.entries() {
+		localSecrets[e.Key] = e.Value
+	}
 	content := formatEnvContent(localSecrets)
 
 	if err := deps.FS.WriteFile(envFile, []byte(content), 0600); err != nil {
@@ -158,7 +225,10 @@ func runSetLocal(opts SetOptions, deps *Dependencies) error {
 		return err
 	}
 
-	deps.UI.Success(fmt.Sprintf("Set %s in %s", opts.Key, envFile))
+	for _, e := range opts.entries() {
+		deps.UI.Success(fmt.Sprintf("Set %s in %s", e.Key, envFile))
+	}
+	revealGenerated(opts, deps)
 	return nil
 }
 
@@ -246,25 +316,30 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 		}
 	}
 
-	// Check if key exists in vault
-	existsInVault := false
-	if existingValue, ok := vaultSecrets[opts.Key]; ok {
-		existsInVault = true
+	// Check if keys exist in vault
+	existsInVault := make(map[string]bool)
+	for _, e := range opts.entries() {
+		existingValue, ok := vaultSecrets[e.Key]
+		if !ok {
+			continue
+		}
+		existsInVault[e.Key] = true
 		if !opts.Yes {
-			deps.UI.Warn(fmt.Sprintf("%s already exists in vault (%s)", opts.Key, envName))
+			deps.UI.Warn(fmt.Sprintf("%s already exists in vault (%s)", e.Key, envName))
 			deps.UI.Message(fmt.Sprintf("  Current: %s", deps.UI.Dim(maskValue(existingValue))))
-			deps.UI.Message(fmt.Sprintf("  New:     %s", deps.UI.Value(maskValue(opts.Value))))
-
-			if !deps.UI.IsInteractive() {
-				deps.UI.Error("Use --yes to update existing secret in non-interactive mode")
-				return fmt.Errorf("confirmation required")
-			}
+			deps.UI.Message(fmt.Sprintf("  New:     %s", deps.UI.Value(opts.displayValue(e.Value))))
+		}
+	}
+	if len(existsInVault) > 0 && !opts.Yes {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error("Use --yes to update existing secret in non-interactive mode")
+			return fmt.Errorf("confirmation required")
+		}
 
-			confirm, _ := deps.UI.Confirm("Update this secret?", false)
-			if !confirm {
-				deps.UI.Warn("Aborted.")
-				return nil
-			}
+		confirm, _ := deps.UI.Confirm("Update this secret?", false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
 		}
 	}
 
@@ -272,11 +347,14 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 	analytics.Track("cli_set", map[string]interface{}{
 		"repoFullName": repo,
 		"environment":  envName,
-		"isUpdate":     existsInVault,
+		"isUpdate":     existsInVault[opts.Key],
+		"generated":    opts.Generate != "",
 	})
 
 	// Merge and push
-	vaultSecrets[opts.Key] = opts.Value
+	for _, e := range opts.entries() {
+		vaultSecrets[e.Key] = e.Value
+	}
 
 	err = deps.UI.Spin("Pushing to vault...", func() error {
 		_, pushErr := client.PushSecrets(ctx, repo, envName, vaultSecrets)
@@ -311,12 +389,16 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 			return err
 		}
 	}
+	evictAgentCache(deps, repo, envName)
 
-	if existsInVault {
-		deps.UI.Success(fmt.Sprintf("Updated %s in vault (%s)", opts.Key, envName))
-	} else {
-		deps.UI.Success(fmt.Sprintf("Added %s to vault (%s)", opts.Key, envName))
+	for _, e := range opts.entries() {
+		if existsInVault[e.Key] {
+			deps.UI.Success(fmt.Sprintf("Updated %s in vault (%s)", e.Key, envName))
+		} else {
+			deps.UI.Success(fmt.Sprintf("Added %s to vault (%s)", e.Key, envName))
+		}
 	}
+	revealGenerated(opts, deps)
 
 	// Show tip for using the secret
 	deps.UI.Message("")
@@ -332,6 +414,17 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 	return nil
 }
 
+// revealGenerated prints generated values when --reveal is passed
+func revealGenerated(opts SetOptions, deps *Dependencies) {
+	if opts.Generate == "" || !opts.Reveal {
+		return
+	}
+	deps.UI.Message("")
+	for _, e := range opts.entries() {
+		deps.UI.Message(fmt.Sprintf("%s=%s", e.Key, e.Value))
+	}
+}
+
 // formatEnvContent formats a map as env file content (sorted for deterministic output)
 func formatEnvContent(secrets map[string]string) string {
 	keys := make([]string, 0, len(secrets))
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/keywaysh/cli/internal/api"
@@ -689,3 +690,114 @@ func TestFormatEnvContent_SortsKeys(t *testing.T) {
 		t.Errorf("expected sorted output:\n%s\ngot:\n%s", expected, result)
 	}
 }
+
+func TestRunSetWithDeps_Generate(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "OTHER=x"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+
+	opts := SetOptions{Key: "SESSION_SECRET", Generate: "alnum:40", EnvName: "development", EnvFlagSet: true}
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	value := apiMock.PushedSecrets["SESSION_SECRET"]
+	if len(value) != 40 {
+		t.Fatalf("expected a 40-character generated value, got %q", value)
+	}
+	if apiMock.PushedSecrets["OTHER"] != "x" {
+		t.Error("expected existing secrets to be kept")
+	}
+	for _, m := range uiMock.MessageCalls {
+		if strings.Contains(m, value) {
+			t.Errorf("generated value must not be shown without --reveal: %q", m)
+		}
+	}
+}
+
+func TestRunSetWithDeps_GenerateKeypairAndReveal(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+
+	opts := SetOptions{Key: "JWT_KEY", Generate: "ed25519", Reveal: true, EnvName: "development", EnvFlagSet: true}
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(apiMock.PushedSecrets["JWT_KEY"], "PRIVATE KEY") ||
+		!strings.Contains(apiMock.PushedSecrets["JWT_KEY_PUBLIC"], "PUBLIC KEY") {
+		t.Fatalf("expected the keypair to be stored, got %v", apiMock.PushedSecrets)
+	}
+	if len(uiMock.SuccessCalls) != 2 {
+		t.Errorf("expected one success per key, got %v", uiMock.SuccessCalls)
+	}
+
+	revealed := strings.Join(uiMock.MessageCalls, "\n")
+	if !strings.Contains(revealed, "JWT_KEY="+apiMock.PushedSecrets["JWT_KEY"]) {
+		t.Error("expected --reveal to print the generated value")
+	}
+}
+
+func TestRunSetWithDeps_GenerateOverwriteMasksNewValue(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old-value"}
+	uiMock.Interactive = false
+
+	opts := SetOptions{Key: "API_KEY", Generate: "hex", EnvName: "development", EnvFlagSet: true}
+	if err := runSetWithDeps(opts, deps); err == nil {
+		t.Fatal("expected confirmation to be required")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected nothing to be pushed")
+	}
+
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected the existing key to be reported")
+	}
+
+	if got := opts.displayValue("abcdef123456"); got != "(generated)" {
+		t.Errorf("expected generated values to stay hidden, got %q", got)
+	}
+	opts.Reveal = true
+	if got := opts.displayValue("abcdef123456"); got != maskValue("abcdef123456") {
+		t.Errorf("expected --reveal to show the masked value, got %q", got)
+	}
+}
+
+func TestRunSetWithDeps_GenerateInvalid(t *testing.T) {
+	tests := []SetOptions{
+		{Key: "API_KEY", Generate: "nope"},
+		{Key: "API_KEY", Generate: "uuid", Value: "x"},
+		{Key: "API_KEY", Value: "x", Reveal: true},
+	}
+	for _, opts := range tests {
+		deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+		if err := runSetWithDeps(opts, deps); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+		if len(uiMock.ErrorCalls) == 0 || apiMock.PushedSecrets != nil {
+			t.Errorf("expected an error and no push for %+v", opts)
+		}
+	}
+}
+
+func TestRunSetWithDeps_EvictsAgentCache(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+	agentMock := deps.Agent.(*MockAgent)
+	agentMock.Running = true
+	agentMock.Contents = map[string]string{"owner/repo:development": "API_KEY=old"}
+
+	opts := SetOptions{Key: "API_KEY", Value: "new", EnvName: "development", EnvFlagSet: true, Yes: true}
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(agentMock.Evicted) != 1 || agentMock.Evicted[0] != "owner/repo:development" {
+		t.Errorf("expected the environment to be evicted from the agent, got %v", agentMock.Evicted)
+	}
+}
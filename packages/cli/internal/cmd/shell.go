@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/injector"
+	"github.com/spf13/cobra"
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Open a shell with secrets injected",
+	Long: `Open your $SHELL with the environment's secrets injected, instead of
+sourcing a .env file by hand. Nothing is written to your shell history or to a
+file, and the secrets are gone when you exit.
+
+The prompt is marked with the environment name, and $KEYWAY_ENV holds it, so
+you always know which credentials you're holding. The shell exits on its own
+after it has been idle at the prompt for --idle-timeout (bash, zsh and ksh).`,
+	Example: `  keyway shell
+  keyway shell -e staging
+  keyway shell -e production --idle-timeout 5m`,
+	Args: cobra.NoArgs,
+	RunE: runShellCmd,
+}
+
+func init() {
+	shellCmd.Flags().StringArrayP("env", "e", []string{"development"}, "Environment name (repeat to layer environments, later ones win)")
+	shellCmd.Flags().Duration("idle-timeout", shellIdleTimeout, "Exit the shell after it has been idle this long (0 to disable)")
+}
+
+// shellIdleTimeout is how long a keyway shell may sit idle at the prompt
+const shellIdleTimeout = 30 * time.Minute
+
+// ShellEnvVar names the environment a keyway shell was opened for
+const ShellEnvVar = "KEYWAY_ENV"
+
+// ShellOptions contains the parsed flags for the shell command
+type ShellOptions struct {
+	EnvName    string
+	EnvFlagSet bool
+	// BaseEnvs are layered under EnvName, in order
+	BaseEnvs    []string
+	IdleTimeout time.Duration
+}
+
+// runShellCmd is the entry point for the shell command (uses default dependencies)
+func runShellCmd(cmd *cobra.Command, args []string) error {
+	opts := ShellOptions{EnvFlagSet: cmd.Flags().Changed("env")}
+	envNames, _ := cmd.Flags().GetStringArray("env")
+	opts.BaseEnvs, opts.EnvName = envNamesFlag(envNames)
+	opts.IdleTimeout, _ = cmd.Flags().GetDuration("idle-timeout")
+
+	return runShellWithDeps(opts, defaultDeps)
+}
+
+// runShellWithDeps is the testable version of runShellCmd
+func runShellWithDeps(opts ShellOptions, deps *Dependencies) error {
+	deps.UI.Intro("shell")
+
+	if current := os.Getenv(ShellEnvVar); current != "" {
+		deps.UI.Warn(fmt.Sprintf("Already in a keyway shell for %s - exit it to drop its secrets", current))
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	envName := opts.EnvName
+	if !opts.EnvFlagSet && deps.UI.IsInteractive() {
+		vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
+		if err != nil || len(vaultEnvs) == 0 {
+			vaultEnvs = []string{"development", "staging", "production"}
+		}
+		for i, e := range vaultEnvs {
+			if e == "development" {
+				vaultEnvs[0], vaultEnvs[i] = vaultEnvs[i], vaultEnvs[0]
+				break
+			}
+		}
+		selected, err := deps.UI.Select("Environment:", vaultEnvs)
+		if err != nil {
+			return err
+		}
+		envName = selected
+	}
+
+	envNames := append(append([]string{}, opts.BaseEnvs...), envName)
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(strings.Join(envNames, " → "))))
+
+	var secrets map[string]string
+	fetch := func() error {
+		return deps.UI.Spin("Fetching secrets...", func() error {
+			var err error
+			secrets, _, err = fetchEnvLayers(ctx, client, repo, envNames)
+			return err
+		})
+	}
+
+	err = fetch()
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
+	if err != nil {
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+
+	shell := userShell()
+	dir, err := os.MkdirTemp("", "keyway-shell-")
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	label := strings.Join(envNames, "+")
+	args, env, supportsIdle, err := shellLaunch(shell, label, opts.IdleTimeout, dir)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	for k, v := range secrets {
+		env[k] = v
+	}
+	env[ShellEnvVar] = label
+
+	deps.UI.Success(fmt.Sprintf("Injected %d secrets", len(secrets)))
+	if opts.IdleTimeout > 0 {
+		if supportsIdle {
+			deps.UI.Step(fmt.Sprintf("Idle timeout: %s", deps.UI.Value(opts.IdleTimeout)))
+		} else {
+			deps.UI.Warn(fmt.Sprintf("%s has no idle timeout: remember to exit it", filepath.Base(shell)))
+		}
+	}
+	deps.UI.Outro("Type exit to leave the shell and drop its secrets")
+
+	err = deps.CmdRunner.RunCommand(shell, args, env, injector.Options{})
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Left the keyway shell for %s", label)))
+	return err
+}
+
+// userShell returns the shell to open: $SHELL, or a default for the platform
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if comspec := os.Getenv("COMSPEC"); comspec != "" {
+		return comspec
+	}
+	return "/bin/sh"
+}
+
+// shellLaunch returns the arguments and extra variables that open shell with
+// a prompt marked with label and, where the shell supports it, the idle
+// timeout. Startup files it needs are written to dir. The user's own startup
+// files still run first, so the marker goes on top of their prompt.
+func shellLaunch(shell, label string, idle time.Duration, dir string) (args []string, env map[string]string, supportsIdle bool, err error) {
+	env = map[string]string{}
+	marker := "(keyway:" + label + ")"
+	// Red when production credentials are at hand, yellow otherwise
+	color, ansi := "yellow", "33"
+	if strings.Contains(strings.ToLower(label), "prod") {
+		color, ansi = "red", "31"
+	}
+	tmout := ""
+	if idle > 0 {
+		tmout = fmt.Sprintf("TMOUT=%d\n", int(idle.Round(time.Second).Seconds()))
+	}
+
+	switch name := strings.TrimSuffix(filepath.Base(shell), ".exe"); name {
+	case "bash":
+		rc := filepath.Join(dir, "bashrc")
+		content := "[ -f ~/.bashrc ] && . ~/.bashrc\n" +
+			tmout +
+			fmt.Sprintf("PS1=%s\"$PS1\"\n", shellQuote(`\[\e[`+ansi+`m\]`+marker+`\[\e[0m\] `))
+		if err := os.WriteFile(rc, []byte(content), 0600); err != nil {
+			return nil, nil, false, err
+		}
+		return []string{"--rcfile", rc, "-i"}, env, true, nil
+
+	case "zsh":
+		// zsh reads its startup files from $ZDOTDIR: point it at dir, where
+		// they source the user's own and then restore it
+		user := os.Getenv("ZDOTDIR")
+		if user == "" {
+			user, _ = os.UserHomeDir()
+		}
+		zshenv := fmt.Sprintf("ZDOTDIR=%s\n", shellQuote(user)) +
+			"[[ -f \"$ZDOTDIR/.zshenv\" ]] && source \"$ZDOTDIR/.zshenv\"\n" +
+			"_keyway_zdotdir=$ZDOTDIR\n" +
+			fmt.Sprintf("ZDOTDIR=%s\n", shellQuote(dir))
+		zshrc := "ZDOTDIR=$_keyway_zdotdir\nunset _keyway_zdotdir\n" +
+			"[[ -f \"$ZDOTDIR/.zshrc\" ]] && source \"$ZDOTDIR/.zshrc\"\n" +
+			tmout +
+			fmt.Sprintf("PROMPT=%s\"$PROMPT\"\n", shellQuote("%F{"+color+"}"+strings.ReplaceAll(marker, "%", "%%")+"%f "))
+		if err := os.WriteFile(filepath.Join(dir, ".zshenv"), []byte(zshenv), 0600); err != nil {
+			return nil, nil, false, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".zshrc"), []byte(zshrc), 0600); err != nil {
+			return nil, nil, false, err
+		}
+		env["ZDOTDIR"] = dir
+		return []string{"-i"}, env, true, nil
+
+	case "fish":
+		quoted := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(marker+" ") + "'"
+		init := "functions -c fish_prompt _keyway_fish_prompt\n" +
+			fmt.Sprintf("function fish_prompt; set_color %s; echo -n %s; set_color normal; _keyway_fish_prompt; end", color, quoted)
+		return []string{"-i", "--init-command", init}, env, false, nil
+
+	case "cmd":
+		env["PROMPT"] = marker + " $P$G"
+		return nil, env, false, nil
+
+	case "pwsh", "powershell":
+		return []string{"-NoExit", "-Command", fmt.Sprintf("function prompt { '%s ' + $executionContext.SessionState.Path.CurrentLocation + '> ' }", strings.ReplaceAll(marker, "'", "''"))}, env, false, nil
+
+	default:
+		// sh, ksh and the like read PS1 and TMOUT from the environment
+		env["PS1"] = marker + " $ "
+		if idle > 0 {
+			env["TMOUT"] = strings.TrimSuffix(strings.TrimPrefix(tmout, "TMOUT="), "\n")
+		}
+		return []string{"-i"}, env, name == "ksh" || name == "mksh", nil
+	}
+}
+
+// shellQuote quotes s for POSIX shells and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunShellWithDeps_OpensShellWithSecrets(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv(ShellEnvVar, "")
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := ShellOptions{EnvName: "staging", EnvFlagSet: true, IdleTimeout: time.Minute}
+	if err := runShellWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cmdRunner.LastCommand != "/bin/bash" {
+		t.Errorf("expected $SHELL to be opened, got %q", cmdRunner.LastCommand)
+	}
+	if len(cmdRunner.LastArgs) == 0 || cmdRunner.LastArgs[0] != "--rcfile" {
+		t.Errorf("expected bash to get an rcfile, got %v", cmdRunner.LastArgs)
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "secret123" {
+		t.Errorf("expected API_KEY=secret123, got %q", cmdRunner.LastSecrets["API_KEY"])
+	}
+	if cmdRunner.LastSecrets[ShellEnvVar] != "staging" {
+		t.Errorf("expected %s=staging, got %q", ShellEnvVar, cmdRunner.LastSecrets[ShellEnvVar])
+	}
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("expected no warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunShellWithDeps_APIError(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Vault not found"}
+
+	if err := runShellWithDeps(ShellOptions{EnvName: "staging", EnvFlagSet: true}, deps); err == nil {
+		t.Fatal("expected an error")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected no shell to be opened")
+	}
+}
+
+func TestShellLaunch_Bash(t *testing.T) {
+	dir := t.TempDir()
+	args, _, idle, err := shellLaunch("/usr/bin/bash", "production", 90*time.Second, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !idle {
+		t.Error("expected bash to support the idle timeout")
+	}
+	rc, err := os.ReadFile(args[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{". ~/.bashrc", "TMOUT=90", "(keyway:production)", `\e[31m`} {
+		if !strings.Contains(string(rc), want) {
+			t.Errorf("expected rcfile to contain %q, got:\n%s", want, rc)
+		}
+	}
+}
+
+func TestShellLaunch_Zsh(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ZDOTDIR", "/home/me/.config/zsh")
+	_, env, _, err := shellLaunch("/bin/zsh", "staging", 0, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["ZDOTDIR"] != dir {
+		t.Errorf("expected ZDOTDIR=%s, got %q", dir, env["ZDOTDIR"])
+	}
+	zshenv, _ := os.ReadFile(filepath.Join(dir, ".zshenv"))
+	if !strings.Contains(string(zshenv), "ZDOTDIR='/home/me/.config/zsh'") {
+		t.Errorf("expected .zshenv to source the user's files, got:\n%s", zshenv)
+	}
+	zshrc, _ := os.ReadFile(filepath.Join(dir, ".zshrc"))
+	if !strings.Contains(string(zshrc), "(keyway:staging)") || strings.Contains(string(zshrc), "TMOUT") {
+		t.Errorf("expected a marker and no idle timeout, got:\n%s", zshrc)
+	}
+}
+
+func TestShellLaunch_OtherShells(t *testing.T) {
+	_, env, idle, err := shellLaunch("/bin/dash", "staging", time.Minute, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idle {
+		t.Error("expected dash not to support the idle timeout")
+	}
+	if env["PS1"] != "(keyway:staging) $ " || env["TMOUT"] != "60" {
+		t.Errorf("expected PS1 and TMOUT, got %v", env)
+	}
+}
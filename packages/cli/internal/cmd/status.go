@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which local env files differ from the vault",
+	Long: `Compare every local env file with the vault environment it maps to.
+
+Each file is reported as:
+  in sync   local file matches the vault
+  behind    the vault has changes to pull
+  ahead     the local file has changes to push
+  diverged  both sides changed
+
+When a file was pulled before, its baseline tells which side changed a key.
+Otherwise keys whose values differ are reported as diverged.
+
+Examples:
+  keyway status
+  keyway status --check     # Exit non-zero if any file drifted (CI)
+  keyway status --json`,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().Bool("check", false, "Exit with a non-zero status if any file is out of sync")
+	statusCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// Drift states reported by keyway status
+const (
+	StatusInSync   = "in sync"
+	StatusBehind   = "behind"
+	StatusAhead    = "ahead"
+	StatusDiverged = "diverged"
+	StatusError    = "error"
+)
+
+// StatusOptions contains the parsed flags for the status command
+type StatusOptions struct {
+	Check      bool
+	JSONOutput bool
+}
+
+// FileStatus is the drift of one local env file against its vault environment
+type FileStatus struct {
+	File        string   `json:"file"`
+	Environment string   `json:"environment"`
+	State       string   `json:"state"`
+	Ahead       []string `json:"ahead"`
+	Behind      []string `json:"behind"`
+	Diverged    []string `json:"diverged"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// runStatus is the entry point for the status command (uses default dependencies)
+func runStatus(cmd *cobra.Command, args []string) error {
+	opts := StatusOptions{}
+	opts.Check, _ = cmd.Flags().GetBool("check")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runStatusWithDeps(opts, defaultDeps)
+}
+
+// runStatusWithDeps is the testable version of runStatus
+func runStatusWithDeps(opts StatusOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("status")
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	if !opts.JSONOutput {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	}
+
+	candidates := deps.Env.Discover()
+	if len(candidates) == 0 {
+		if opts.JSONOutput {
+			return printStatusJSON([]FileStatus{})
+		}
+		deps.UI.Warn("No env files found")
+		deps.UI.Message(deps.UI.Dim("Run keyway pull to download secrets"))
+		return nil
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	// Files mapping to the same environment share one download
+	var vaults map[string]map[string]string
+	fetch := func(envName string) (map[string]string, error) {
+		if secrets, ok := vaults[envName]; ok {
+			return secrets, nil
+		}
+		resp, err := client.PullSecrets(ctx, repo, envName)
+		if err != nil {
+			if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+				vaults[envName] = map[string]string{}
+				return vaults[envName], nil
+			}
+			return nil, err
+		}
+		// Local files hold resolved values, as pull writes them
+		secrets, _, err := resolveSecretRefs(ctx, client, repo, envName, env.Parse(resp.Content))
+		if err != nil {
+			if isAuthError(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to resolve references: %w", err)
+		}
+		vaults[envName] = secrets
+		return vaults[envName], nil
+	}
+
+	var statuses []FileStatus
+	compare := func() error {
+		vaults = make(map[string]map[string]string)
+		statuses = make([]FileStatus, 0, len(candidates))
+		for _, c := range candidates {
+			status := FileStatus{File: c.File, Environment: c.Env}
+
+			content, err := deps.FS.ReadFile(c.File)
+			if err != nil {
+				status.State = StatusError
+				status.Error = err.Error()
+				statuses = append(statuses, status)
+				continue
+			}
+
+			vaultSecrets, err := fetch(c.Env)
+			if err != nil {
+				if isAuthError(err) {
+					return err
+				}
+				status.State = StatusError
+				status.Error = err.Error()
+				statuses = append(statuses, status)
+				continue
+			}
+
+			baseline, err := deps.Baselines.Load(repo, c.Env, filepath.Join(".", c.File))
+			if err != nil {
+				baseline = nil
+			}
+
+			local := env.Parse(string(content))
+			if baseline != nil {
+				local = baseline.WithoutLayered(local)
+			}
+			classifyDrift(&status, local, vaultSecrets, baseline)
+			statuses = append(statuses, status)
+		}
+		return nil
+	}
+
+	err = deps.UI.Spin("Comparing env files with the vault...", compare)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = deps.UI.Spin("Comparing env files with the vault...", compare)
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	drifted := 0
+	for _, s := range statuses {
+		if s.State != StatusInSync {
+			drifted++
+		}
+	}
+
+	analytics.Track(analytics.EventStatus, map[string]interface{}{
+		"repoFullName": repo,
+		"files":        len(statuses),
+		"drifted":      drifted,
+		"check":        opts.Check,
+	})
+
+	if opts.JSONOutput {
+		if err := printStatusJSON(statuses); err != nil {
+			return err
+		}
+	} else {
+		printStatus(deps, statuses)
+		if drifted == 0 {
+			deps.UI.Success("All env files are in sync with the vault")
+		} else {
+			deps.UI.Warn(fmt.Sprintf("%d of %d env file(s) out of sync", drifted, len(statuses)))
+		}
+		deps.UI.Outro("")
+	}
+
+	if opts.Check && drifted > 0 {
+		return fmt.Errorf("%d env file(s) out of sync with the vault", drifted)
+	}
+	return nil
+}
+
+// classifyDrift fills in the drift state of a file. Without a baseline only
+// keys present on a single side can be attributed; keys with different values
+// are diverged since either side may have changed them.
+func classifyDrift(status *FileStatus, local, vault map[string]string, baseline *env.Baseline) {
+	status.Ahead, status.Behind, status.Diverged = []string{}, []string{}, []string{}
+
+	pull := env.CalculatePullDiff(local, vault)
+	if !pull.HasChanges() {
+		status.State = StatusInSync
+		return
+	}
+
+	if baseline != nil {
+		merge := env.ThreeWayMerge(baseline, local, vault, true)
+		status.Ahead = append(append(status.Ahead, merge.Pushed...), merge.Pruned...)
+		sort.Strings(status.Ahead)
+		status.Behind = append(status.Behind, merge.Kept...)
+		status.Diverged = append(status.Diverged, merge.Conflicts...)
+	} else {
+		push := env.CalculatePushDiff(local, vault)
+		status.Ahead = append(status.Ahead, push.Added...)
+		status.Behind = append(status.Behind, pull.Added...)
+		status.Diverged = append(status.Diverged, push.Changed...)
+	}
+
+	switch {
+	case len(status.Diverged) > 0 || (len(status.Ahead) > 0 && len(status.Behind) > 0):
+		status.State = StatusDiverged
+	case len(status.Ahead) > 0:
+		status.State = StatusAhead
+	default:
+		status.State = StatusBehind
+	}
+}
+
+// printStatus shows one block per file, git status style
+func printStatus(deps *Dependencies, statuses []FileStatus) {
+	for _, s := range statuses {
+		deps.UI.Message("")
+		deps.UI.Message(fmt.Sprintf("%s %s %s", deps.UI.File(s.File), deps.UI.Dim("→ "+s.Environment), deps.UI.Bold(s.State)))
+
+		if s.State == StatusError {
+			deps.UI.Message(deps.UI.Dim("  " + s.Error))
+			continue
+		}
+		if len(s.Ahead) > 0 {
+			deps.UI.Message(fmt.Sprintf("  Local changes: %s", strings.Join(s.Ahead, ", ")))
+		}
+		if len(s.Behind) > 0 {
+			deps.UI.Message(fmt.Sprintf("  Vault changes: %s", strings.Join(s.Behind, ", ")))
+		}
+		if len(s.Diverged) > 0 {
+			deps.UI.Message(fmt.Sprintf("  Changed on both sides: %s", strings.Join(s.Diverged, ", ")))
+		}
+
+		switch s.State {
+		case StatusBehind:
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("  Run keyway pull -e %s -f %s", s.Environment, s.File)))
+		case StatusAhead:
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("  Run keyway push -e %s -f %s", s.Environment, s.File)))
+		case StatusDiverged:
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("  Run keyway pull -e %s -f %s, then push", s.Environment, s.File)))
+		}
+	}
+	deps.UI.Message("")
+}
+
+func printStatusJSON(statuses []FileStatus) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(statuses)
+}
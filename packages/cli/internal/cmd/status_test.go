@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+)
+
+func TestClassifyDrift_WithoutBaseline(t *testing.T) {
+	tests := []struct {
+		name  string
+		local map[string]string
+		vault map[string]string
+		want  string
+	}{
+		{"in sync", map[string]string{"A": "1"}, map[string]string{"A": "1"}, StatusInSync},
+		{"behind", map[string]string{"A": "1"}, map[string]string{"A": "1", "B": "2"}, StatusBehind},
+		{"ahead", map[string]string{"A": "1", "B": "2"}, map[string]string{"A": "1"}, StatusAhead},
+		{"both sides", map[string]string{"A": "1", "B": "2"}, map[string]string{"A": "1", "C": "3"}, StatusDiverged},
+		{"changed value", map[string]string{"A": "1"}, map[string]string{"A": "2"}, StatusDiverged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status FileStatus
+			classifyDrift(&status, tt.local, tt.vault, nil)
+			if status.State != tt.want {
+				t.Errorf("State = %q, want %q", status.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyDrift_WithBaseline(t *testing.T) {
+	base, err := env.NewBaseline(map[string]string{"A": "old", "B": "old"})
+	if err != nil {
+		t.Fatalf("NewBaseline failed: %v", err)
+	}
+
+	var status FileStatus
+	classifyDrift(&status, map[string]string{"A": "old", "B": "old"}, map[string]string{"A": "new", "B": "old"}, base)
+	if status.State != StatusBehind || !reflect.DeepEqual(status.Behind, []string{"A"}) {
+		t.Errorf("expected A behind, got %+v", status)
+	}
+
+	status = FileStatus{}
+	classifyDrift(&status, map[string]string{"A": "mine", "B": "old"}, map[string]string{"A": "old", "B": "old"}, base)
+	if status.State != StatusAhead || !reflect.DeepEqual(status.Ahead, []string{"A"}) {
+		t.Errorf("expected A ahead, got %+v", status)
+	}
+
+	status = FileStatus{}
+	classifyDrift(&status, map[string]string{"A": "mine", "B": "old"}, map[string]string{"A": "theirs", "B": "old"}, base)
+	if status.State != StatusDiverged || !reflect.DeepEqual(status.Diverged, []string{"A"}) {
+		t.Errorf("expected A diverged, got %+v", status)
+	}
+}
+
+func TestRunStatusWithDeps_AllInSync(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	envMock.Candidates = []EnvCandidate{
+		{File: ".env", Env: "development"},
+		{File: ".env.production", Env: "production"},
+	}
+	fsMock.Files[".env"] = []byte("API_KEY=dev")
+	fsMock.Files[".env.production"] = []byte("API_KEY=prod")
+	apiMock.PullByEnv = map[string]string{"development": "API_KEY=dev", "production": "API_KEY=prod"}
+
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.SuccessCalls) != 1 {
+		t.Errorf("expected in-sync success, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunStatusWithDeps_ResolvesReferences(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	fsMock.Files[".env"] = []byte("API_KEY=dev\nSENTRY_DSN=https://key@sentry.io/1")
+	apiMock.PullByEnv = map[string]string{"development": "API_KEY=dev\nSENTRY_DSN=" + sentryRef}
+	apiMock.PullByRef = map[string]string{"acme/shared-infra/production": "SENTRY_DSN=https://key@sentry.io/1"}
+
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err != nil {
+		t.Fatalf("expected a pulled reference to be in sync, got %v", err)
+	}
+}
+
+func TestRunStatusWithDeps_LayeredPullInSync(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	apiMock.PullByEnv = map[string]string{
+		"base":        "LOG_LEVEL=info\nDB_URL=postgres://base",
+		"development": "DB_URL=postgres://dev",
+	}
+	pullOpts := PullOptions{EnvName: "development", BaseEnvs: []string{"base"}, File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(pullOpts, deps); err != nil {
+		t.Fatalf("pull: expected no error, got %v", err)
+	}
+	fsMock.Files[".env"] = fsMock.Written[".env"]
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err != nil {
+		t.Fatalf("expected the base environment's keys not to count as drift, got %v", err)
+	}
+
+	// Editing a key of the main environment is still a local change, and the
+	// base environment's keys are not pushed into it
+	fsMock.Files[".env"] = []byte("LOG_LEVEL=info\nDB_URL=postgres://mine")
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	pushOpts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(pushOpts, deps); err != nil {
+		t.Fatalf("push: expected no error, got %v", err)
+	}
+	if _, ok := apiMock.PushedSecrets["LOG_LEVEL"]; ok || apiMock.PushedSecrets["DB_URL"] != "postgres://mine" {
+		t.Errorf("expected only DB_URL to be pushed, got %v", apiMock.PushedSecrets)
+	}
+}
+
+func TestRunStatusWithDeps_CheckFailsOnDrift(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	envMock.Candidates = []EnvCandidate{
+		{File: ".env", Env: "development"},
+		{File: ".env.production", Env: "production"},
+	}
+	fsMock.Files[".env"] = []byte("API_KEY=dev")
+	fsMock.Files[".env.production"] = []byte("API_KEY=prod")
+	apiMock.PullByEnv = map[string]string{"development": "API_KEY=dev\nNEW=1", "production": "API_KEY=prod"}
+
+	if err := runStatusWithDeps(StatusOptions{}, deps); err != nil {
+		t.Fatalf("expected no error without --check, got %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 {
+		t.Errorf("expected drift warning, got %v", uiMock.WarnCalls)
+	}
+
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err == nil {
+		t.Error("expected --check to fail on drift")
+	}
+}
+
+func TestRunStatusWithDeps_MissingVaultEnvironment(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	envMock.Candidates = []EnvCandidate{{File: ".env.staging", Env: "staging"}}
+	fsMock.Files[".env.staging"] = []byte("API_KEY=x")
+	apiMock.PullByEnv = map[string]string{}
+
+	// A file whose environment doesn't exist yet is ahead, not an error
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err == nil {
+		t.Error("expected drift for an environment missing from the vault")
+	}
+}
+
+func TestRunStatusWithDeps_NoEnvFiles(t *testing.T) {
+	deps, _, _, uiMock, _, envMock, _ := NewTestDepsWithEnv()
+	envMock.Candidates = []EnvCandidate{}
+
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 {
+		t.Errorf("expected warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunStatusWithDeps_PullError(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	fsMock.Files[".env"] = []byte("API_KEY=x")
+	apiMock.PullError = errors.New("network down")
+
+	if err := runStatusWithDeps(StatusOptions{Check: true}, deps); err == nil {
+		t.Error("expected files that could not be compared to count as drift")
+	}
+}
@@ -878,6 +878,10 @@ func executeSyncOperation(client *api.Client, ctx context.Context, repo string,
 		return err
 	}
 
+	if direction == "pull" && result.Success {
+		evictAgentCache(defaultDeps, repo, keywayEnv)
+	}
+
 	if result.Success {
 		// Track sync event
 		analytics.Track(analytics.EventSync, map[string]interface{}{
@@ -0,0 +1,157 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// Client wraps an API client so that secrets of end-to-end encrypted vaults
+// are decrypted after they are pulled and encrypted before they are pushed.
+// Commands keep working with plaintext, as they do for other vaults.
+type Client struct {
+	api.APIClient
+
+	keyring Keyring
+
+	mu   sync.Mutex
+	keys map[string]*VaultKey // by repo; nil when the vault is not encrypted
+}
+
+// Keyring is the local end-to-end encryption state of the machine
+type Keyring interface {
+	// Load returns the identity of the current user
+	Load() (*Identity, error)
+	// IsRecorded reports whether repo is known to be encrypted
+	IsRecorded(repo string) (bool, error)
+	// Record remembers that repo is encrypted
+	Record(repo string) error
+}
+
+// NewClient wraps client. The identity is only loaded for encrypted vaults.
+func NewClient(client api.APIClient, keyring Keyring) *Client {
+	return &Client{
+		APIClient: client,
+		keyring:   keyring,
+		keys:      make(map[string]*VaultKey),
+	}
+}
+
+// vaultKey returns the key of an encrypted vault, or nil for other vaults.
+// e2eEnabled is what a pull response said, if there was one. The server is
+// not trusted to turn encryption off for a vault recorded as encrypted.
+func (c *Client) vaultKey(ctx context.Context, repo string, e2eEnabled *bool) (*VaultKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[repo]; ok {
+		return key, nil
+	}
+	recorded, err := c.keyring.IsRecorded(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the list of end-to-end encrypted vaults: %w", err)
+	}
+	if !recorded && e2eEnabled != nil && !*e2eEnabled {
+		c.keys[repo] = nil
+		return nil, nil
+	}
+
+	info, err := c.APIClient.GetVaultKeys(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if !info.E2EEnabled {
+		if recorded {
+			return nil, fmt.Errorf("%s uses end-to-end encryption on this machine but the server reports it doesn't - refusing to send or accept plaintext secrets", repo)
+		}
+		c.keys[repo] = nil
+		return nil, nil
+	}
+	if info.WrappedKey == "" {
+		return nil, fmt.Errorf("%s uses end-to-end encryption and its key hasn't been shared with you yet - run keyway e2e join, then ask a member to run keyway e2e grant", repo)
+	}
+
+	id, err := c.keyring.Load()
+	if err != nil {
+		return nil, fmt.Errorf("%s uses end-to-end encryption but your identity can't be loaded: %w", repo, err)
+	}
+	key, err := id.Unwrap(info.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		if err := c.keyring.Record(repo); err != nil {
+			return nil, fmt.Errorf("failed to record %s as end-to-end encrypted: %w", repo, err)
+		}
+	}
+	c.keys[repo] = key
+	return key, nil
+}
+
+// decrypt returns resp with its values decrypted when the vault is encrypted
+func (c *Client) decrypt(ctx context.Context, repo, env string, resp *api.PullSecretsResponse) (*api.PullSecretsResponse, error) {
+	if resp == nil {
+		return nil, nil
+	}
+	key, err := c.vaultKey(ctx, repo, &resp.E2EEnabled)
+	if err != nil || key == nil {
+		return resp, err
+	}
+	content, err := key.DecryptContent(env, resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	decrypted := *resp
+	decrypted.Content = content
+	return &decrypted, nil
+}
+
+// PullSecrets downloads secrets, decrypting them for encrypted vaults
+func (c *Client) PullSecrets(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+	resp, err := c.APIClient.PullSecrets(ctx, repo, env)
+	if err != nil {
+		return resp, err
+	}
+	return c.decrypt(ctx, repo, env, resp)
+}
+
+// PullSecretsIfChanged is PullSecrets for conditional requests
+func (c *Client) PullSecretsIfChanged(ctx context.Context, repo, env, revision string) (*api.PullSecretsResponse, error) {
+	resp, err := c.APIClient.PullSecretsIfChanged(ctx, repo, env, revision)
+	if err != nil {
+		return resp, err
+	}
+	return c.decrypt(ctx, repo, env, resp)
+}
+
+// PushSecrets uploads secrets, encrypting them first for encrypted vaults
+func (c *Client) PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
+	key, err := c.vaultKey(ctx, repo, nil)
+	if err != nil {
+		// Let the push itself report a missing vault, unless it must be encrypted
+		apiErr, ok := err.(*api.APIError)
+		if !ok || apiErr.StatusCode != 404 {
+			return nil, err
+		}
+		if recorded, _ := c.keyring.IsRecorded(repo); recorded {
+			return nil, err
+		}
+	}
+	if key != nil {
+		if secrets, err = key.EncryptSecrets(env, secrets); err != nil {
+			return nil, err
+		}
+	}
+	return c.APIClient.PushSecrets(ctx, repo, env, secrets)
+}
+
+// PutVaultKeys shares the vault key and forgets what is cached for the vault,
+// since enabling encryption changes how its secrets are pushed
+func (c *Client) PutVaultKeys(ctx context.Context, repo string, keys []api.WrappedKey, enable bool) error {
+	c.mu.Lock()
+	delete(c.keys, repo)
+	c.mu.Unlock()
+	return c.APIClient.PutVaultKeys(ctx, repo, keys, enable)
+}
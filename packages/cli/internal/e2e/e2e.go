@@ -0,0 +1,297 @@
+// Package e2e implements the zero-knowledge mode of a vault: values are
+// encrypted by the CLI with a vault key before they are pushed, so the server
+// only ever stores ciphertext. The vault key is shared between members by
+// sealing it to each member's public key.
+package e2e
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// ValuePrefix marks a value encrypted with a vault key (format version 1)
+const ValuePrefix = "kw1:"
+
+// Identity is a member's X25519 keypair. The private key never leaves the
+// machine; the public key is registered with each vault the member joins.
+type Identity struct {
+	public  [32]byte
+	private [32]byte
+}
+
+// NewIdentity generates a new keypair
+func NewIdentity() (*Identity, error) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	return &Identity{public: *pub, private: *priv}, nil
+}
+
+// PublicKey returns the base64-encoded public key
+func (id *Identity) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(id.public[:])
+}
+
+// IdentityPath is where the identity of the current user is kept
+func IdentityPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "keyway", "identity.key"), nil
+}
+
+// LoadIdentity reads an identity written by SaveIdentity. It returns
+// os.ErrNotExist (wrapped) when there is none yet.
+func LoadIdentity(path string) (*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(priv) != 32 {
+		return nil, fmt.Errorf("invalid identity in %s", path)
+	}
+	id := &Identity{}
+	copy(id.private[:], priv)
+	pub, err := curve25519.X25519(id.private[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity in %s: %w", path, err)
+	}
+	copy(id.public[:], pub)
+	return id, nil
+}
+
+// SaveIdentity writes the private key, readable by the current user only
+func SaveIdentity(path string, id *Identity) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(id.private[:]) + "\n"
+	return os.WriteFile(path, []byte(data), 0600)
+}
+
+// LoadOrCreateIdentity loads the identity at path, creating one if there is
+// none. created reports whether a new identity was written.
+func LoadOrCreateIdentity(path string) (id *Identity, created bool, err error) {
+	id, err = LoadIdentity(path)
+	if err == nil {
+		return id, false, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, false, err
+	}
+	id, err = NewIdentity()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := SaveIdentity(path, id); err != nil {
+		return nil, false, fmt.Errorf("failed to save identity: %w", err)
+	}
+	return id, true, nil
+}
+
+// VaultsPath is where the vaults known to be end-to-end encrypted are
+// recorded, next to the identity
+func VaultsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "keyway", "e2e-vaults"), nil
+}
+
+// IsRecordedVault reports whether repo was recorded by RecordVault. Once a
+// vault is recorded, the server can't make this machine exchange plaintext
+// with it by claiming it is not encrypted.
+func IsRecordedVault(path, repo string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), repo) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RecordVault records that repo is end-to-end encrypted, one repo per line
+func RecordVault(path, repo string) error {
+	recorded, err := IsRecordedVault(path, repo)
+	if err != nil || recorded {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strings.ToLower(repo) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// VaultKey is the symmetric key values of a vault are encrypted with
+type VaultKey [32]byte
+
+// NewVaultKey generates a random vault key
+func NewVaultKey() (*VaultKey, error) {
+	var k VaultKey
+	if _, err := rand.Read(k[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate vault key: %w", err)
+	}
+	return &k, nil
+}
+
+// Wrap seals the vault key to a member's base64 public key. Only the holder
+// of the matching private key can unwrap it.
+func (k *VaultKey) Wrap(publicKey string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(raw) != 32 {
+		return "", fmt.Errorf("invalid public key")
+	}
+	var pub [32]byte
+	copy(pub[:], raw)
+	sealed, err := box.SealAnonymous(nil, k[:], &pub, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap vault key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Unwrap opens a vault key sealed to this identity
+func (id *Identity) Unwrap(wrapped string) (*VaultKey, error) {
+	sealed, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped vault key")
+	}
+	raw, ok := box.OpenAnonymous(nil, sealed, &id.public, &id.private)
+	if !ok || len(raw) != 32 {
+		return nil, fmt.Errorf("cannot unwrap the vault key: it was shared with a different identity")
+	}
+	var k VaultKey
+	copy(k[:], raw)
+	return &k, nil
+}
+
+// IsEncrypted reports whether a value was encrypted with a vault key
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ValuePrefix)
+}
+
+// Encrypt encrypts the value of key name in environment envName. Values
+// that are already encrypted for that key are returned as is, so pushing what
+// was just pulled raw never double-encrypts.
+func (k *VaultKey) Encrypt(envName, name, value string) (string, error) {
+	if IsEncrypted(value) {
+		if _, err := k.Decrypt(envName, name, value); err != nil {
+			return "", err
+		}
+		return value, nil
+	}
+	return k.encrypt(envName, name, value)
+}
+
+// encrypt encrypts a value unconditionally
+func (k *VaultKey) encrypt(envName, name, value string) (string, error) {
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", fmt.Errorf("failed to encrypt value: %w", err)
+	}
+	sealed := secretbox.Seal(nonce[:], []byte(value), &nonce, k.valueKey(envName, name))
+	return ValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the value of key name in environment envName. A value
+// that is not encrypted, or was encrypted for another key or environment, is
+// an error: the server must not be able to hand out values of its choosing.
+func (k *VaultKey) Decrypt(envName, name, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("value is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, ValuePrefix))
+	if err != nil || len(sealed) < 24 {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed[:24])
+	plain, ok := secretbox.Open(nil, sealed[24:], &nonce, k.valueKey(envName, name))
+	if !ok {
+		return "", fmt.Errorf("cannot decrypt value: wrong vault key, or the ciphertext was tampered with or moved from another key")
+	}
+	return string(plain), nil
+}
+
+// valueKey derives the key one value is encrypted with, so that a ciphertext
+// only opens under the key name and environment it was encrypted for. Key
+// names and environment names never contain a NUL byte.
+func (k *VaultKey) valueKey(envName, name string) *[32]byte {
+	mac := hmac.New(sha256.New, k[:])
+	mac.Write([]byte("keyway value\x00" + envName + "\x00" + name))
+	var sub [32]byte
+	copy(sub[:], mac.Sum(nil))
+	return &sub
+}
+
+// EncryptSecrets returns a copy of the secrets of environment envName with
+// every value encrypted
+func (k *VaultKey) EncryptSecrets(envName string, secrets map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(secrets))
+	for key, value := range secrets {
+		enc, err := k.Encrypt(envName, key, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		out[key] = enc
+	}
+	return out, nil
+}
+
+// DecryptContent decrypts the values of env file content pulled from
+// environment envName, keeping its layout. Every value must be encrypted.
+func (k *VaultKey) DecryptContent(envName, content string) (string, error) {
+	values := make(map[string]string)
+	for key, value := range env.Parse(content) {
+		plain, err := k.Decrypt(envName, key, value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = plain
+	}
+	if len(values) == 0 {
+		return content, nil
+	}
+	return env.ReplaceValues(content, values), nil
+}
+
+// Fingerprint returns a short, human-comparable digest of a public key, so
+// members can check out of band that the key they share the vault with is
+// really their teammate's
+func Fingerprint(publicKey string) string {
+	sum := sha256.Sum256([]byte(publicKey))
+	h := hex.EncodeToString(sum[:8])
+	return h[0:4] + "-" + h[4:8] + "-" + h[8:12] + "-" + h[12:16]
+}
@@ -0,0 +1,307 @@
+package e2e
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func mustVaultKey(t *testing.T) *VaultKey {
+	t.Helper()
+	k, err := NewVaultKey()
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	return k
+}
+
+// testKeyring is a Keyring kept in memory
+type testKeyring struct {
+	load     func() (*Identity, error)
+	recorded map[string]bool
+}
+
+func (k *testKeyring) Load() (*Identity, error) { return k.load() }
+
+func (k *testKeyring) IsRecorded(repo string) (bool, error) { return k.recorded[repo], nil }
+
+func (k *testKeyring) Record(repo string) error {
+	if k.recorded == nil {
+		k.recorded = make(map[string]bool)
+	}
+	k.recorded[repo] = true
+	return nil
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := mustVaultKey(t)
+
+	enc, err := k.Encrypt("production", "DB_URL", "postgres://user:p@ss@db/app")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "postgres") {
+		t.Fatalf("unexpected ciphertext %q", enc)
+	}
+	if again, _ := k.Encrypt("production", "DB_URL", enc); again != enc {
+		t.Error("expected encrypted values to be left as is")
+	}
+
+	dec, err := k.Decrypt("production", "DB_URL", enc)
+	if err != nil || dec != "postgres://user:p@ss@db/app" {
+		t.Errorf("Decrypt = %q, %v", dec, err)
+	}
+	if _, err := k.Decrypt("production", "DB_URL", "legacy"); err == nil {
+		t.Error("expected error for a plaintext value")
+	}
+	if _, err := mustVaultKey(t).Decrypt("production", "DB_URL", enc); err == nil {
+		t.Error("expected error with the wrong key")
+	}
+}
+
+func TestDecrypt_BoundToKeyAndEnvironment(t *testing.T) {
+	k := mustVaultKey(t)
+	enc, _ := k.Encrypt("production", "DB_URL", "postgres://prod")
+
+	if _, err := k.Decrypt("production", "API_KEY", enc); err == nil {
+		t.Error("expected error for a ciphertext moved to another key")
+	}
+	if _, err := k.Decrypt("staging", "DB_URL", enc); err == nil {
+		t.Error("expected error for a ciphertext moved to another environment")
+	}
+	if _, err := k.Encrypt("staging", "DB_URL", enc); err == nil {
+		t.Error("expected error when pushing a ciphertext of another environment")
+	}
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	alice, _ := NewIdentity()
+	bob, _ := NewIdentity()
+	k := mustVaultKey(t)
+
+	wrapped, err := k.Wrap(alice.PublicKey())
+	if err != nil {
+		t.Fatalf("Wrap failed: %v", err)
+	}
+	got, err := alice.Unwrap(wrapped)
+	if err != nil || *got != *k {
+		t.Fatalf("Unwrap failed: %v", err)
+	}
+	if _, err := bob.Unwrap(wrapped); err == nil {
+		t.Error("expected another identity not to unwrap the key")
+	}
+	if _, err := k.Wrap("not-a-key"); err == nil {
+		t.Error("expected error for an invalid public key")
+	}
+}
+
+func TestIdentityPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyway", "identity.key")
+
+	id, created, err := LoadOrCreateIdentity(path)
+	if err != nil || !created {
+		t.Fatalf("expected a new identity, got created=%v err=%v", created, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected a 0600 identity file, got %v (%v)", info, err)
+	}
+
+	again, created, err := LoadOrCreateIdentity(path)
+	if err != nil || created {
+		t.Fatalf("expected the existing identity, got created=%v err=%v", created, err)
+	}
+	if again.PublicKey() != id.PublicKey() {
+		t.Error("expected the same public key after reloading")
+	}
+}
+
+func TestDecryptContent(t *testing.T) {
+	k := mustVaultKey(t)
+	a, _ := k.Encrypt("production", "A", "has space")
+	b, _ := k.Encrypt("production", "B", "plain")
+
+	got, err := k.DecryptContent("production", "# comment\nA="+a+"\nB="+b+"\n")
+	if err != nil {
+		t.Fatalf("DecryptContent failed: %v", err)
+	}
+	want := "# comment\nA=\"has space\"\nB=plain\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := k.DecryptContent("production", "A="+a+"\nC=plaintext\n"); err == nil || !strings.Contains(err.Error(), "C:") {
+		t.Errorf("expected error for a plaintext value, got %v", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	id, _ := NewIdentity()
+	k := mustVaultKey(t)
+	wrapped, _ := k.Wrap(id.PublicKey())
+	enc, _ := k.Encrypt("production", "API_KEY", "secret")
+
+	var pushed map[string]string
+	mock := &api.MockClient{
+		GetVaultKeysFn: func(ctx context.Context, repo string) (*api.VaultKeys, error) {
+			return &api.VaultKeys{E2EEnabled: true, WrappedKey: wrapped}, nil
+		},
+		PullSecretsFn: func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+			return &api.PullSecretsResponse{Content: "API_KEY=" + enc, E2EEnabled: true}, nil
+		},
+		PushSecretsFn: func(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
+			pushed = secrets
+			return &api.PushSecretsResponse{}, nil
+		},
+	}
+	keyring := &testKeyring{load: func() (*Identity, error) { return id, nil }}
+	client := NewClient(mock, keyring)
+	ctx := context.Background()
+
+	resp, err := client.PullSecrets(ctx, "owner/repo", "production")
+	if err != nil || resp.Content != "API_KEY=secret" {
+		t.Fatalf("PullSecrets = %+v, %v", resp, err)
+	}
+	if !keyring.recorded["owner/repo"] {
+		t.Error("expected the vault to be recorded as encrypted")
+	}
+
+	if _, err := client.PushSecrets(ctx, "owner/repo", "production", map[string]string{"API_KEY": "rotated"}); err != nil {
+		t.Fatalf("PushSecrets failed: %v", err)
+	}
+	if dec, _ := k.Decrypt("production", "API_KEY", pushed["API_KEY"]); !IsEncrypted(pushed["API_KEY"]) || dec != "rotated" {
+		t.Errorf("expected an encrypted push, got %q", pushed["API_KEY"])
+	}
+	if mock.Calls["GetVaultKeys"] != 1 {
+		t.Errorf("expected the vault key to be fetched once, got %d", mock.Calls["GetVaultKeys"])
+	}
+}
+
+func TestClient_PlainVault(t *testing.T) {
+	var pushed map[string]string
+	mock := &api.MockClient{
+		PushSecretsFn: func(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
+			pushed = secrets
+			return &api.PushSecretsResponse{}, nil
+		},
+	}
+	client := NewClient(mock, &testKeyring{load: func() (*Identity, error) {
+		t.Fatal("identity must not be loaded for plain vaults")
+		return nil, nil
+	}})
+	ctx := context.Background()
+
+	if _, err := client.PullSecrets(ctx, "owner/repo", "production"); err != nil {
+		t.Fatalf("PullSecrets failed: %v", err)
+	}
+	if _, err := client.PushSecrets(ctx, "owner/repo", "production", map[string]string{"K": "v"}); err != nil {
+		t.Fatalf("PushSecrets failed: %v", err)
+	}
+	if pushed["K"] != "v" {
+		t.Errorf("expected a plaintext push, got %q", pushed["K"])
+	}
+	if mock.Calls["GetVaultKeys"] != 0 {
+		t.Error("expected the pull to tell the vault is not encrypted")
+	}
+}
+
+func TestClient_NotGranted(t *testing.T) {
+	mock := &api.MockClient{
+		GetVaultKeysFn: func(ctx context.Context, repo string) (*api.VaultKeys, error) {
+			return &api.VaultKeys{E2EEnabled: true}, nil
+		},
+		PullSecretsFn: func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+			return &api.PullSecretsResponse{Content: "K=kw1:xxxx", E2EEnabled: true}, nil
+		},
+	}
+	client := NewClient(mock, &testKeyring{load: NewIdentity})
+
+	_, err := client.PullSecrets(context.Background(), "owner/repo", "production")
+	if err == nil || !strings.Contains(err.Error(), "keyway e2e join") {
+		t.Errorf("expected a not-granted error, got %v", err)
+	}
+}
+
+func TestClient_RecordedVaultRefusesPlaintext(t *testing.T) {
+	var pushed bool
+	mock := &api.MockClient{
+		PullSecretsFn: func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+			return &api.PullSecretsResponse{Content: "API_KEY=plaintext"}, nil
+		},
+		PushSecretsFn: func(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
+			pushed = true
+			return &api.PushSecretsResponse{}, nil
+		},
+	}
+	keyring := &testKeyring{load: NewIdentity, recorded: map[string]bool{"owner/repo": true}}
+	client := NewClient(mock, keyring)
+	ctx := context.Background()
+
+	if _, err := client.PullSecrets(ctx, "owner/repo", "production"); err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Errorf("expected the pull to be refused, got %v", err)
+	}
+	if _, err := client.PushSecrets(ctx, "owner/repo", "production", map[string]string{"API_KEY": "v"}); err == nil {
+		t.Error("expected the push to be refused")
+	}
+	if pushed {
+		t.Error("expected no plaintext to be pushed")
+	}
+
+	mock.GetVaultKeysFn = func(ctx context.Context, repo string) (*api.VaultKeys, error) {
+		return nil, &api.APIError{StatusCode: 404}
+	}
+	client = NewClient(mock, keyring)
+	if _, err := client.PushSecrets(ctx, "owner/repo", "production", map[string]string{"API_KEY": "v"}); err == nil || pushed {
+		t.Error("expected the push to be refused when the vault is missing")
+	}
+}
+
+func TestClient_RejectsPlaintextValues(t *testing.T) {
+	id, _ := NewIdentity()
+	k := mustVaultKey(t)
+	wrapped, _ := k.Wrap(id.PublicKey())
+	enc, _ := k.Encrypt("production", "API_KEY", "secret")
+
+	mock := &api.MockClient{
+		GetVaultKeysFn: func(ctx context.Context, repo string) (*api.VaultKeys, error) {
+			return &api.VaultKeys{E2EEnabled: true, WrappedKey: wrapped}, nil
+		},
+		PullSecretsFn: func(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+			return &api.PullSecretsResponse{Content: "API_KEY=" + enc + "\nINJECTED=plaintext", E2EEnabled: true}, nil
+		},
+	}
+	client := NewClient(mock, &testKeyring{load: func() (*Identity, error) { return id, nil }})
+
+	if _, err := client.PullSecrets(context.Background(), "owner/repo", "production"); err == nil || !strings.Contains(err.Error(), "INJECTED") {
+		t.Errorf("expected a plaintext value to be rejected, got %v", err)
+	}
+	if _, err := client.PullSecrets(context.Background(), "owner/repo", "staging"); err == nil {
+		t.Error("expected a value of another environment to be rejected")
+	}
+}
+
+func TestRecordVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyway", "e2e-vaults")
+
+	if recorded, err := IsRecordedVault(path, "Owner/Repo"); err != nil || recorded {
+		t.Fatalf("expected nothing recorded yet, got %v, %v", recorded, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := RecordVault(path, "Owner/Repo"); err != nil {
+			t.Fatalf("RecordVault failed: %v", err)
+		}
+	}
+	if recorded, err := IsRecordedVault(path, "owner/repo"); err != nil || !recorded {
+		t.Errorf("expected the vault to be recorded, got %v, %v", recorded, err)
+	}
+	if recorded, _ := IsRecordedVault(path, "owner/other"); recorded {
+		t.Error("expected another vault not to be recorded")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "owner/repo\n" {
+		t.Errorf("expected the vault to be recorded once, got %q", data)
+	}
+}
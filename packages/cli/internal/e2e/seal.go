@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+)
+
+const (
+	// sealedMarker is the first line of a sealed env file
+	sealedMarker = "# keyway-sealed: v1"
+	// recipientPrefix starts the header line holding the file key sealed to
+	// one recipient: "# keyway-recipient: <public key> <wrapped key>"
+	recipientPrefix = "# keyway-recipient: "
+	// sealedEnv stands for the environment of sealed values, which belong to
+	// a file rather than a vault environment
+	sealedEnv = ""
+)
+
+// IsSealed reports whether content is a sealed env file
+func IsSealed(content []byte) bool {
+	return strings.HasPrefix(string(content), sealedMarker)
+}
+
+// Seal encrypts the values of secrets with a new file key and seals that key
+// to each recipient's base64 public key. The result is an env file whose
+// keys stay readable, so it can be committed and reviewed, while only the
+// recipients can decrypt the values, without any network access.
+func Seal(secrets map[string]string, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("at least one recipient is required")
+	}
+	key, err := NewVaultKey()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(sealedMarker + "\n")
+	b.WriteString("# Values are encrypted. Decrypt with: keyway unseal <file>\n")
+
+	seen := make(map[string]bool)
+	for _, recipient := range recipients {
+		if seen[recipient] {
+			continue
+		}
+		seen[recipient] = true
+		wrapped, err := key.Wrap(recipient)
+		if err != nil {
+			return "", fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+		b.WriteString(recipientPrefix + recipient + " " + wrapped + "\n")
+	}
+	b.WriteString("\n")
+
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		enc, err := key.encrypt(sealedEnv, k, secrets[k])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(k + "=" + enc + "\n")
+	}
+	return b.String(), nil
+}
+
+// SealedRecipients returns the public keys a sealed env file was sealed to
+func SealedRecipients(content []byte) []string {
+	var recipients []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), recipientPrefix); ok {
+			if fields := strings.Fields(rest); len(fields) == 2 {
+				recipients = append(recipients, fields[0])
+			}
+		}
+	}
+	return recipients
+}
+
+// Unseal decrypts a sealed env file with the identity it was sealed to
+func Unseal(content []byte, id *Identity) (map[string]string, error) {
+	if !IsSealed(content) {
+		return nil, fmt.Errorf("not a sealed env file")
+	}
+
+	var key *VaultKey
+	for _, line := range strings.Split(string(content), "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), recipientPrefix)
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) != 2 || fields[0] != id.PublicKey() {
+			continue
+		}
+		if key, _ = id.Unwrap(fields[1]); key != nil {
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("this file was not sealed to your identity (%s)", Fingerprint(id.PublicKey()))
+	}
+
+	secrets := env.Parse(string(content))
+	for k, value := range secrets {
+		plain, err := key.Decrypt(sealedEnv, k, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		secrets[k] = plain
+	}
+	return secrets, nil
+}
@@ -0,0 +1,82 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSealUnseal(t *testing.T) {
+	alice, _ := NewIdentity()
+	bob, _ := NewIdentity()
+	secrets := map[string]string{"API_KEY": "sk_live_123", "MULTI": "a b\nc", "EMPTY": ""}
+
+	sealed, err := Seal(secrets, []string{alice.PublicKey(), bob.PublicKey(), alice.PublicKey()})
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsSealed([]byte(sealed)) {
+		t.Fatal("expected sealed content to be detected")
+	}
+	if strings.Contains(sealed, "sk_live_123") {
+		t.Fatal("sealed content contains a plaintext value")
+	}
+	if got := SealedRecipients([]byte(sealed)); len(got) != 2 {
+		t.Errorf("expected 2 recipients, got %v", got)
+	}
+
+	for name, id := range map[string]*Identity{"alice": alice, "bob": bob} {
+		got, err := Unseal([]byte(sealed), id)
+		if err != nil {
+			t.Fatalf("%s: Unseal() error = %v", name, err)
+		}
+		for k, want := range secrets {
+			if got[k] != want {
+				t.Errorf("%s: %s = %q, want %q", name, k, got[k], want)
+			}
+		}
+	}
+}
+
+func TestUnseal_NotARecipient(t *testing.T) {
+	alice, _ := NewIdentity()
+	eve, _ := NewIdentity()
+	sealed, _ := Seal(map[string]string{"A": "1"}, []string{alice.PublicKey()})
+
+	if _, err := Unseal([]byte(sealed), eve); err == nil || !strings.Contains(err.Error(), "not sealed to your identity") {
+		t.Errorf("expected not-a-recipient error, got %v", err)
+	}
+}
+
+func TestUnseal_Tampered(t *testing.T) {
+	alice, _ := NewIdentity()
+	sealed, _ := Seal(map[string]string{"A": "1"}, []string{alice.PublicKey()})
+
+	tampered := strings.Replace(sealed, "A=kw1:", "A=kw1:AAAA", 1)
+	if _, err := Unseal([]byte(tampered), alice); err == nil {
+		t.Error("expected error for tampered value")
+	}
+	swapped, _ := Seal(map[string]string{"A": "1", "B": "2"}, []string{alice.PublicKey()})
+	lines := strings.Split(swapped, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "A=") {
+			lines[i] = "B=" + strings.TrimPrefix(line, "A=")
+		} else if strings.HasPrefix(line, "B=") {
+			lines[i] = "A=" + strings.TrimPrefix(line, "B=")
+		}
+	}
+	if _, err := Unseal([]byte(strings.Join(lines, "\n")), alice); err == nil {
+		t.Error("expected error for values swapped between keys")
+	}
+	if _, err := Unseal([]byte("A=1\n"), alice); err == nil {
+		t.Error("expected error for plain env file")
+	}
+}
+
+func TestSeal_Errors(t *testing.T) {
+	if _, err := Seal(map[string]string{"A": "1"}, nil); err == nil {
+		t.Error("expected error without recipients")
+	}
+	if _, err := Seal(map[string]string{"A": "1"}, []string{"not-a-key"}); err == nil {
+		t.Error("expected error for invalid recipient")
+	}
+}
@@ -0,0 +1,298 @@
+package env
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Baseline is a snapshot of the vault taken at pull time. Values are never
+// stored: each one is kept as an HMAC under a random salt, so reading the file
+// doesn't disclose them and equal values in two baselines don't look alike.
+// The salt is stored next to the hashes, so anyone who can read the file can
+// still confirm a guess: short or guessable values are not protected. The
+// file is only written to the user's private config directory.
+type Baseline struct {
+	Salt   string            `json:"salt"`
+	Hashes map[string]string `json:"hashes"`
+	// Layered holds the keys that a layered pull took from base environments
+	// only, which are not part of the environment itself
+	Layered map[string]string `json:"layered,omitempty"`
+}
+
+// NewBaseline snapshots secrets under a fresh random salt.
+func NewBaseline(secrets map[string]string) (*Baseline, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	b := &Baseline{Salt: hex.EncodeToString(salt), Hashes: make(map[string]string, len(secrets))}
+	for k, v := range secrets {
+		b.Hashes[k] = b.Hash(v)
+	}
+	return b, nil
+}
+
+// PullBaseline snapshots secrets after a pull that kept the keys of kept, which
+// the vault doesn't have, in the local file. A kept key that prev, the baseline
+// of the previous sync, knew about was deleted from the vault since: it keeps
+// its previous state, so the next push sees a vault deletion rather than a
+// local addition. Other kept keys were added locally and are left out, to be
+// pushed as new.
+func PullBaseline(prev *Baseline, secrets, kept map[string]string) (*Baseline, error) {
+	if prev == nil {
+		return NewBaseline(secrets)
+	}
+	// Carried-over hashes are only meaningful under their own salt
+	b := &Baseline{Salt: prev.Salt, Hashes: make(map[string]string, len(secrets))}
+	for k, v := range secrets {
+		b.Hashes[k] = b.Hash(v)
+	}
+	for k := range kept {
+		if _, inVault := secrets[k]; inVault {
+			continue
+		}
+		if hash, ok := prev.Hashes[k]; ok {
+			b.Hashes[k] = hash
+		}
+	}
+	return b, nil
+}
+
+// SetLayered records the keys that a layered pull took from base
+// environments only.
+func (b *Baseline) SetLayered(secrets map[string]string) {
+	b.Layered = nil
+	for k, v := range secrets {
+		if b.Layered == nil {
+			b.Layered = make(map[string]string, len(secrets))
+		}
+		b.Layered[k] = b.Hash(v)
+	}
+}
+
+// WithoutLayered returns local without the keys that a layered pull took from
+// base environments, unless they were edited since: those belong to the
+// base environments, not to this one.
+func (b *Baseline) WithoutLayered(local map[string]string) map[string]string {
+	if len(b.Layered) == 0 {
+		return local
+	}
+	own := make(map[string]string, len(local))
+	for k, v := range local {
+		if hash, ok := b.Layered[k]; ok && hmac.Equal([]byte(hash), []byte(b.Hash(v))) {
+			continue
+		}
+		own[k] = v
+	}
+	return own
+}
+
+// Hash returns the salted hash of a value.
+func (b *Baseline) Hash(value string) string {
+	mac := hmac.New(sha256.New, []byte(b.Salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// changed reports whether a key's presence or value differs from the baseline.
+func (b *Baseline) changed(key, value string, present bool) bool {
+	hash, inBase := b.Hashes[key]
+	if !present {
+		return inBase
+	}
+	return !inBase || !hmac.Equal([]byte(hash), []byte(b.Hash(value)))
+}
+
+// MergeResult is the outcome of a three-way merge between the baseline,
+// the local file and the current vault.
+type MergeResult struct {
+	Result    map[string]string // what the vault should contain after the push
+	Pushed    []string          // changed locally only: local value wins
+	Kept      []string          // changed in the vault only: vault value is kept
+	Conflicts []string          // changed on both sides to different values
+	Pruned    []string          // unchanged in the vault and removed locally (only with prune)
+	local     map[string]string
+}
+
+// ThreeWayMerge merges local changes into the vault state using the baseline
+// recorded at the last pull. Keys missing locally are only removed when prune
+// is set, mirroring the additive default of push. Conflicts are left at their
+// vault value until resolved with Resolve.
+func ThreeWayMerge(base *Baseline, local, remote map[string]string, prune bool) *MergeResult {
+	m := &MergeResult{
+		Result: make(map[string]string, len(remote)),
+		local:  local,
+	}
+	for k, v := range remote {
+		m.Result[k] = v
+	}
+
+	keys := make(map[string]bool)
+	for k := range local {
+		keys[k] = true
+	}
+	for k := range remote {
+		keys[k] = true
+	}
+
+	for key := range keys {
+		localVal, inLocal := local[key]
+		remoteVal, inRemote := remote[key]
+
+		if inLocal && inRemote && localVal == remoteVal {
+			continue
+		}
+
+		localChanged := base.changed(key, localVal, inLocal)
+		remoteChanged := base.changed(key, remoteVal, inRemote)
+
+		switch {
+		case !inLocal && !prune:
+			// Additive push: a key missing locally never touches the vault
+			if remoteChanged {
+				m.Kept = append(m.Kept, key)
+			}
+		case localChanged && !remoteChanged:
+			if inLocal {
+				m.Result[key] = localVal
+				m.Pushed = append(m.Pushed, key)
+			} else {
+				delete(m.Result, key)
+				m.Pruned = append(m.Pruned, key)
+			}
+		case !localChanged && remoteChanged:
+			m.Kept = append(m.Kept, key)
+		case localChanged && remoteChanged:
+			if !inLocal {
+				// Deleted locally but edited in the vault: the edit wins
+				m.Kept = append(m.Kept, key)
+				continue
+			}
+			m.Conflicts = append(m.Conflicts, key)
+		}
+	}
+
+	sort.Strings(m.Pushed)
+	sort.Strings(m.Kept)
+	sort.Strings(m.Conflicts)
+	sort.Strings(m.Pruned)
+	return m
+}
+
+// Resolve settles a conflict in favour of the local value (useLocal) or the vault value.
+func (m *MergeResult) Resolve(key string, useLocal bool) {
+	if !useLocal {
+		return
+	}
+	if v, ok := m.local[key]; ok {
+		m.Result[key] = v
+	}
+}
+
+// Baseline returns the baseline to record once the result has been pushed.
+// Keys where the local file now matches the vault take their new state; the
+// others keep their previous state so they still show up as vault changes on
+// the next push instead of looking like local edits.
+func (m *MergeResult) Baseline(base *Baseline) *Baseline {
+	next := &Baseline{Salt: base.Salt, Hashes: make(map[string]string, len(m.Result)), Layered: base.Layered}
+	for k, h := range base.Hashes {
+		next.Hashes[k] = h
+	}
+
+	keys := make(map[string]bool)
+	for k := range m.Result {
+		keys[k] = true
+	}
+	for k := range base.Hashes {
+		keys[k] = true
+	}
+	for key := range keys {
+		localVal, inLocal := m.local[key]
+		resultVal, inResult := m.Result[key]
+		switch {
+		case inLocal && inResult && localVal == resultVal:
+			next.Hashes[key] = base.Hash(resultVal)
+		case !inLocal && !inResult:
+			delete(next.Hashes, key)
+		}
+	}
+	return next
+}
+
+// baselineFile is the on-disk form of a baseline
+type baselineFile struct {
+	Repo        string `json:"repo"`
+	Environment string `json:"environment"`
+	File        string `json:"file"`
+	PulledAt    string `json:"pulledAt"`
+	Baseline
+}
+
+// baselinePath returns where the baseline for a repo, environment and local
+// file is kept. Baselines live in the user config dir, never in the repo.
+func baselinePath(repo, envName, file string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	sum := sha256.Sum256([]byte(repo + "\x00" + envName + "\x00" + file))
+	name := hex.EncodeToString(sum[:16]) + ".json"
+	return filepath.Join(home, ".config", "keyway", "baselines", name), nil
+}
+
+// LoadBaseline returns the baseline recorded by the last pull of envName into
+// file, or nil if there is none.
+func LoadBaseline(repo, envName, file string) (*Baseline, error) {
+	path, err := baselinePath(repo, envName, file)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stored baselineFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Hashes == nil {
+		stored.Hashes = make(map[string]string)
+	}
+	return &stored.Baseline, nil
+}
+
+// SaveBaseline records the vault state of envName as the baseline for file.
+func SaveBaseline(repo, envName, file string, b *Baseline) error {
+	path, err := baselinePath(repo, envName, file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(baselineFile{
+		Repo:        repo,
+		Environment: envName,
+		File:        file,
+		PulledAt:    time.Now().UTC().Format(time.RFC3339),
+		Baseline:    *b,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,255 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustBaseline(t *testing.T, secrets map[string]string) *Baseline {
+	t.Helper()
+	b, err := NewBaseline(secrets)
+	if err != nil {
+		t.Fatalf("NewBaseline failed: %v", err)
+	}
+	return b
+}
+
+func TestNewBaseline_DoesNotStoreValues(t *testing.T) {
+	b := mustBaseline(t, map[string]string{"API_KEY": "super-secret"})
+
+	if len(b.Salt) != 32 {
+		t.Errorf("expected 16-byte hex salt, got %q", b.Salt)
+	}
+	if strings.Contains(b.Hashes["API_KEY"], "super-secret") {
+		t.Error("baseline must not contain the raw value")
+	}
+	if b.Hashes["API_KEY"] != b.Hash("super-secret") {
+		t.Error("expected hash to be reproducible with the same salt")
+	}
+}
+
+func TestNewBaseline_SaltDiffers(t *testing.T) {
+	a := mustBaseline(t, map[string]string{"K": "v"})
+	b := mustBaseline(t, map[string]string{"K": "v"})
+	if a.Hashes["K"] == b.Hashes["K"] {
+		t.Error("expected different salts to produce different hashes")
+	}
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	base := map[string]string{
+		"SAME":          "1",
+		"LOCAL_EDIT":    "old",
+		"REMOTE_EDIT":   "old",
+		"BOTH_SAME":     "old",
+		"CONFLICT":      "old",
+		"LOCAL_DELETE":  "x",
+		"REMOTE_DELETE": "x",
+	}
+	local := map[string]string{
+		"SAME":          "1",
+		"LOCAL_EDIT":    "new",
+		"REMOTE_EDIT":   "old",
+		"BOTH_SAME":     "new",
+		"CONFLICT":      "mine",
+		"REMOTE_DELETE": "x",
+		"LOCAL_ADD":     "l",
+	}
+	remote := map[string]string{
+		"SAME":         "1",
+		"LOCAL_EDIT":   "old",
+		"REMOTE_EDIT":  "theirs",
+		"BOTH_SAME":    "new",
+		"CONFLICT":     "theirs",
+		"LOCAL_DELETE": "x",
+		"REMOTE_ADD":   "r",
+	}
+
+	m := ThreeWayMerge(mustBaseline(t, base), local, remote, false)
+
+	if !reflect.DeepEqual(m.Pushed, []string{"LOCAL_ADD", "LOCAL_EDIT"}) {
+		t.Errorf("Pushed = %v", m.Pushed)
+	}
+	if !reflect.DeepEqual(m.Kept, []string{"REMOTE_ADD", "REMOTE_DELETE", "REMOTE_EDIT"}) {
+		t.Errorf("Kept = %v", m.Kept)
+	}
+	if !reflect.DeepEqual(m.Conflicts, []string{"CONFLICT"}) {
+		t.Errorf("Conflicts = %v", m.Conflicts)
+	}
+	if len(m.Pruned) != 0 {
+		t.Errorf("expected nothing pruned without prune, got %v", m.Pruned)
+	}
+
+	want := map[string]string{
+		"SAME":         "1",
+		"LOCAL_EDIT":   "new",
+		"REMOTE_EDIT":  "theirs",
+		"BOTH_SAME":    "new",
+		"CONFLICT":     "theirs", // unresolved conflicts stay at the vault value
+		"LOCAL_DELETE": "x",      // additive push keeps it
+		"REMOTE_ADD":   "r",
+		"LOCAL_ADD":    "l",
+	}
+	if !reflect.DeepEqual(m.Result, want) {
+		t.Errorf("Result = %v, want %v", m.Result, want)
+	}
+}
+
+func TestThreeWayMerge_Prune(t *testing.T) {
+	base := map[string]string{"GONE": "x", "EDITED": "x"}
+	local := map[string]string{}
+	remote := map[string]string{"GONE": "x", "EDITED": "y", "NEW": "z"}
+
+	m := ThreeWayMerge(mustBaseline(t, base), local, remote, true)
+
+	if !reflect.DeepEqual(m.Pruned, []string{"GONE"}) {
+		t.Errorf("Pruned = %v", m.Pruned)
+	}
+	// Changed or added in the vault since the pull: never pruned
+	if !reflect.DeepEqual(m.Kept, []string{"EDITED", "NEW"}) {
+		t.Errorf("Kept = %v", m.Kept)
+	}
+	if _, ok := m.Result["GONE"]; ok {
+		t.Error("expected GONE to be removed from the result")
+	}
+}
+
+func TestThreeWayMerge_RemoteDeletedLocalEdited(t *testing.T) {
+	base := map[string]string{"K": "old"}
+	m := ThreeWayMerge(mustBaseline(t, base), map[string]string{"K": "new"}, map[string]string{}, false)
+
+	if !reflect.DeepEqual(m.Conflicts, []string{"K"}) {
+		t.Fatalf("Conflicts = %v", m.Conflicts)
+	}
+	if _, ok := m.Result["K"]; ok {
+		t.Error("expected unresolved conflict to keep the vault state (deleted)")
+	}
+
+	m.Resolve("K", true)
+	if m.Result["K"] != "new" {
+		t.Errorf("expected local value after resolving, got %q", m.Result["K"])
+	}
+}
+
+func TestThreeWayMerge_BothAddedDifferently(t *testing.T) {
+	m := ThreeWayMerge(mustBaseline(t, nil), map[string]string{"K": "a"}, map[string]string{"K": "b"}, false)
+	if !reflect.DeepEqual(m.Conflicts, []string{"K"}) {
+		t.Errorf("Conflicts = %v", m.Conflicts)
+	}
+
+	m.Resolve("K", false)
+	if m.Result["K"] != "b" {
+		t.Errorf("expected vault value to be kept, got %q", m.Result["K"])
+	}
+}
+
+func TestMergeResult_Baseline(t *testing.T) {
+	base := mustBaseline(t, map[string]string{"LOCAL": "old", "REMOTE": "old", "GONE": "x"})
+	local := map[string]string{"LOCAL": "new", "REMOTE": "old"}
+	remote := map[string]string{"LOCAL": "old", "REMOTE": "theirs", "GONE": "x"}
+
+	m := ThreeWayMerge(base, local, remote, true)
+	next := m.Baseline(base)
+
+	if next.Hashes["LOCAL"] != base.Hash("new") {
+		t.Error("expected pushed key to take the new value")
+	}
+	if next.Hashes["REMOTE"] != base.Hash("old") {
+		t.Error("expected kept key to keep its old baseline")
+	}
+	if _, ok := next.Hashes["GONE"]; ok {
+		t.Error("expected pruned key to leave the baseline")
+	}
+
+	// The stale local value must not be pushed over the vault next time
+	again := ThreeWayMerge(next, local, m.Result, false)
+	if len(again.Pushed) != 0 || !reflect.DeepEqual(again.Kept, []string{"REMOTE"}) {
+		t.Errorf("Pushed = %v, Kept = %v", again.Pushed, again.Kept)
+	}
+}
+
+func TestPullBaseline_RemoteDeletionIsNotPushedBack(t *testing.T) {
+	prev := mustBaseline(t, map[string]string{"A": "1", "X": "gone"})
+	remote := map[string]string{"A": "1"}
+	local := map[string]string{"A": "1", "X": "gone", "NEW": "mine"}
+
+	next, err := PullBaseline(prev, remote, local)
+	if err != nil {
+		t.Fatalf("PullBaseline failed: %v", err)
+	}
+	if next.Hashes["X"] != prev.Hashes["X"] {
+		t.Error("expected the remotely deleted key to keep its previous state")
+	}
+	if _, ok := next.Hashes["NEW"]; ok {
+		t.Error("expected the local addition to stay out of the baseline")
+	}
+
+	m := ThreeWayMerge(next, local, remote, false)
+	if !reflect.DeepEqual(m.Pushed, []string{"NEW"}) || !reflect.DeepEqual(m.Kept, []string{"X"}) {
+		t.Errorf("Pushed = %v, Kept = %v", m.Pushed, m.Kept)
+	}
+	if _, ok := m.Result["X"]; ok {
+		t.Error("expected the remote deletion to stand")
+	}
+
+	// Editing the key after the deletion is a conflict
+	local["X"] = "edited"
+	m = ThreeWayMerge(next, local, remote, false)
+	if !reflect.DeepEqual(m.Conflicts, []string{"X"}) {
+		t.Errorf("Conflicts = %v", m.Conflicts)
+	}
+}
+
+func TestPullBaseline_NoPrevious(t *testing.T) {
+	next, err := PullBaseline(nil, map[string]string{"A": "1"}, map[string]string{"X": "local"})
+	if err != nil {
+		t.Fatalf("PullBaseline failed: %v", err)
+	}
+	if len(next.Hashes) != 1 || next.Hashes["A"] != next.Hash("1") {
+		t.Errorf("expected a snapshot of the vault only, got %v", next.Hashes)
+	}
+}
+
+func TestBaseline_WithoutLayered(t *testing.T) {
+	b := mustBaseline(t, map[string]string{"DB_URL": "postgres://dev"})
+	b.SetLayered(map[string]string{"LOG_LEVEL": "info", "REGION": "eu"})
+
+	local := map[string]string{"DB_URL": "postgres://dev", "LOG_LEVEL": "info", "REGION": "us"}
+	got := b.WithoutLayered(local)
+	want := map[string]string{"DB_URL": "postgres://dev", "REGION": "us"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WithoutLayered = %v, want %v (edited base keys are kept)", got, want)
+	}
+
+	b.SetLayered(nil)
+	if b.Layered != nil {
+		t.Errorf("expected no layered keys, got %v", b.Layered)
+	}
+}
+
+func TestSaveLoadBaseline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	got, err := LoadBaseline("owner/repo", "development", ".env")
+	if err != nil || got != nil {
+		t.Fatalf("expected no baseline, got %v, %v", got, err)
+	}
+
+	b := mustBaseline(t, map[string]string{"K": "v"})
+	if err := SaveBaseline("owner/repo", "development", ".env", b); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+
+	got, err = LoadBaseline("owner/repo", "development", ".env")
+	if err != nil {
+		t.Fatalf("LoadBaseline failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("got %+v, want %+v", got, b)
+	}
+
+	if other, _ := LoadBaseline("owner/repo", "production", ".env"); other != nil {
+		t.Error("expected baselines to be scoped per environment")
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"strings"
 )
 
+// SealedSuffix is the extension of env files sealed with keyway seal
+const SealedSuffix = ".sealed"
+
 // Candidate represents a discovered .env file with its derived environment.
 type Candidate struct {
 	File string
@@ -13,7 +16,8 @@ type Candidate struct {
 }
 
 // Discover finds .env files in the current directory.
-// It excludes template files like .env.example, .env.sample, etc.
+// It excludes template files like .env.example, .env.sample, etc. and sealed
+// files, which are only read when named explicitly.
 func Discover() []Candidate {
 	entries, err := os.ReadDir(".")
 	if err != nil {
@@ -30,7 +34,7 @@ func Discover() []Candidate {
 	var candidates []Candidate
 	for _, entry := range entries {
 		name := entry.Name()
-		if strings.HasPrefix(name, ".env") && !excludeFiles[name] && !entry.IsDir() {
+		if strings.HasPrefix(name, ".env") && !excludeFiles[name] && !strings.HasSuffix(name, SealedSuffix) && !entry.IsDir() {
 			candidates = append(candidates, Candidate{
 				File: name,
 				Env:  DeriveEnvFromFile(name),
@@ -73,8 +77,9 @@ func NormalizeEnvName(name string) string {
 //   - ".env.local" -> "development"
 //   - ".env.production" -> "production"
 //   - ".env.staging" -> "staging"
+//   - ".env.production.sealed" -> "production"
 func DeriveEnvFromFile(file string) string {
-	base := filepath.Base(file)
+	base := strings.TrimSuffix(filepath.Base(file), SealedSuffix)
 	if base == ".env" {
 		return "development"
 	}
@@ -20,6 +20,8 @@ func TestDeriveEnvFromFile(t *testing.T) {
 		{".env.custom", "custom"},
 		{"path/to/.env", "development"},
 		{"path/to/.env.production", "production"},
+		{".env.sealed", "development"},
+		{".env.production.sealed", "production"},
 	}
 
 	for _, tt := range tests {
@@ -41,7 +43,7 @@ func TestDiscover(t *testing.T) {
 	defer os.RemoveAll(tmpDir)
 
 	// Create test files
-	testFiles := []string{".env", ".env.production", ".env.staging", "not-env.txt"}
+	testFiles := []string{".env", ".env.production", ".env.staging", ".env.production.sealed", "not-env.txt"}
 	for _, f := range testFiles {
 		path := filepath.Join(tmpDir, f)
 		if err := os.WriteFile(path, []byte("TEST=value"), 0644); err != nil {
@@ -56,7 +58,7 @@ func TestDiscover(t *testing.T) {
 
 	candidates := Discover()
 
-	// Should find .env files but not .env.local or non-env files
+	// Should find .env files but not sealed or non-env files
 	if len(candidates) != 3 {
 		t.Errorf("expected 3 env files, got %d: %v", len(candidates), candidates)
 	}
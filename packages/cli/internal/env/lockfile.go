@@ -0,0 +1,194 @@
+package env
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// LockfileName is the file keyway pull writes next to the env files
+const LockfileName = ".keyway.lock"
+
+// lockfileVersion is the format version this CLI writes. Version 1 used a
+// plain HMAC, which is fast to brute-force; it is still read and verified.
+const lockfileVersion = 2
+
+// Cost of the Argon2id fingerprints of version 2 (the OWASP minimum): each
+// guess at a value costs an attacker reading the lockfile as much as it costs
+// keyway to fingerprint it
+const (
+	lockArgonTime    = 2
+	lockArgonMemory  = 19 * 1024 // KiB
+	lockArgonThreads = 1
+)
+
+// Lockfile records fingerprints of pulled secrets so it can be committed.
+// Each value is hashed with Argon2id, salted with the lockfile salt and the
+// key name, so equal values under different keys don't look alike and every
+// guess at a value is slow. Short or guessable values can still be found by
+// a patient attacker who can read the lockfile.
+type Lockfile struct {
+	Version      int                           `json:"version"`
+	Salt         string                        `json:"salt"`
+	Environments map[string]*LockedEnvironment `json:"environments"`
+}
+
+// LockedEnvironment is the state of one environment at the last pull
+type LockedEnvironment struct {
+	Revision string            `json:"revision,omitempty"`
+	Secrets  map[string]string `json:"secrets"`
+}
+
+// LockDiff lists keys whose vault state no longer matches the lockfile
+type LockDiff struct {
+	Added   []string // in the vault, not in the lockfile
+	Changed []string // value differs from the locked fingerprint
+	Removed []string // in the lockfile, not in the vault
+}
+
+// HasChanges returns true if there are any differences.
+func (d *LockDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Changed) > 0 || len(d.Removed) > 0
+}
+
+// NewLockfile returns an empty lockfile with a fresh random salt.
+func NewLockfile() (*Lockfile, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &Lockfile{
+		Version:      lockfileVersion,
+		Salt:         hex.EncodeToString(salt),
+		Environments: make(map[string]*LockedEnvironment),
+	}, nil
+}
+
+// ParseLockfile reads a lockfile written by Marshal.
+func ParseLockfile(data []byte) (*Lockfile, error) {
+	var l Lockfile
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", LockfileName, err)
+	}
+	if l.Version > lockfileVersion {
+		return nil, fmt.Errorf("%s version %d is newer than this CLI supports - please upgrade", LockfileName, l.Version)
+	}
+	if l.Salt == "" {
+		return nil, fmt.Errorf("invalid %s: missing salt", LockfileName)
+	}
+	if l.Environments == nil {
+		l.Environments = make(map[string]*LockedEnvironment)
+	}
+	return &l, nil
+}
+
+// Marshal returns the lockfile as indented JSON with sorted keys, so changes
+// show up as one line per secret in a diff.
+func (l *Lockfile) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Outdated reports whether the lockfile was written with a weaker format
+// than this CLI writes.
+func (l *Lockfile) Outdated() bool {
+	return l.Version < lockfileVersion
+}
+
+// Hash returns the fingerprint of a secret value under this lockfile's salt.
+func (l *Lockfile) Hash(key, value string) string {
+	if l.Version < 2 {
+		mac := hmac.New(sha256.New, []byte(l.Salt))
+		mac.Write([]byte(key))
+		mac.Write([]byte{0})
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	salt := []byte(l.Salt + "\x00" + key)
+	return hex.EncodeToString(argon2.IDKey([]byte(value), salt, lockArgonTime, lockArgonMemory, lockArgonThreads, 32))
+}
+
+// hashAll fingerprints secrets, spreading the slow hashes over the CPUs.
+func (l *Lockfile) hashAll(secrets map[string]string) map[string]string {
+	keys := make(chan string)
+	hashes := make(map[string]string, len(secrets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range keys {
+				hash := l.Hash(k, secrets[k])
+				mu.Lock()
+				hashes[k] = hash
+				mu.Unlock()
+			}
+		}()
+	}
+	for k := range secrets {
+		keys <- k
+	}
+	close(keys)
+	wg.Wait()
+	return hashes
+}
+
+// Record replaces the locked state of an environment.
+func (l *Lockfile) Record(envName, revision string, secrets map[string]string) {
+	l.Environments[envName] = &LockedEnvironment{Revision: revision, Secrets: l.hashAll(secrets)}
+}
+
+// Verify compares the current secrets of an environment with the lockfile.
+func (l *Lockfile) Verify(envName string, secrets map[string]string) (*LockDiff, error) {
+	locked, ok := l.Environments[envName]
+	if !ok {
+		return nil, fmt.Errorf("environment %q is not in %s", envName, LockfileName)
+	}
+
+	present := make(map[string]string, len(secrets))
+	diff := &LockDiff{}
+	for k, v := range secrets {
+		if _, exists := locked.Secrets[k]; exists {
+			present[k] = v
+		} else {
+			diff.Added = append(diff.Added, k)
+		}
+	}
+	for k, hash := range l.hashAll(present) {
+		if !hmac.Equal([]byte(locked.Secrets[k]), []byte(hash)) {
+			diff.Changed = append(diff.Changed, k)
+		}
+	}
+	for k := range locked.Secrets {
+		if _, exists := secrets[k]; !exists {
+			diff.Removed = append(diff.Removed, k)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+// EnvironmentNames returns the locked environments in sorted order.
+func (l *Lockfile) EnvironmentNames() []string {
+	names := make([]string, 0, len(l.Environments))
+	for name := range l.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
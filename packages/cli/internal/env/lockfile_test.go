@@ -0,0 +1,105 @@
+package env
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLockfile_RoundTripWithoutPlaintext(t *testing.T) {
+	l, err := NewLockfile()
+	if err != nil {
+		t.Fatalf("NewLockfile failed: %v", err)
+	}
+	l.Record("production", "rev1", map[string]string{"API_KEY": "super-secret"})
+
+	data, err := l.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "super-secret") {
+		t.Error("lockfile must not contain secret values")
+	}
+
+	parsed, err := ParseLockfile(data)
+	if err != nil {
+		t.Fatalf("ParseLockfile failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, l) {
+		t.Errorf("got %+v, want %+v", parsed, l)
+	}
+}
+
+func TestLockfile_HashDependsOnKey(t *testing.T) {
+	l, _ := NewLockfile()
+	if l.Hash("A", "same") == l.Hash("B", "same") {
+		t.Error("expected equal values under different keys to hash differently")
+	}
+}
+
+func TestLockfile_Verify(t *testing.T) {
+	l, _ := NewLockfile()
+	l.Record("production", "rev1", map[string]string{"SAME": "1", "EDITED": "old", "GONE": "x"})
+
+	diff, err := l.Verify("production", map[string]string{"SAME": "1", "EDITED": "new", "NEW": "y"})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !reflect.DeepEqual(diff.Added, []string{"NEW"}) ||
+		!reflect.DeepEqual(diff.Changed, []string{"EDITED"}) ||
+		!reflect.DeepEqual(diff.Removed, []string{"GONE"}) {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+
+	if _, err := l.Verify("staging", nil); err == nil {
+		t.Error("expected error for an environment missing from the lockfile")
+	}
+}
+
+func TestLockfile_Version1StillVerifies(t *testing.T) {
+	// Written by an older CLI: HMAC-SHA256("ab", "K\x00v")
+	l, err := ParseLockfile([]byte(`{"version": 1, "salt": "ab", "environments": {"dev": {"secrets": {"K": "` + legacyHash("ab", "K", "v") + `"}}}}`))
+	if err != nil {
+		t.Fatalf("ParseLockfile failed: %v", err)
+	}
+	if !l.Outdated() {
+		t.Error("expected a version 1 lockfile to be outdated")
+	}
+	diff, err := l.Verify("dev", map[string]string{"K": "v"})
+	if err != nil || diff.HasChanges() {
+		t.Errorf("expected no changes, got %+v, %v", diff, err)
+	}
+
+	fresh, _ := NewLockfile()
+	if fresh.Outdated() {
+		t.Error("expected a new lockfile to use the current format")
+	}
+	fresh.Salt = "ab"
+	if fresh.Hash("K", "v") == legacyHash("ab", "K", "v") {
+		t.Error("expected the current format not to use the fast HMAC")
+	}
+}
+
+func legacyHash(salt, key, value string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(key + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseLockfile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":     "nope",
+		"newer format": `{"version": 99, "salt": "ab"}`,
+		"missing salt": `{"version": 1}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseLockfile([]byte(data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
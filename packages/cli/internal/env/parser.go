@@ -77,3 +77,59 @@ func Merge(vaultContent string, local, vault map[string]string) string {
 
 	return result
 }
+
+// Layer merges environments in order: a key in a later layer overrides the
+// same key in earlier ones
+func Layer(layers ...map[string]string) map[string]string {
+	result := make(map[string]string)
+	for _, layer := range layers {
+		for key, value := range layer {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// Format renders secrets as env file content, sorted by key
+func Format(secrets map[string]string) string {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key + "=" + quoteValue(secrets[key]) + "\n")
+	}
+	return b.String()
+}
+
+// ReplaceValues rewrites the values of the given keys in env content, keeping
+// every other line as is. Values are quoted the same way the vault does.
+func ReplaceValues(content string, values map[string]string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		idx := strings.Index(trimmed, "=")
+		if idx == -1 {
+			continue
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		if value, ok := values[key]; ok {
+			lines[i] = key + "=" + quoteValue(value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quoteValue quotes a value when it holds spaces, newlines or quotes
+func quoteValue(value string) string {
+	if strings.ContainsAny(value, " \n\"") {
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return value
+}
@@ -257,3 +257,35 @@ func TestMerge_PreservesVaultFormatting(t *testing.T) {
 		t.Errorf("Merge() = %q, want %q", result, expected)
 	}
 }
+
+func TestFormat(t *testing.T) {
+	secrets := map[string]string{"B": "two words", "A": "1", "C": ""}
+
+	result := Format(secrets)
+
+	expected := "A=1\nB=\"two words\"\nC=\n"
+	if result != expected {
+		t.Errorf("Format() = %q, want %q", result, expected)
+	}
+	if parsed := Parse(result); len(parsed) != 3 || parsed["B"] != "two words" {
+		t.Errorf("Parse(Format()) = %v", parsed)
+	}
+}
+
+func TestLayer(t *testing.T) {
+	base := map[string]string{"A": "base", "B": "base"}
+	dev := map[string]string{"B": "dev", "C": "dev"}
+	local := map[string]string{"C": "local"}
+
+	result := Layer(base, dev, local)
+
+	expected := map[string]string{"A": "base", "B": "dev", "C": "local"}
+	if len(result) != len(expected) {
+		t.Fatalf("Layer() = %v, want %v", result, expected)
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Layer()[%s] = %q, want %q", k, result[k], v)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RefScheme prefixes a value that points at a secret in another vault
+const RefScheme = "keyway://"
+
+// maxRefDepth bounds chains of references to references
+const maxRefDepth = 10
+
+// Ref is a parsed keyway://owner/repo/environment/KEY reference
+type Ref struct {
+	Repo        string
+	Environment string
+	Key         string
+}
+
+func (r Ref) String() string {
+	return RefScheme + r.Repo + "/" + r.Environment + "/" + r.Key
+}
+
+// IsRef reports whether a value is meant as a reference.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefScheme)
+}
+
+// ParseRef parses a reference. It fails for values that use the scheme but
+// are not of the form keyway://owner/repo/environment/KEY.
+func ParseRef(value string) (Ref, error) {
+	parts := strings.Split(strings.TrimPrefix(value, RefScheme), "/")
+	if !IsRef(value) || len(parts) != 4 {
+		return Ref{}, fmt.Errorf("invalid reference %q: expected %sowner/repo/environment/KEY", value, RefScheme)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return Ref{}, fmt.Errorf("invalid reference %q: expected %sowner/repo/environment/KEY", value, RefScheme)
+		}
+	}
+	return Ref{Repo: parts[0] + "/" + parts[1], Environment: parts[2], Key: parts[3]}, nil
+}
+
+// RefFetcher returns the secrets of an environment of another vault
+type RefFetcher func(repo, envName string) (map[string]string, error)
+
+// ResolveRefs returns a copy of secrets with every reference replaced by the
+// value it points at, following references to references. The secrets of
+// repo/envName itself are used as is, so references within the same
+// environment don't cost a request. Each referenced environment is fetched
+// once. The second return value lists the keys that were references.
+func ResolveRefs(repo, envName string, secrets map[string]string, fetch RefFetcher) (map[string]string, []string, error) {
+	r := &refResolver{
+		fetch:   fetch,
+		fetched: map[string]map[string]string{repo + "/" + envName: secrets},
+	}
+
+	resolved := make(map[string]string, len(secrets))
+	var refKeys []string
+	for _, key := range sortedKeys(secrets) {
+		value := secrets[key]
+		if !IsRef(value) {
+			resolved[key] = value
+			continue
+		}
+		self := Ref{Repo: repo, Environment: envName, Key: key}
+		v, err := r.resolve(value, []string{self.String()})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", key, err)
+		}
+		resolved[key] = v
+		refKeys = append(refKeys, key)
+	}
+	return resolved, refKeys, nil
+}
+
+type refResolver struct {
+	fetch   RefFetcher
+	fetched map[string]map[string]string
+}
+
+// resolve follows value until it is no longer a reference. path holds the
+// references already followed, to report cycles.
+func (r *refResolver) resolve(value string, path []string) (string, error) {
+	for IsRef(value) {
+		ref, err := ParseRef(value)
+		if err != nil {
+			return "", err
+		}
+		for _, seen := range path {
+			if seen == ref.String() {
+				return "", fmt.Errorf("reference cycle: %s", strings.Join(append(path, ref.String()), " -> "))
+			}
+		}
+		if len(path) > maxRefDepth {
+			return "", fmt.Errorf("too many nested references (max %d)", maxRefDepth)
+		}
+		path = append(path, ref.String())
+
+		scope := ref.Repo + "/" + ref.Environment
+		secrets, ok := r.fetched[scope]
+		if !ok {
+			secrets, err = r.fetch(ref.Repo, ref.Environment)
+			if err != nil {
+				return "", fmt.Errorf("cannot resolve %s: %w", ref, err)
+			}
+			r.fetched[scope] = secrets
+		}
+
+		next, ok := secrets[ref.Key]
+		if !ok {
+			return "", fmt.Errorf("%s not found", ref)
+		}
+		value = next
+	}
+	return value, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,126 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeVaults serves secrets by "owner/repo/env" and counts fetches
+type fakeVaults struct {
+	envs    map[string]map[string]string
+	fetches int
+}
+
+func (f *fakeVaults) fetch(repo, envName string) (map[string]string, error) {
+	f.fetches++
+	if repo == "acme/private" {
+		return nil, errors.New("you don't have access to acme/private")
+	}
+	return f.envs[repo+"/"+envName], nil
+}
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("keyway://acme/shared-infra/production/SENTRY_DSN")
+	if err != nil {
+		t.Fatalf("ParseRef failed: %v", err)
+	}
+	want := Ref{Repo: "acme/shared-infra", Environment: "production", Key: "SENTRY_DSN"}
+	if ref != want {
+		t.Errorf("got %+v, want %+v", ref, want)
+	}
+	if ref.String() != "keyway://acme/shared-infra/production/SENTRY_DSN" {
+		t.Errorf("unexpected String(): %s", ref.String())
+	}
+
+	for _, bad := range []string{"keyway://acme/shared/KEY", "keyway://acme//production/KEY", "keyway://a/b/c/d/e", "https://x"} {
+		if _, err := ParseRef(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestResolveRefs(t *testing.T) {
+	vaults := &fakeVaults{envs: map[string]map[string]string{
+		"acme/shared/production": {
+			"SENTRY_DSN": "https://sentry",
+			"DB_URL":     "keyway://acme/db/production/URL",
+			"TOKEN":      "t",
+		},
+		"acme/db/production": {"URL": "postgres://db"},
+	}}
+
+	secrets := map[string]string{
+		"PLAIN":   "x",
+		"SENTRY":  "keyway://acme/shared/production/SENTRY_DSN",
+		"DB":      "keyway://acme/shared/production/DB_URL",
+		"TOKEN_A": "keyway://acme/shared/production/TOKEN",
+		"ALIAS":   "keyway://owner/repo/development/PLAIN",
+	}
+
+	resolved, refKeys, err := ResolveRefs("owner/repo", "development", secrets, vaults.fetch)
+	if err != nil {
+		t.Fatalf("ResolveRefs failed: %v", err)
+	}
+
+	want := map[string]string{
+		"PLAIN":   "x",
+		"SENTRY":  "https://sentry",
+		"DB":      "postgres://db",
+		"TOKEN_A": "t",
+		"ALIAS":   "x",
+	}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Errorf("got %v, want %v", resolved, want)
+	}
+	if !reflect.DeepEqual(refKeys, []string{"ALIAS", "DB", "SENTRY", "TOKEN_A"}) {
+		t.Errorf("refKeys = %v", refKeys)
+	}
+	if vaults.fetches != 2 {
+		t.Errorf("expected each referenced environment to be fetched once, got %d fetches", vaults.fetches)
+	}
+	if secrets["SENTRY"] != "keyway://acme/shared/production/SENTRY_DSN" {
+		t.Error("expected the input map to be left untouched")
+	}
+}
+
+func TestResolveRefs_Errors(t *testing.T) {
+	vaults := &fakeVaults{envs: map[string]map[string]string{
+		"acme/a/production": {"X": "keyway://acme/b/production/Y"},
+		"acme/b/production": {"Y": "keyway://acme/a/production/X"},
+	}}
+
+	tests := []struct {
+		name    string
+		secrets map[string]string
+		want    string
+	}{
+		{"cycle across vaults", map[string]string{"K": "keyway://acme/a/production/X"}, "reference cycle"},
+		{"self reference", map[string]string{"K": "keyway://owner/repo/development/K"}, "reference cycle"},
+		{"no access", map[string]string{"K": "keyway://acme/private/production/X"}, "don't have access to acme/private"},
+		{"missing key", map[string]string{"K": "keyway://acme/b/production/NOPE"}, "keyway://acme/b/production/NOPE not found"},
+		{"malformed", map[string]string{"K": "keyway://acme/b"}, "invalid reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ResolveRefs("owner/repo", "development", tt.secrets, vaults.fetch)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if err != nil && !strings.HasPrefix(err.Error(), "K: ") {
+				t.Errorf("expected the error to name the key, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReplaceValues(t *testing.T) {
+	content := "# comment\nA=keyway://x/y/z/A\nB=keep\nC=\"keyway://x/y/z/C\""
+	got := ReplaceValues(content, map[string]string{"A": "plain", "C": "has space"})
+	want := "# comment\nA=plain\nB=keep\nC=\"has space\""
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+// Package generate creates random secret values from a short spec, so they
+// can go straight to the vault without being typed or pasted.
+package generate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const alphanumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// Companion is an extra secret stored next to the generated one, under the
+// same key with Suffix appended (e.g. the public half of a keypair)
+type Companion struct {
+	Suffix string
+	Value  string
+}
+
+// Result is a generated secret
+type Result struct {
+	Value       string
+	Companions  []Companion
+	Description string
+}
+
+// Specs lists the supported generators with their syntax, for help output
+var Specs = []string{
+	"alnum[:length]   random letters and digits (default 32)",
+	"hex[:bytes]      random bytes, hex encoded (default 32)",
+	"base64[:bytes]   random bytes, base64 encoded (default 32)",
+	"uuid             random UUID (v4)",
+	"rsa[:bits]       RSA keypair, PEM (default 2048)",
+	"ed25519          Ed25519 keypair, PEM",
+	"bcrypt[:cost]    bcrypt hash of a random password (default cost 12)",
+}
+
+// Generate creates a secret from a spec such as "alnum:40", "uuid" or "rsa:4096".
+//
+// Keypairs store the private key as the value and the public key as a
+// "_PUBLIC" companion; bcrypt stores the hash as the value and the password as
+// a "_PASSWORD" companion. PEM blocks are kept on a single line with literal
+// \n separators, since env files hold one value per line.
+func Generate(spec string) (*Result, error) {
+	kind, arg, hasArg := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+
+	param := func(def, min, max int) (int, error) {
+		if !hasArg {
+			return def, nil
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("invalid %s parameter %q: must be a number between %d and %d", kind, arg, min, max)
+		}
+		return n, nil
+	}
+
+	switch kind {
+	case "alnum", "alphanumeric":
+		n, err := param(32, 8, 1024)
+		if err != nil {
+			return nil, err
+		}
+		value, err := randomString(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Value: value, Description: fmt.Sprintf("%d random alphanumeric characters", n)}, nil
+
+	case "hex":
+		n, err := param(32, 8, 512)
+		if err != nil {
+			return nil, err
+		}
+		b, err := randomBytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Value: hex.EncodeToString(b), Description: fmt.Sprintf("%d random bytes (hex)", n)}, nil
+
+	case "base64":
+		n, err := param(32, 8, 512)
+		if err != nil {
+			return nil, err
+		}
+		b, err := randomBytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Value: base64.StdEncoding.EncodeToString(b), Description: fmt.Sprintf("%d random bytes (base64)", n)}, nil
+
+	case "uuid":
+		if hasArg {
+			return nil, fmt.Errorf("uuid takes no parameter")
+		}
+		return &Result{Value: uuid.New().String(), Description: "random UUID"}, nil
+
+	case "rsa":
+		bits, err := param(2048, 2048, 8192)
+		if err != nil {
+			return nil, err
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		return keypair(key, &key.PublicKey, fmt.Sprintf("RSA %d keypair", bits))
+
+	case "ed25519":
+		if hasArg {
+			return nil, fmt.Errorf("ed25519 takes no parameter")
+		}
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		return keypair(priv, pub, "Ed25519 keypair")
+
+	case "bcrypt":
+		cost, err := param(12, bcrypt.MinCost, bcrypt.MaxCost)
+		if err != nil {
+			return nil, err
+		}
+		password, err := randomString(24)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		return &Result{
+			Value:       string(hash),
+			Companions:  []Companion{{Suffix: "_PASSWORD", Value: password}},
+			Description: fmt.Sprintf("bcrypt hash (cost %d) of a random password", cost),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown generator %q - supported: alnum, hex, base64, uuid, rsa, ed25519, bcrypt", kind)
+}
+
+// randomString returns n characters drawn uniformly from alphanumeric
+func randomString(n int) (string, error) {
+	max := big.NewInt(int64(len(alphanumeric)))
+	var sb strings.Builder
+	sb.Grow(n)
+	for i := 0; i < n; i++ {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random value: %w", err)
+		}
+		sb.WriteByte(alphanumeric[idx.Int64()])
+	}
+	return sb.String(), nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return b, nil
+}
+
+// keypair encodes a private key (PKCS#8) and its public key (PKIX) as
+// single-line PEM
+func keypair(priv, pub interface{}, description string) (*Result, error) {
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	return &Result{
+		Value:       singleLinePEM("PRIVATE KEY", privDER),
+		Companions:  []Companion{{Suffix: "_PUBLIC", Value: singleLinePEM("PUBLIC KEY", pubDER)}},
+		Description: description,
+	}, nil
+}
+
+func singleLinePEM(blockType string, der []byte) string {
+	encoded := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	return strings.ReplaceAll(strings.TrimRight(string(encoded), "\n"), "\n", `\n`)
+}
@@ -0,0 +1,133 @@
+package generate
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func mustGenerate(t *testing.T, spec string) *Result {
+	t.Helper()
+	r, err := Generate(spec)
+	if err != nil {
+		t.Fatalf("Generate(%q) failed: %v", spec, err)
+	}
+	return r
+}
+
+// parsePEM undoes the single-line encoding
+func parsePEM(t *testing.T, value string) *pem.Block {
+	t.Helper()
+	if strings.Contains(value, "\n") {
+		t.Fatalf("expected a single-line PEM, got %q", value)
+	}
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(value, `\n`, "\n")))
+	if block == nil {
+		t.Fatalf("invalid PEM: %q", value)
+	}
+	return block
+}
+
+func TestGenerate_Alnum(t *testing.T) {
+	r := mustGenerate(t, "alnum")
+	if !regexp.MustCompile(`^[A-Za-z0-9]{32}$`).MatchString(r.Value) {
+		t.Errorf("unexpected value %q", r.Value)
+	}
+	if got := mustGenerate(t, "alnum:48").Value; len(got) != 48 {
+		t.Errorf("expected 48 characters, got %d", len(got))
+	}
+	if mustGenerate(t, "alnum").Value == r.Value {
+		t.Error("expected different values on each call")
+	}
+}
+
+func TestGenerate_HexAndBase64(t *testing.T) {
+	b, err := hex.DecodeString(mustGenerate(t, "hex:16").Value)
+	if err != nil || len(b) != 16 {
+		t.Errorf("expected 16 hex-encoded bytes, got %d (%v)", len(b), err)
+	}
+	b, err = base64.StdEncoding.DecodeString(mustGenerate(t, "BASE64").Value)
+	if err != nil || len(b) != 32 {
+		t.Errorf("expected 32 base64-encoded bytes, got %d (%v)", len(b), err)
+	}
+}
+
+func TestGenerate_UUID(t *testing.T) {
+	if _, err := uuid.Parse(mustGenerate(t, "uuid").Value); err != nil {
+		t.Errorf("invalid UUID: %v", err)
+	}
+}
+
+func TestGenerate_RSA(t *testing.T) {
+	r := mustGenerate(t, "rsa")
+	key, err := x509.ParsePKCS8PrivateKey(parsePEM(t, r.Value).Bytes)
+	if err != nil {
+		t.Fatalf("invalid private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok || rsaKey.N.BitLen() != 2048 {
+		t.Fatalf("expected a 2048-bit RSA key, got %T", key)
+	}
+
+	if len(r.Companions) != 1 || r.Companions[0].Suffix != "_PUBLIC" {
+		t.Fatalf("expected a _PUBLIC companion, got %+v", r.Companions)
+	}
+	pub, err := x509.ParsePKIXPublicKey(parsePEM(t, r.Companions[0].Value).Bytes)
+	if err != nil {
+		t.Fatalf("invalid public key: %v", err)
+	}
+	if !rsaKey.PublicKey.Equal(pub) {
+		t.Error("public key does not match the private key")
+	}
+}
+
+func TestGenerate_Ed25519(t *testing.T) {
+	r := mustGenerate(t, "ed25519")
+	key, err := x509.ParsePKCS8PrivateKey(parsePEM(t, r.Value).Bytes)
+	if err != nil {
+		t.Fatalf("invalid private key: %v", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		t.Fatalf("expected an Ed25519 key, got %T", key)
+	}
+	pub, err := x509.ParsePKIXPublicKey(parsePEM(t, r.Companions[0].Value).Bytes)
+	if err != nil {
+		t.Fatalf("invalid public key: %v", err)
+	}
+	if !priv.Public().(ed25519.PublicKey).Equal(pub) {
+		t.Error("public key does not match the private key")
+	}
+}
+
+func TestGenerate_Bcrypt(t *testing.T) {
+	r := mustGenerate(t, "bcrypt:4")
+	if len(r.Companions) != 1 || r.Companions[0].Suffix != "_PASSWORD" {
+		t.Fatalf("expected a _PASSWORD companion, got %+v", r.Companions)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(r.Value), []byte(r.Companions[0].Value)); err != nil {
+		t.Errorf("hash does not match password: %v", err)
+	}
+	if cost, _ := bcrypt.Cost([]byte(r.Value)); cost != 4 {
+		t.Errorf("expected cost 4, got %d", cost)
+	}
+}
+
+func TestGenerate_InvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"", "nope", "alnum:abc", "alnum:2", "hex:0", "uuid:4", "rsa:1024", "ed25519:1", "bcrypt:99"} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := Generate(spec); err == nil {
+				t.Errorf("expected error for %q", spec)
+			}
+		})
+	}
+}
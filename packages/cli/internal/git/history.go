@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Commit identifies the commit that introduced a blob
+type Commit struct {
+	SHA    string
+	Author string
+	// Date is the author date, in RFC 3339 format
+	Date string
+}
+
+// Blob is a version of a file, as added or modified by a commit
+type Blob struct {
+	SHA    string
+	Path   string
+	Commit Commit
+}
+
+// HistoryBlobs lists the blobs that the history of HEAD added or modified
+// under dir, oldest first. Each blob is listed once, with the first commit
+// that introduced it. since limits the history to the commits after a
+// revision or, when it is not one, a date ("2 weeks ago", "2024-01-01").
+// It also returns how many commits were walked.
+func HistoryBlobs(dir, since string) (int, []Blob, error) {
+	rng := []string{"HEAD"}
+	if since != "" {
+		verify := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", since+"^{commit}")
+		if verify.Run() == nil {
+			rng = []string{since + "..HEAD"}
+		} else {
+			rng = []string{"--since=" + since, "HEAD"}
+		}
+	}
+
+	// -m lists the changes of merge commits too, against each parent
+	args := []string{"-C", dir, "-c", "core.quotePath=false", "log", "--reverse", "-m", "--raw", "--no-abbrev", "--no-renames",
+		"--format=%x01%H%x02%an <%ae>%x02%aI"}
+	args = append(append(args, rng...), "--", ".")
+	cmd := exec.Command("git", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, nil, err
+	}
+
+	commits, blobs := parseRawLog(out)
+	if err := cmd.Wait(); err != nil {
+		return 0, nil, fmt.Errorf("git log failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return commits, blobs, nil
+}
+
+// parseRawLog reads the output of HistoryBlobs' git log: a header line per
+// commit, then a line per changed file, like
+// ":100644 100644 <old> <new> M\tpath"
+func parseRawLog(r io.Reader) (int, []Blob) {
+	var blobs []Blob
+	seenCommits := map[string]bool{}
+	seenBlobs := map[string]bool{}
+	var commit Commit
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\x01"):
+			parts := strings.SplitN(line[1:], "\x02", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			commit = Commit{SHA: parts[0], Author: parts[1], Date: parts[2]}
+			seenCommits[commit.SHA] = true
+		case strings.HasPrefix(line, ":"):
+			meta, path, ok := strings.Cut(line[1:], "\t")
+			fields := strings.Fields(meta)
+			if !ok || len(fields) < 5 {
+				continue
+			}
+			mode, sha := fields[1], fields[3]
+			// Skip deletions, symlinks and submodules
+			if !strings.HasPrefix(mode, "100") || seenBlobs[sha] {
+				continue
+			}
+			seenBlobs[sha] = true
+			blobs = append(blobs, Blob{SHA: sha, Path: path, Commit: commit})
+		}
+	}
+	return len(seenCommits), blobs
+}
+
+// BlobReader reads the content of blobs through a single git process
+type BlobReader struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+// NewBlobReader starts reading blobs from the repository at dir
+func NewBlobReader(dir string) (*BlobReader, error) {
+	cmd := exec.Command("git", "-C", dir, "cat-file", "--batch")
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &BlobReader{cmd: cmd, in: in, out: bufio.NewReader(out)}, nil
+}
+
+// Read returns the content of a blob, or nil when it is larger than max bytes
+func (r *BlobReader) Read(sha string, max int64) ([]byte, error) {
+	if _, err := fmt.Fprintln(r.in, sha); err != nil {
+		return nil, err
+	}
+	header, err := r.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	// "<sha> <type> <size>", or "<sha> missing"
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("blob %s not found", sha)
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected header %q", strings.TrimSpace(header))
+	}
+
+	// The content is followed by a newline
+	if size > max {
+		_, err := r.out.Discard(int(size) + 1)
+		return nil, err
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(r.out, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// Close stops the git process
+func (r *BlobReader) Close() error {
+	r.in.Close()
+	return r.cmd.Wait()
+}
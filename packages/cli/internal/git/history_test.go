@@ -0,0 +1,111 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository in a temporary directory and returns a
+// function that runs git in it
+func gitRepo(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+			"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git %v failed: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q")
+	return dir, run
+}
+
+func TestHistoryBlobs(t *testing.T) {
+	dir, run := gitRepo(t)
+	write := func(name, content string) {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	write("a.txt", "one\n")
+	write("b.txt", "one\n") // Same blob as a.txt
+	run("add", "-A")
+	run("commit", "-qm", "first")
+	first := run("rev-parse", "HEAD")
+
+	write("a.txt", "two\n")
+	run("commit", "-qam", "second")
+	second := run("rev-parse", "HEAD")
+
+	run("rm", "-q", "a.txt")
+	run("commit", "-qm", "third")
+
+	commits, blobs, err := HistoryBlobs(dir, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if commits != 3 {
+		t.Errorf("expected 3 commits, got %d", commits)
+	}
+	if len(blobs) != 2 {
+		t.Fatalf("expected each blob once, got %+v", blobs)
+	}
+	if blobs[0].Path != "a.txt" || blobs[0].Commit.SHA != first || blobs[0].Commit.Author != "Alice <alice@example.com>" {
+		t.Errorf("unexpected first blob %+v", blobs[0])
+	}
+	if blobs[1].Path != "a.txt" || blobs[1].Commit.SHA != second {
+		t.Errorf("unexpected second blob %+v", blobs[1])
+	}
+
+	reader, err := NewBlobReader(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if data, err := reader.Read(blobs[1].SHA, 1024); err != nil || string(data) != "two\n" {
+		t.Errorf("expected the content of the blob, got %q, %v", data, err)
+	}
+	if data, err := reader.Read(blobs[0].SHA, 2); err != nil || data != nil {
+		t.Errorf("expected a blob over the limit to be skipped, got %q, %v", data, err)
+	}
+	// The reader is still in sync after skipping
+	if data, err := reader.Read(blobs[1].SHA, 1024); err != nil || string(data) != "two\n" {
+		t.Errorf("expected the content of the blob, got %q, %v", data, err)
+	}
+
+	commits, blobs, err = HistoryBlobs(dir, first)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if commits != 2 || len(blobs) != 1 || blobs[0].Commit.SHA != second {
+		t.Errorf("expected only the commits after the first, got %d commits and %+v", commits, blobs)
+	}
+}
+
+func TestParseRawLog(t *testing.T) {
+	log := "\x01c1\x02Alice <a@example.com>\x022024-01-01T00:00:00Z\n" +
+		"\n" +
+		":000000 100644 0000000000000000000000000000000000000000 1111111111111111111111111111111111111111 A\tsrc/app.js\n" +
+		":000000 120000 0000000000000000000000000000000000000000 2222222222222222222222222222222222222222 A\tlink\n" +
+		":000000 160000 0000000000000000000000000000000000000000 3333333333333333333333333333333333333333 A\tvendor/lib\n" +
+		"\x01c2\x02Bob <b@example.com>\x022024-01-02T00:00:00Z\n" +
+		":100644 000000 1111111111111111111111111111111111111111 0000000000000000000000000000000000000000 D\tsrc/app.js\n" +
+		":100644 100755 4444444444444444444444444444444444444444 1111111111111111111111111111111111111111 M\tbin/app file.js\n"
+
+	commits, blobs := parseRawLog(strings.NewReader(log))
+
+	if commits != 2 {
+		t.Errorf("expected 2 commits, got %d", commits)
+	}
+	if len(blobs) != 1 || blobs[0].Path != "src/app.js" || blobs[0].Commit.SHA != "c1" {
+		t.Errorf("expected only the regular file, once, got %+v", blobs)
+	}
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// AddedLine is a line that the staged changes add to a file
+type AddedLine struct {
+	Path string
+	// Line is the line number in the staged file
+	Line int
+	Text string
+}
+
+// StagedAddedLines returns the lines that the staged changes under dir add,
+// which is what the next commit brings in. Removed lines, unchanged lines
+// and binary files are left out.
+func StagedAddedLines(dir string) ([]AddedLine, error) {
+	cmd := exec.Command("git", "-C", dir, "-c", "core.quotePath=false",
+		"diff", "--cached", "-U0", "--no-color", "--no-ext-diff", "--diff-filter=d", "--", ".")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	lines := parseAddedLines(out)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git diff failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return lines, nil
+}
+
+// parseAddedLines reads the added lines of a diff with no context lines
+func parseAddedLines(r io.Reader) []AddedLine {
+	var lines []AddedLine
+	var path string
+	var next int
+	inHunk := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			path, inHunk = "", false
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			path = strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")
+			if path == "/dev/null" {
+				path = ""
+			} else {
+				path = strings.TrimPrefix(path, "b/")
+			}
+		case strings.HasPrefix(line, "@@ "):
+			// "@@ -12,3 +14,5 @@": the added lines start at line 14
+			inHunk = false
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				continue
+			}
+			start, _, _ := strings.Cut(fields[2][1:], ",")
+			n, err := strconv.Atoi(start)
+			if err != nil {
+				continue
+			}
+			next, inHunk = n, true
+		case inHunk && path != "" && strings.HasPrefix(line, "+"):
+			lines = append(lines, AddedLine{Path: path, Line: next, Text: line[1:]})
+			next++
+		}
+	}
+	return lines
+}
+
+// HooksDir returns the directory of the hooks of the repository at dir,
+// which core.hooksPath may have moved
+func HooksDir(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository")
+	}
+	hooks := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
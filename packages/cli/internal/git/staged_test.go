@@ -0,0 +1,68 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAddedLines(t *testing.T) {
+	diff := `diff --git a/app.js b/app.js
+index 1111111..2222222 100644
+--- a/app.js
++++ b/app.js
+@@ -2,0 +3,2 @@ const a = 1
++const b = 2
+++++ not a header
+@@ -10 +11,0 @@
+-removed
+diff --git a/new file.txt b/new file.txt
+new file mode 100644
+--- /dev/null
++++ b/new file.txt	
+@@ -0,0 +1 @@
++hello
+diff --git a/logo.png b/logo.png
+Binary files /dev/null and b/logo.png differ
+`
+	got := parseAddedLines(strings.NewReader(diff))
+	want := []AddedLine{
+		{Path: "app.js", Line: 3, Text: "const b = 2"},
+		{Path: "app.js", Line: 4, Text: "+++ not a header"},
+		{Path: "new file.txt", Line: 1, Text: "hello"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestStagedAddedLines(t *testing.T) {
+	dir, run := gitRepo(t)
+	write := func(name, content string) {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	write("a.txt", "one\ntwo\n")
+	run("add", "-A")
+	run("commit", "-qm", "first")
+
+	write("a.txt", "one\nchanged\nthree\n")
+	write("b.txt", "new\n")
+	write("unstaged.txt", "ignored\n")
+	run("add", "a.txt", "b.txt")
+
+	lines, err := StagedAddedLines(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []AddedLine{
+		{Path: "a.txt", Line: 2, Text: "changed"},
+		{Path: "a.txt", Line: 3, Text: "three"},
+		{Path: "b.txt", Line: 1, Text: "new"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %+v\nwant %+v", lines, want)
+	}
+}
@@ -0,0 +1,69 @@
+package injector
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvFileVar is set to the path the secrets are served from with EnvPipe
+const EnvFileVar = "KEYWAY_ENV_FILE"
+
+// envFileFlagAt returns where to add --env-file to docker and compose
+// commands, after the subcommand so that an --env-file given by the user still
+// wins, or -1 for other commands. raw reports that the reader is docker run,
+// which takes values literally.
+func envFileFlagAt(command string, args []string) (at int, raw bool) {
+	switch strings.TrimSuffix(filepath.Base(command), ".exe") {
+	case "docker":
+		switch {
+		case len(args) > 0 && (args[0] == "run" || args[0] == "create"):
+			return 1, true
+		case len(args) > 1 && args[0] == "container" && (args[1] == "run" || args[1] == "create"):
+			return 2, true
+		case len(args) > 0 && args[0] == "compose":
+			return 1, false
+		}
+	case "docker-compose":
+		return 0, false
+	}
+	return -1, false
+}
+
+// withEnvFile returns args with --env-file path inserted at index at
+func withEnvFile(args []string, at int, path string) []string {
+	out := make([]string, 0, len(args)+2)
+	out = append(out, args[:at]...)
+	out = append(out, "--env-file", path)
+	return append(out, args[at:]...)
+}
+
+// formatEnvFile renders secrets as an env file. Values are quoted when needed
+// so that neither compose nor a shell sourcing the file expands anything in
+// them: single quoted, or double quoted with \ " $ and ` escaped when they
+// contain a single quote. Raw files hold KEY=value lines for docker run, which
+// doesn't unquote.
+func formatEnvFile(secrets map[string]string, raw bool) (string, error) {
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := secrets[k]
+		switch {
+		case raw && strings.ContainsAny(v, "\r\n"):
+			return "", fmt.Errorf("%s spans several lines, which docker --env-file can't pass", k)
+		case raw || !strings.ContainsAny(v, " \t\r\n#$'\"\\`"):
+		case !strings.Contains(v, "'"):
+			v = "'" + v + "'"
+		default:
+			v = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(v) + `"`
+		}
+		b.WriteString(k + "=" + v + "\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,77 @@
+package injector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvFileFlagAt(t *testing.T) {
+	tests := []struct {
+		command string
+		args    string
+		want    string
+		raw     bool
+	}{
+		{"docker", "run --rm app", "run --env-file P --rm app", true},
+		{"/usr/bin/docker", "container create app", "container create --env-file P app", true},
+		{"docker", "compose up -d", "compose --env-file P up -d", false},
+		{"docker-compose", "up", "--env-file P up", false},
+		{"docker", "ps", "ps", false},
+		{"npm", "run dev", "run dev", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command+" "+tt.args, func(t *testing.T) {
+			args := strings.Fields(tt.args)
+			at, raw := envFileFlagAt(tt.command, args)
+			if at >= 0 {
+				args = withEnvFile(args, at, "P")
+			}
+			if got := strings.Join(args, " "); got != tt.want || raw != tt.raw {
+				t.Errorf("got %q raw=%v, want %q raw=%v", got, raw, tt.want, tt.raw)
+			}
+		})
+	}
+}
+
+func TestFormatEnvFile(t *testing.T) {
+	secrets := map[string]string{
+		"PLAIN":  "abc123",
+		"SPACES": "hello world",
+		"DOLLAR": "pa$$word",
+		"QUOTE":  `it's "quoted"`,
+	}
+
+	got, err := formatEnvFile(secrets, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "DOLLAR='pa$$word'\nPLAIN=abc123\nQUOTE=\"it's \\\"quoted\\\"\"\nSPACES='hello world'\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	raw, err := formatEnvFile(secrets, true)
+	if err != nil || !strings.Contains(raw, "DOLLAR=pa$$word\n") || !strings.Contains(raw, "SPACES=hello world\n") {
+		t.Errorf("expected literal values, got %q, %v", raw, err)
+	}
+	if _, err := formatEnvFile(map[string]string{"PEM": "a\nb"}, true); err == nil {
+		t.Error("expected error for a multi-line value in a raw file")
+	}
+}
+
+func TestPipeSecrets_OnlyPathInEnvironment(t *testing.T) {
+	env, args, pipe, err := pipeSecrets("docker", []string{"run", "app"}, map[string]string{"API_KEY": "secret"})
+	if err != nil {
+		t.Skipf("env pipes unavailable: %v", err)
+	}
+	defer pipe.stop()
+
+	if !reflect.DeepEqual(env, map[string]string{EnvFileVar: pipe.path}) {
+		t.Errorf("expected only %s in the environment, got %v", EnvFileVar, env)
+	}
+	if !reflect.DeepEqual(args, []string{"run", "--env-file", pipe.path, "app"}) {
+		t.Errorf("got args %v", args)
+	}
+}
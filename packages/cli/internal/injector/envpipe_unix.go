@@ -0,0 +1,88 @@
+//go:build !windows
+
+package injector
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// envPipe serves an env file from a named pipe. A pipe can only be read
+// once, and compose reads an env file once per service that uses it, so each
+// reader gets a pipe of its own: once a reader has opened it, a new one takes
+// its place at the same path. The content never reaches a disk.
+type envPipe struct {
+	dir     string
+	path    string
+	content []byte
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// serveEnvPipe creates the pipe in a private directory and starts serving it
+func serveEnvPipe(content string) (*envPipe, error) {
+	dir, err := privateTempDir()
+	if err != nil {
+		return nil, err
+	}
+	p := &envPipe{
+		dir:     dir,
+		path:    filepath.Join(dir, "env"),
+		content: []byte(content),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if err := syscall.Mkfifo(p.path, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	go p.serve()
+	return p, nil
+}
+
+// serve writes the content to every reader until stop
+func (p *envPipe) serve() {
+	defer close(p.stopped)
+	for {
+		// Blocks until a reader opens the pipe
+		f, err := os.OpenFile(p.path, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		select {
+		case <-p.done:
+			f.Close()
+			return
+		default:
+		}
+		// Readers that come next open a new pipe instead of sharing this one
+		if err := p.replace(); err != nil {
+			f.Close()
+			return
+		}
+		// A reader that stops early is not an error
+		_, _ = f.Write(p.content)
+		f.Close()
+	}
+}
+
+// replace puts a new pipe at the path
+func (p *envPipe) replace() error {
+	next := p.path + ".next"
+	if err := syscall.Mkfifo(next, 0600); err != nil {
+		return err
+	}
+	return os.Rename(next, p.path)
+}
+
+// stop stops serving and removes the pipe
+func (p *envPipe) stop() {
+	close(p.done)
+	// Open the pipe as a reader to release a pending open in serve
+	if r, err := os.OpenFile(p.path, os.O_RDONLY|syscall.O_NONBLOCK, 0); err == nil {
+		<-p.stopped
+		r.Close()
+	}
+	_ = os.RemoveAll(p.dir)
+}
@@ -0,0 +1,55 @@
+//go:build !windows
+
+package injector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnvPipe_ServesEveryReader(t *testing.T) {
+	p, err := serveEnvPipe("API_KEY=secret\n")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		data, err := os.ReadFile(p.path)
+		if err != nil || string(data) != "API_KEY=secret\n" {
+			t.Fatalf("read %d: got %q, %v", i, data, err)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		p.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop hung")
+	}
+	if _, err := os.Stat(p.dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", p.dir, err)
+	}
+}
+
+func TestRunCommandWatched_EnvPipe(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	secrets := map[string]string{"API_KEY": "secret", "TRICKY": `pa$$ 'w"ord`}
+	// Read the pipe twice, then source it like a shell script would
+	script := `cat "$KEYWAY_ENV_FILE" > /dev/null; set -a; . "$KEYWAY_ENV_FILE"; printf '%s|%s' "$API_KEY" "$TRICKY" > "$1"`
+
+	err := RunCommandWatched("sh", []string{"-c", script, "sh", out}, secrets, Options{EnvPipe: true}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	if string(data) != `secret|pa$$ 'w"ord` {
+		t.Errorf("got %q", data)
+	}
+}
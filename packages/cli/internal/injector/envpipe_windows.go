@@ -0,0 +1,18 @@
+//go:build windows
+
+package injector
+
+import "errors"
+
+// envPipe is not supported on Windows
+type envPipe struct {
+	path string
+}
+
+// serveEnvPipe is not supported on Windows, which has no named pipes in the
+// file system
+func serveEnvPipe(content string) (*envPipe, error) {
+	return nil, errors.New("env pipes are not supported on Windows")
+}
+
+func (p *envPipe) stop() {}
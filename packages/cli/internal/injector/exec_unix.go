@@ -0,0 +1,27 @@
+//go:build !windows
+
+package injector
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// Exec replaces the current process with the command, secrets injected into
+// its environment. The command keeps keyway's PID and receives signals
+// directly. It only returns if the command can't be started.
+func Exec(command string, args []string, secrets map[string]string, opts Options) error {
+	if err := checkExecOptions(opts); err != nil {
+		return err
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	env := childEnv(secrets, opts)
+	if err := syscall.Exec(path, append([]string{command}, args...), env); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	return nil
+}
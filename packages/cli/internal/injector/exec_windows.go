@@ -0,0 +1,10 @@
+//go:build windows
+
+package injector
+
+import "errors"
+
+// Exec is not supported on Windows, which can't replace a running process
+func Exec(command string, args []string, secrets map[string]string, opts Options) error {
+	return errors.New("exec mode is not supported on Windows")
+}
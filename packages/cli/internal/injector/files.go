@@ -0,0 +1,92 @@
+package injector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FileSecret asks for a secret to be written to a file instead of being
+// passed in the environment, for tools that read credentials from a path
+type FileSecret struct {
+	// Key is the secret to write
+	Key string
+	// Var is set to the path of the file
+	Var string
+}
+
+// ParseFileSecret parses a KEY:VAR spec. Without :VAR, KEY itself is set to
+// the path of the file.
+func ParseFileSecret(spec string) (FileSecret, error) {
+	key, variable, found := strings.Cut(spec, ":")
+	key, variable = strings.TrimSpace(key), strings.TrimSpace(variable)
+	if !found {
+		variable = key
+	}
+	// The key names a file in the private directory: it can't leave it
+	if key == "" || variable == "" || strings.ContainsAny(key+variable, "=/\\") || key == "." || key == ".." {
+		return FileSecret{}, fmt.Errorf("invalid file secret %q, expected KEY:VAR", spec)
+	}
+	return FileSecret{Key: key, Var: variable}, nil
+}
+
+// writeFileSecrets writes the requested secrets to 0600 files in a private
+// directory, preferably in memory (tmpfs). It returns the secrets to put in
+// the environment, where each written key is replaced by its variable holding
+// the path, and a cleanup function that removes the files.
+func writeFileSecrets(secrets map[string]string, files []FileSecret) (map[string]string, func(), error) {
+	if len(files) == 0 {
+		return secrets, func() {}, nil
+	}
+
+	dir, err := privateTempDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a directory for file secrets: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	out := make(map[string]string, len(secrets))
+	for k, v := range secrets {
+		out[k] = v
+	}
+	for _, f := range files {
+		value, ok := secrets[f.Key]
+		if !ok {
+			cleanup()
+			return nil, nil, fmt.Errorf("%s is not in the vault", f.Key)
+		}
+		path := filepath.Join(dir, f.Key)
+		if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write %s: %w", f.Key, err)
+		}
+		delete(out, f.Key)
+		out[f.Var] = path
+	}
+	return out, cleanup, nil
+}
+
+// privateTempDir creates a directory only the current user can read, in
+// memory when the system offers it so that secrets never reach a disk
+func privateTempDir() (string, error) {
+	var bases []string
+	if runtime.GOOS == "linux" {
+		bases = append(bases, "/dev/shm")
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		bases = append(bases, dir)
+	}
+	bases = append(bases, os.TempDir())
+
+	var err error
+	for _, base := range bases {
+		var dir string
+		// MkdirTemp creates the directory with 0700 permissions
+		if dir, err = os.MkdirTemp(base, "keyway-"); err == nil {
+			return dir, nil
+		}
+	}
+	return "", err
+}
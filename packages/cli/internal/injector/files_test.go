@@ -0,0 +1,98 @@
+package injector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFileSecret(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    FileSecret
+		wantErr bool
+	}{
+		{"GCP_SA_JSON:GOOGLE_APPLICATION_CREDENTIALS", FileSecret{Key: "GCP_SA_JSON", Var: "GOOGLE_APPLICATION_CREDENTIALS"}, false},
+		{"KUBECONFIG", FileSecret{Key: "KUBECONFIG", Var: "KUBECONFIG"}, false},
+		{"KEY:", FileSecret{}, true},
+		{":VAR", FileSecret{}, true},
+		{"../KEY:VAR", FileSecret{}, true},
+		{"KEY:A=B", FileSecret{}, true},
+		{"..", FileSecret{}, true},
+		{"..:VAR", FileSecret{}, true},
+		{".:VAR", FileSecret{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseFileSecret(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFileSecrets(t *testing.T) {
+	secrets := map[string]string{"SA_JSON": `{"type":"service_account"}`, "OTHER": "1"}
+
+	env, cleanup, err := writeFileSecrets(secrets, []FileSecret{{Key: "SA_JSON", Var: "CREDENTIALS"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	path := env["CREDENTIALS"]
+	if _, ok := env["SA_JSON"]; ok {
+		t.Error("expected the value to be removed from the environment")
+	}
+	if env["OTHER"] != "1" || secrets["SA_JSON"] == "" {
+		t.Errorf("unexpected environment %v or modified secrets %v", env, secrets)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != secrets["SA_JSON"] {
+		t.Fatalf("got %q, %v", data, err)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 file, got %o", fi.Mode().Perm())
+	}
+	if fi, _ := os.Stat(filepath.Dir(path)); fi.Mode().Perm() != 0700 {
+		t.Errorf("expected 0700 directory, got %o", fi.Mode().Perm())
+	}
+
+	cleanup()
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("expected the directory to be removed, got %v", err)
+	}
+}
+
+func TestWriteFileSecrets_MissingKey(t *testing.T) {
+	if _, _, err := writeFileSecrets(map[string]string{"A": "1"}, []FileSecret{{Key: "B", Var: "B"}}); err == nil {
+		t.Fatal("expected error for a missing secret")
+	}
+}
+
+func TestRunCommandWatched_FileSecretRemovedOnExit(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	script := `echo "path=$CREDENTIALS" > "$OUT"; echo "content=$(cat "$CREDENTIALS")" >> "$OUT"; echo "env=$SA_JSON" >> "$OUT"`
+	secrets := map[string]string{"OUT": out, "SA_JSON": "hunter22"}
+
+	opts := Options{FileSecrets: []FileSecret{{Key: "SA_JSON", Var: "CREDENTIALS"}}}
+	if err := RunCommandWatched("sh", []string{"-c", script}, secrets, opts, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || lines[1] != "content=hunter22" || lines[2] != "env=" {
+		t.Fatalf("unexpected output %q", data)
+	}
+	path := strings.TrimPrefix(lines[0], "path=")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", path, err)
+	}
+}
@@ -5,14 +5,151 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
 	"syscall"
+	"time"
 )
 
 // signals is defined in signals_unix.go and signals_windows.go
 
+// Options controls how RunCommand builds the child environment
+type Options struct {
+	// NoOverride keeps variables already set in the parent environment
+	// instead of replacing them with secrets of the same name
+	NoOverride bool
+	// CleanEnv passes only the variables in InheritedEnv, and those matching
+	// Keep, from the parent environment instead of all of them
+	CleanEnv bool
+	// Keep lists globs of extra variables to inherit with CleanEnv
+	Keep []string
+	// MaskOutput replaces secret values in the child's stdout and stderr
+	MaskOutput bool
+	// FileSecrets are written to temporary files instead of the environment
+	FileSecrets []FileSecret
+	// EnvPipe serves the secrets as an env file from a named pipe instead of
+	// the environment. The child gets its path in EnvFileVar, and docker and
+	// compose commands get --env-file.
+	EnvPipe bool
+	// GracePeriod is how long RunCommandWatched waits for the child to exit
+	// after SIGTERM before killing it (defaults to DefaultGracePeriod)
+	GracePeriod time.Duration
+}
+
+// DefaultGracePeriod is how long a child gets to shut down before a restart
+// kills it
+const DefaultGracePeriod = 10 * time.Second
+
+// ExitError reports that the command ran and exited with a non-zero status.
+// Callers exit with Code to pass it on, the way a shell would.
+type ExitError struct {
+	// Code is the exit status, or 128 plus the signal number when the
+	// command was killed by a signal
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.Code)
+}
+
 // RunCommand executes a command with the provided secrets injected into the environment.
-// It handles signal forwarding and exit code propagation.
-func RunCommand(command string, args []string, secrets map[string]string) error {
+// It handles signal forwarding, and returns an *ExitError when the command fails.
+func RunCommand(command string, args []string, secrets map[string]string, opts Options) error {
+	// Handle signals
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+	defer signal.Stop(sigs)
+
+	c, err := startChild(command, args, secrets, opts)
+	if err != nil {
+		return err
+	}
+
+	// Forward signals to the child's process group
+	go func() {
+		for sig := range sigs {
+			_ = signalGroup(c.cmd.Process, sig)
+		}
+	}()
+
+	// Wait for the command to finish
+	<-c.exited
+	return exitWith(c.err)
+}
+
+// RunCommandWatched runs a command like RunCommand, and restarts it each time
+// new secrets are received on updates: the child gets SIGTERM, then SIGKILL if
+// it is still running after the grace period, and is started again with the
+// new environment. It returns when the child exits on its own.
+func RunCommandWatched(command string, args []string, secrets map[string]string, opts Options, updates <-chan map[string]string) error {
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+	defer signal.Stop(sigs)
+
+	c, err := startChild(command, args, secrets, opts)
+	if err != nil {
+		return err
+	}
+
+	interrupted := false
+	for {
+		select {
+		case <-c.exited:
+			return exitWith(c.err)
+		case sig := <-sigs:
+			// The child is asked to stop: don't start another one
+			interrupted = true
+			_ = signalGroup(c.cmd.Process, sig)
+		case next, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if interrupted {
+				continue
+			}
+			c.stop(grace)
+			if c, err = startChild(command, args, next, opts); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// child is a started command
+type child struct {
+	cmd    *exec.Cmd
+	mask   *outputMask
+	exited chan struct{}
+	err    error
+}
+
+// startChild starts a command with secrets in its environment
+func startChild(command string, args []string, secrets map[string]string, opts Options) (*child, error) {
+	envSecrets, cleanup, err := writeFileSecrets(secrets, opts.FileSecrets)
+	if err != nil {
+		return nil, err
+	}
+	if opts.EnvPipe {
+		var pipe *envPipe
+		if envSecrets, args, pipe, err = pipeSecrets(command, args, envSecrets); err != nil {
+			cleanup()
+			return nil, err
+		}
+		removeFiles := cleanup
+		cleanup = func() {
+			pipe.stop()
+			removeFiles()
+		}
+	}
+
 	// Prepare the command
 	cmd := exec.Command(command, args...)
 
@@ -21,47 +158,181 @@ func RunCommand(command string, args []string, secrets map[string]string) error
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	// Build the environment
-	// Start with current environment
-	currentEnv := os.Environ()
-	newEnv := make([]string, 0, len(currentEnv)+len(secrets))
-	newEnv = append(newEnv, currentEnv...)
+	cmd.Env = childEnv(envSecrets, opts)
+	setProcessGroup(cmd)
 
-	// Append secrets
-	for k, v := range secrets {
-		newEnv = append(newEnv, fmt.Sprintf("%s=%s", k, v))
+	c := &child{cmd: cmd, exited: make(chan struct{})}
+	if opts.MaskOutput {
+		c.mask = maskOutput(cmd, secrets)
 	}
-	cmd.Env = newEnv
-
-	// Handle signals
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, signals...)
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start command: %w", err)
+		if c.mask != nil {
+			c.mask.finish()
+		}
+		cleanup()
+		restoreForeground(cmd)
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	if c.mask != nil {
+		c.mask.started()
 	}
 
-	// Forward signals to the child process
 	go func() {
-		for sig := range sigs {
-			if cmd.Process != nil {
-				_ = cmd.Process.Signal(sig)
-			}
+		c.err = cmd.Wait()
+		restoreForeground(cmd)
+		if c.mask != nil {
+			c.mask.finish()
 		}
+		// Signals are forwarded to the child rather than ending keyway, so
+		// the files are removed whichever way the child stops
+		cleanup()
+		close(c.exited)
 	}()
+	return c, nil
+}
 
-	// Wait for the command to finish
-	err := cmd.Wait()
+// pipeSecrets starts serving secrets from an env pipe, and returns what the
+// child gets instead: the path of the pipe in its environment and, for docker
+// and compose, in an --env-file flag
+func pipeSecrets(command string, args []string, secrets map[string]string) (map[string]string, []string, *envPipe, error) {
+	at, raw := envFileFlagAt(command, args)
+	content, err := formatEnvFile(secrets, raw)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pipe, err := serveEnvPipe(content)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create the env pipe: %w", err)
+	}
+	if at >= 0 {
+		args = withEnvFile(args, at, pipe.path)
+	}
+	return map[string]string{EnvFileVar: pipe.path}, args, pipe, nil
+}
+
+// stop terminates the child's process group gracefully, killing it after
+// grace
+func (c *child) stop(grace time.Duration) {
+	if err := signalGroup(c.cmd.Process, syscall.SIGTERM); err != nil {
+		// SIGTERM is not supported on Windows
+		_ = c.cmd.Process.Kill()
+	}
+	select {
+	case <-c.exited:
+	case <-time.After(grace):
+		if err := signalGroup(c.cmd.Process, syscall.SIGKILL); err != nil {
+			_ = c.cmd.Process.Kill()
+		}
+		<-c.exited
+	}
+}
+
+// exitWith turns the error of a child that failed into an *ExitError
+func exitWith(err error) error {
+	exitError, ok := err.(*exec.ExitError)
+	if !ok {
+		return err
+	}
+	code := exitError.ExitCode()
+	if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	if code <= 0 {
+		code = 1
+	}
+	return &ExitError{Code: code}
+}
+
+// childEnv builds the environment of the command
+func childEnv(secrets map[string]string, opts Options) []string {
+	base := os.Environ()
+	if opts.CleanEnv {
+		base = CleanEnv(base, opts.Keep)
+	}
+	return BuildEnv(base, secrets, opts.NoOverride)
+}
+
+// checkExecOptions rejects options that need keyway to outlive the command
+func checkExecOptions(opts Options) error {
+	if opts.MaskOutput {
+		return fmt.Errorf("output can't be masked in exec mode")
+	}
+	if len(opts.FileSecrets) > 0 {
+		return fmt.Errorf("file secrets can't be removed in exec mode")
+	}
+	if opts.EnvPipe {
+		return fmt.Errorf("the env pipe can't be served in exec mode")
+	}
+	return nil
+}
+
+// BuildEnv combines a parent environment with secrets so that every variable
+// appears exactly once, instead of leaving duplicates for the child's libc to
+// resolve. Secrets replace parent variables of the same name, unless
+// noOverride is set, in which case the parent's value is kept.
+func BuildEnv(base []string, secrets map[string]string, noOverride bool) []string {
+	inBase := make(map[string]bool, len(base))
+	for _, kv := range base {
+		if k, _, ok := strings.Cut(kv, "="); ok {
+			inBase[envKey(k)] = true
+		}
+	}
+	inSecrets := make(map[string]bool, len(secrets))
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		if noOverride && inBase[envKey(k)] {
+			continue
+		}
+		inSecrets[envKey(k)] = true
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
-	// Handle exit code
-	if exitError, ok := err.(*exec.ExitError); ok {
-		// The process exited with a non-zero status
-		if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-			os.Exit(status.ExitStatus())
+	env := make([]string, 0, len(base)+len(keys))
+	for _, kv := range base {
+		k, _, _ := strings.Cut(kv, "=")
+		if !inSecrets[envKey(k)] {
+			env = append(env, kv)
 		}
-		os.Exit(1)
 	}
+	for _, k := range keys {
+		env = append(env, k+"="+secrets[k])
+	}
+	return env
+}
 
-	return err
+// InheritedEnv lists the variables a clean environment keeps from the parent:
+// what programs need to find executables, locate the user and print text
+var InheritedEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "COLORTERM", "LANG", "LC_*", "TZ", "TMPDIR",
+	// Windows can't start most programs without these
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// CleanEnv keeps the variables of base listed in InheritedEnv or matching one
+// of the keep globs
+func CleanEnv(base []string, keep []string) []string {
+	patterns := append(append([]string{}, InheritedEnv...), keep...)
+	env := make([]string, 0, len(patterns))
+	for _, kv := range base {
+		k, _, _ := strings.Cut(kv, "=")
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(envKey(pattern), envKey(k)); ok {
+				env = append(env, kv)
+				break
+			}
+		}
+	}
+	return env
+}
+
+// envKey normalizes a variable name for comparison; names are case
+// insensitive on Windows
+func envKey(name string) string {
+	if runtime.GOOS == "windows" {
+		return strings.ToUpper(name)
+	}
+	return name
 }
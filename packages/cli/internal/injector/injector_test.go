@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Helper to capture output of the RunCommand function is hard because it wires to os.Stdout.
@@ -25,7 +27,7 @@ func TestRunCommand(t *testing.T) {
 	}
 	
 	// We use "env" command to print environment variables
-	err := RunCommand("env", []string{}, secrets)
+	err := RunCommand("env", []string{}, secrets, Options{})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "RunCommand failed: %v\n", err)
 		os.Exit(1)
@@ -193,3 +195,116 @@ func TestRunCommand_NonexistentCommand(t *testing.T) {
 		t.Error("Expected error for non-existent command")
 	}
 }
+
+func TestBuildEnv(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "API_URL=http://parent", "HOME=/home/me"}
+	secrets := map[string]string{"API_URL": "http://vault", "TOKEN": "abc"}
+
+	tests := []struct {
+		name       string
+		noOverride bool
+		want       []string
+	}{
+		{
+			name: "secrets win",
+			want: []string{"PATH=/usr/bin", "HOME=/home/me", "API_URL=http://vault", "TOKEN=abc"},
+		},
+		{
+			name:       "parent wins",
+			noOverride: true,
+			want:       []string{"PATH=/usr/bin", "API_URL=http://parent", "HOME=/home/me", "TOKEN=abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildEnv(base, secrets, tt.noOverride)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("BuildEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommandWatched_Restarts(t *testing.T) {
+	tests := []struct {
+		name     string
+		trap     string
+		wantTerm bool
+	}{
+		{"graceful", `trap 'echo term >> "$OUT"; exit 0' TERM`, true},
+		{"killed after grace period", `trap '' TERM`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			script := `echo "v=$VAL" >> "$OUT"; [ "$VAL" = 2 ] && exit 0; ` + tt.trap + `; while :; do sleep 0.05; done`
+
+			updates := make(chan map[string]string)
+			go func() {
+				waitForFile(t, out, "v=1")
+				updates <- map[string]string{"OUT": out, "VAL": "2"}
+			}()
+
+			opts := Options{GracePeriod: 200 * time.Millisecond}
+			err := RunCommandWatched("sh", []string{"-c", script}, map[string]string{"OUT": out, "VAL": "1"}, opts, updates)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			data, _ := os.ReadFile(out)
+			if !strings.HasPrefix(string(data), "v=1\n") || !strings.HasSuffix(string(data), "v=2\n") {
+				t.Errorf("expected the command to run twice, got %q", data)
+			}
+			if strings.Contains(string(data), "term") != tt.wantTerm {
+				t.Errorf("unexpected shutdown, got %q", data)
+			}
+		})
+	}
+}
+
+// waitForFile waits until a file contains want
+func waitForFile(t *testing.T, path, want string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), want) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("timed out waiting for %q in %s", want, path)
+}
+
+func TestCleanEnv(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "AWS_SECRET_ACCESS_KEY=x", "NODE_ENV=test", "NODE_OPTIONS=--inspect"}
+
+	got := CleanEnv(base, []string{"NODE_ENV"})
+	want := []string{"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "NODE_ENV=test"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRunCommand_ExitError(t *testing.T) {
+	tests := []struct {
+		script string
+		want   int
+	}{
+		{"exit 3", 3},
+		{"kill -9 $$", 128 + 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			err := RunCommand("sh", []string{"-c", tt.script}, nil, Options{})
+			exitErr, ok := err.(*ExitError)
+			if !ok {
+				t.Fatalf("expected *ExitError, got %v", err)
+			}
+			if exitErr.Code != tt.want {
+				t.Errorf("got code %d, want %d", exitErr.Code, tt.want)
+			}
+		})
+	}
+}
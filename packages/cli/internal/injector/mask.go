@@ -0,0 +1,234 @@
+package injector
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minMaskLength is the shortest secret value that is masked. Shorter values
+// ("true", a port number) would hide unrelated output all over the place.
+const minMaskLength = 6
+
+// maskPatterns returns every form of a secret value to look for in output,
+// mapped to its replacement. Values are matched as is and in common
+// encodings: base64 (standard and URL-safe, with and without padding), URL
+// escaping and JSON string escaping.
+func maskPatterns(secrets map[string]string) map[string]string {
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	patterns := make(map[string]string)
+	for _, k := range keys {
+		value := secrets[k]
+		if len(value) < minMaskLength {
+			continue
+		}
+		replacement := "***" + k + "***"
+		quoted, _ := json.Marshal(value)
+		forms := []string{
+			value,
+			base64.StdEncoding.EncodeToString([]byte(value)),
+			base64.RawStdEncoding.EncodeToString([]byte(value)),
+			base64.URLEncoding.EncodeToString([]byte(value)),
+			base64.RawURLEncoding.EncodeToString([]byte(value)),
+			url.QueryEscape(value),
+			url.PathEscape(value),
+			strings.Trim(string(quoted), `"`),
+		}
+		for _, form := range forms {
+			// The first key with a given value names it
+			if _, ok := patterns[form]; !ok {
+				patterns[form] = replacement
+			}
+		}
+	}
+	return patterns
+}
+
+// MaskWriter replaces secret values in a stream before passing it on. A
+// value split across writes is still caught: only the bytes at the end of a
+// write that could start a secret are held back, until the next write or
+// Close.
+type MaskWriter struct {
+	dst      io.Writer
+	replacer *strings.Replacer
+	patterns []string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewMaskWriter returns a writer that masks the values of secrets written to
+// dst
+func NewMaskWriter(dst io.Writer, secrets map[string]string) *MaskWriter {
+	byForm := maskPatterns(secrets)
+	patterns := make([]string, 0, len(byForm))
+	for form := range byForm {
+		patterns = append(patterns, form)
+	}
+	// Longest first, so a value wins over any shorter value it contains
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	oldnew := make([]string, 0, 2*len(patterns))
+	for _, p := range patterns {
+		oldnew = append(oldnew, p, byForm[p])
+	}
+	return &MaskWriter{
+		dst:      dst,
+		replacer: strings.NewReplacer(oldnew...),
+		patterns: patterns,
+	}
+}
+
+// Write masks and forwards everything that can't be the start of a secret
+func (w *MaskWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	cut := len(w.buf) - w.partialMatch()
+	if cut > 0 {
+		if _, err := io.WriteString(w.dst, w.replacer.Replace(string(w.buf[:cut]))); err != nil {
+			return 0, err
+		}
+		w.buf = append(w.buf[:0], w.buf[cut:]...)
+	}
+	return len(p), nil
+}
+
+// Close flushes what is held back
+func (w *MaskWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.dst, w.replacer.Replace(string(w.buf)))
+	w.buf = w.buf[:0]
+	return err
+}
+
+// partialMatch returns the length of the longest end of the buffer that is
+// the beginning of a pattern
+func (w *MaskWriter) partialMatch() int {
+	buf := string(w.buf)
+	longest := 0
+	for _, p := range w.patterns {
+		n := len(p) - 1
+		if n > len(buf) {
+			n = len(buf)
+		}
+		for ; n > longest; n-- {
+			if strings.HasSuffix(buf, p[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// outputMask routes a child's stdout and stderr through MaskWriters. When an
+// output is a terminal, the child writes to a pseudo-terminal instead of a
+// pipe, so it keeps colors, line buffering and terminal size.
+type outputMask struct {
+	writers []*MaskWriter
+	ptmx    *os.File
+	tty     *os.File
+	done    chan struct{}
+	stop    func()
+}
+
+// maskOutput sets up cmd's stdout and stderr so that secret values are masked
+func maskOutput(cmd *exec.Cmd, secrets map[string]string) *outputMask {
+	m := &outputMask{}
+	outTTY, errTTY := isTerminal(os.Stdout), isTerminal(os.Stderr)
+
+	if outTTY || errTTY {
+		ptmx, tty, err := openPTY()
+		if err == nil {
+			m.ptmx, m.tty = ptmx, tty
+			dst := os.Stdout
+			if !outTTY {
+				dst = os.Stderr
+			}
+			w := NewMaskWriter(dst, secrets)
+			m.writers = append(m.writers, w)
+			m.done = make(chan struct{})
+			go func() {
+				// Reading fails once the child and its children are gone
+				_, _ = io.Copy(w, ptmx)
+				close(m.done)
+			}()
+			m.stop = followTerminalSize(ptmx)
+		} else {
+			outTTY, errTTY = false, false
+		}
+	}
+
+	if outTTY {
+		cmd.Stdout = m.tty
+	} else {
+		w := NewMaskWriter(os.Stdout, secrets)
+		m.writers = append(m.writers, w)
+		cmd.Stdout = w
+	}
+	if errTTY {
+		cmd.Stderr = m.tty
+	} else {
+		w := NewMaskWriter(os.Stderr, secrets)
+		m.writers = append(m.writers, w)
+		cmd.Stderr = w
+	}
+	return m
+}
+
+// started releases the parent's copy of the terminal once the child has it
+func (m *outputMask) started() {
+	if m.tty != nil {
+		m.tty.Close()
+	}
+}
+
+// finish waits for the remaining output and flushes it
+func (m *outputMask) finish() {
+	if m.tty != nil {
+		m.tty.Close()
+	}
+	if m.done != nil {
+		<-m.done
+	}
+	if m.ptmx != nil {
+		m.ptmx.Close()
+	}
+	if m.stop != nil {
+		m.stop()
+	}
+	for _, w := range m.writers {
+		_ = w.Close()
+	}
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package injector
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// openPTY opens a pseudo-terminal sized like ours
+func openPTY() (ptmx, tty *os.File, err error) {
+	ptmx, tty, err = pty.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = pty.InheritSize(os.Stdin, ptmx)
+	return ptmx, tty, nil
+}
+
+// followTerminalSize resizes the pseudo-terminal when ours is resized
+func followTerminalSize(ptmx *os.File) (stop func()) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			_ = pty.InheritSize(os.Stdin, ptmx)
+		}
+	}()
+	return func() {
+		signal.Stop(winch)
+		close(winch)
+	}
+}
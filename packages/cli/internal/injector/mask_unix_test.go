@@ -0,0 +1,51 @@
+//go:build !windows
+
+package injector
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/creack/pty"
+)
+
+func TestRunCommand_MaskOutputKeepsTerminal(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(exe, "-test.run=TestRunCommand_MaskOutputProcess")
+	cmd.Env = append(os.Environ(), "GO_TEST_MASK_PROCESS=1")
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		t.Skipf("pseudo-terminals unavailable: %v", err)
+	}
+	defer ptmx.Close()
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, ptmx)
+		close(done)
+	}()
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("process failed: %v\n%s", err, out.String())
+	}
+	ptmx.Close()
+	<-done
+
+	if !strings.Contains(out.String(), "out ***MASK_TOKEN***") {
+		t.Errorf("expected masked output, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "tok_0123456789") {
+		t.Error("secret leaked")
+	}
+	if !strings.Contains(out.String(), "tty") {
+		t.Errorf("child should see a terminal, got %q", out.String())
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package injector
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY is not supported on Windows; masked output goes through pipes
+func openPTY() (ptmx, tty *os.File, err error) {
+	return nil, nil, errors.New("pseudo-terminals are not supported on Windows")
+}
+
+// followTerminalSize is a no-op on Windows
+func followTerminalSize(ptmx *os.File) (stop func()) {
+	return func() {}
+}
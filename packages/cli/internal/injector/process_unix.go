@@ -0,0 +1,52 @@
+//go:build !windows
+
+package injector
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setProcessGroup starts the child in its own process group, so that signals
+// reach it once and reach everything it spawns. When keyway runs in the
+// foreground of a terminal, the child's group takes the terminal over: Ctrl-C
+// goes straight to it, and it can read input without being stopped.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	fd := int(os.Stdin.Fd())
+	if pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP); err == nil && pgrp == syscall.Getpgrp() {
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = fd
+	}
+}
+
+// setBackgroundProcessGroup starts the child in its own process group, but
+// leaves the terminal to keyway
+func setBackgroundProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalGroup sends sig to the child's process group
+func signalGroup(p *os.Process, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return p.Signal(sig)
+	}
+	return syscall.Kill(-p.Pid, s)
+}
+
+// restoreForeground takes the terminal back from a child that had it. The
+// request would stop keyway with SIGTTOU while it is in the background, so
+// the signal is ignored meanwhile.
+func restoreForeground(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Foreground {
+		return
+	}
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(cmd.SysProcAttr.Ctty, unix.TIOCSPGRP, syscall.Getpgrp())
+}
@@ -0,0 +1,100 @@
+//go:build !windows
+
+package injector
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRunCommand_OwnProcessGroup(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	script := `echo $$ > "$OUT.tmp"; mv "$OUT.tmp" "$OUT"; i=0; while [ $i -lt 100 ] && [ ! -e "$OUT.done" ]; do sleep 0.05; i=$((i+1)); done`
+
+	done := make(chan int)
+	go func() {
+		waitForFile(t, out, "\n")
+		data, _ := os.ReadFile(out)
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		pgid, _ := syscall.Getpgid(pid)
+		_ = os.WriteFile(out+".done", nil, 0600)
+		done <- pgid
+		close(done)
+	}()
+
+	if err := RunCommand("sh", []string{"-c", script}, map[string]string{"OUT": out}, Options{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	pgid := <-done
+	data, _ := os.ReadFile(out)
+	if pid, _ := strconv.Atoi(strings.TrimSpace(string(data))); pgid != pid || pgid == syscall.Getpgrp() {
+		t.Errorf("expected the child to lead its own group, got pgid %d for pid %d", pgid, pid)
+	}
+}
+
+func TestRunCommand_SignalsReachGrandchildren(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	inner := filepath.Join(dir, "inner.sh")
+	// The outer shell doesn't trap TERM; only the group signal reaches inner
+	_ = os.WriteFile(inner, []byte(`trap 'echo inner >> "$OUT"; exit 0' TERM
+echo ready >> "$OUT"
+i=0; while [ $i -lt 100 ]; do sleep 0.05; i=$((i+1)); done
+`), 0700)
+
+	go func() {
+		waitForFile(t, out, "ready")
+		_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	}()
+
+	err := RunCommand("sh", []string{"-c", `sh "$INNER" & wait`}, map[string]string{"OUT": out, "INNER": inner}, Options{})
+	if _, ok := err.(*ExitError); !ok {
+		t.Fatalf("expected the outer shell to be terminated, got %v", err)
+	}
+	waitForFile(t, out, "inner")
+}
+
+func TestExecProcess(t *testing.T) {
+	if os.Getenv("GO_TEST_EXEC_PROCESS") != "1" {
+		return
+	}
+	err := Exec("sh", []string{"-c", `echo "pid=$$ secret=$EXEC_SECRET"`}, map[string]string{"EXEC_SECRET": "s3cret"}, Options{})
+	os.Stderr.WriteString(err.Error())
+	os.Exit(2)
+}
+
+func TestExec_ReplacesProcess(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(exe, "-test.run=TestExecProcess")
+	cmd.Env = append(os.Environ(), "GO_TEST_EXEC_PROCESS=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("process failed: %v\nstderr: %s", err, stderr.String())
+	}
+
+	want := "pid=" + strconv.Itoa(cmd.Process.Pid) + " secret=s3cret"
+	if strings.TrimSpace(stdout.String()) != want {
+		t.Errorf("got %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestExec_RejectsOptionsNeedingKeyway(t *testing.T) {
+	if err := Exec("true", nil, nil, Options{MaskOutput: true}); err == nil {
+		t.Error("expected error for masked output")
+	}
+	if err := Exec("true", nil, map[string]string{"A": "1"}, Options{FileSecrets: []FileSecret{{Key: "A", Var: "A"}}}); err == nil {
+		t.Error("expected error for file secrets")
+	}
+}
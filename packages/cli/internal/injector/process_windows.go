@@ -0,0 +1,24 @@
+//go:build windows
+
+package injector
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows: a child in a new process group
+// would stop receiving Ctrl-C from the console, which reaches every process
+// attached to it already
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// setBackgroundProcessGroup is a no-op on Windows, like setProcessGroup
+func setBackgroundProcessGroup(cmd *exec.Cmd) {}
+
+// signalGroup signals the child itself on Windows
+func signalGroup(p *os.Process, sig os.Signal) error {
+	return p.Signal(sig)
+}
+
+// restoreForeground is a no-op on Windows
+func restoreForeground(cmd *exec.Cmd) {}
@@ -0,0 +1,225 @@
+package injector
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Process is one of the commands RunProcesses runs side by side
+type Process struct {
+	// Name prefixes each line of the process's output
+	Name    string
+	Command string
+	Args    []string
+	// Secrets are injected into this process only
+	Secrets map[string]string
+}
+
+// prefixColors are the ANSI colors given to process names in turn
+var prefixColors = []string{"36", "33", "32", "35", "34", "31"}
+
+// RunProcesses starts processes side by side, each with its own secrets, and
+// interleaves their output line by line behind their names. They live and die
+// together: when one exits, or keyway gets a signal, the others are signalled
+// too, and killed once the grace period is over. It returns the outcome of
+// the process that exited first, as an *ExitError when it failed. After a
+// signal, a process that exits cleanly still makes it fail with 128 plus the
+// signal number, as a shell would.
+//
+// Only NoOverride, CleanEnv, Keep, MaskOutput and GracePeriod apply from opts.
+func RunProcesses(procs []Process, opts Options) error {
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+	defer signal.Stop(sigs)
+
+	width := len("keyway")
+	for _, p := range procs {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+	color := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	var stdoutMu, stderrMu sync.Mutex
+	label := func(name string, i int) string {
+		l := fmt.Sprintf("%-*s |", width, name)
+		if color {
+			l = "\x1b[" + prefixColors[i%len(prefixColors)] + "m" + l + "\x1b[0m"
+		}
+		return l + " "
+	}
+	notice := &prefixWriter{dst: os.Stdout, mu: &stdoutMu, prefix: label("keyway", len(procs))}
+
+	children := make([]*child, 0, len(procs))
+	stopAll := func(sig os.Signal) {
+		for _, c := range children {
+			select {
+			case <-c.exited:
+			default:
+				if err := signalGroup(c.cmd.Process, sig); err != nil {
+					_ = c.cmd.Process.Kill()
+				}
+			}
+		}
+	}
+
+	for i, p := range procs {
+		c, err := startProcess(p, opts,
+			&prefixWriter{dst: os.Stdout, mu: &stdoutMu, prefix: label(p.Name, i)},
+			&prefixWriter{dst: os.Stderr, mu: &stderrMu, prefix: label(p.Name, i)})
+		if err != nil {
+			stopAll(syscall.SIGTERM)
+			for _, c := range children {
+				<-c.exited
+			}
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		children = append(children, c)
+	}
+
+	exited := make(chan int, len(children))
+	for i, c := range children {
+		go func(i int, c *child) {
+			<-c.exited
+			exited <- i
+		}(i, c)
+	}
+
+	var result error
+	var received os.Signal
+	first := true
+	var deadline <-chan time.Time
+	for remaining := len(children); remaining > 0; {
+		select {
+		case i := <-exited:
+			remaining--
+			if !first {
+				continue
+			}
+			first = false
+			result = exitWith(children[i].err)
+			if sig, ok := received.(syscall.Signal); ok && result == nil {
+				result = &ExitError{Code: 128 + int(sig)}
+			}
+			if deadline != nil {
+				// Already stopping the others after a signal
+				continue
+			}
+			// The first process to exit takes the others down with it
+			status := "exited"
+			if exitErr, ok := result.(*ExitError); ok {
+				status = fmt.Sprintf("exited with status %d", exitErr.Code)
+			}
+			if remaining > 0 {
+				fmt.Fprintf(notice, "%s %s, stopping the others\n", procs[i].Name, status)
+			}
+			stopAll(syscall.SIGTERM)
+			deadline = time.After(grace)
+		case sig := <-sigs:
+			if received == nil {
+				received = sig
+			}
+			stopAll(sig)
+			if deadline == nil {
+				deadline = time.After(grace)
+			}
+		case <-deadline:
+			fmt.Fprintln(notice, "killing the processes still running")
+			stopAll(syscall.SIGKILL)
+			deadline = make(chan time.Time)
+		}
+	}
+	return result
+}
+
+// startProcess starts one of the processes of RunProcesses, writing its output
+// to stdout and stderr. Unlike startChild, it doesn't get our standard input
+// or take the terminal over: the processes share it, through keyway.
+func startProcess(p Process, opts Options, stdout, stderr *prefixWriter) (*child, error) {
+	cmd := exec.Command(p.Command, p.Args...)
+	outputs := []io.Writer{stdout, stderr}
+	var masks []*MaskWriter
+	if opts.MaskOutput {
+		for i, w := range outputs {
+			m := NewMaskWriter(w, p.Secrets)
+			masks = append(masks, m)
+			outputs[i] = m
+		}
+	}
+	cmd.Stdout, cmd.Stderr = outputs[0], outputs[1]
+	cmd.Env = childEnv(p.Secrets, opts)
+	setBackgroundProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	c := &child{cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		c.err = cmd.Wait()
+		for _, m := range masks {
+			_ = m.Close()
+		}
+		stdout.flush()
+		stderr.flush()
+		close(c.exited)
+	}()
+	return c, nil
+}
+
+// prefixWriter writes whole lines to dst, each behind prefix. Writers sharing
+// a destination share mu, so their lines don't mix.
+type prefixWriter struct {
+	dst    io.Writer
+	mu     *sync.Mutex
+	prefix string
+
+	buf []byte
+}
+
+// Write forwards the complete lines in p, holding back a last partial line
+// until it is completed or flushed
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	end := strings.LastIndexByte(string(w.buf), '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	if err := w.writeLines(string(w.buf[:end+1])); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf[:0], w.buf[end+1:]...)
+	return len(p), nil
+}
+
+// flush writes a partial line that is still held back
+func (w *prefixWriter) flush() {
+	if len(w.buf) > 0 {
+		_ = w.writeLines(string(w.buf) + "\n")
+		w.buf = w.buf[:0]
+	}
+}
+
+func (w *prefixWriter) writeLines(lines string) error {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(lines, "\n") {
+		if line != "" {
+			b.WriteString(w.prefix)
+			b.WriteString(line)
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.dst, b.String())
+	return err
+}
@@ -0,0 +1,118 @@
+//go:build !windows
+
+package injector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// captureOutput runs f with stdout and stderr sent to a file, and returns
+// what was written
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, out
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	f()
+	data, _ := os.ReadFile(out.Name())
+	return string(data)
+}
+
+func TestRunProcesses_OwnSecretsAndPrefixes(t *testing.T) {
+	procs := []Process{
+		{Name: "web", Command: "sh", Args: []string{"-c", `sleep 0.3; echo "web sees $WEB_KEY${WORKER_KEY}"; printf partial`}, Secrets: map[string]string{"WEB_KEY": "w1"}},
+		{Name: "worker", Command: "sh", Args: []string{"-c", `echo "worker sees $WORKER_KEY${WEB_KEY}" >&2; sleep 10`}, Secrets: map[string]string{"WORKER_KEY": "k2"}},
+	}
+
+	var err error
+	start := time.Now()
+	output := captureOutput(t, func() { err = RunProcesses(procs, Options{GracePeriod: time.Second}) })
+
+	if err != nil {
+		t.Fatalf("expected the first process's success, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("expected worker to be stopped when web exited")
+	}
+	for _, want := range []string{"web    | web sees w1\n", "web    | partial\n", "worker | worker sees k2\n", "keyway | web exited, stopping the others\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunProcesses_FirstFailureIsReturned(t *testing.T) {
+	procs := []Process{
+		{Name: "a", Command: "sh", Args: []string{"-c", "sleep 0.3; exit 3"}},
+		// Ignores SIGTERM, so it has to be killed after the grace period
+		{Name: "b", Command: "sh", Args: []string{"-c", `trap "" TERM; sleep 10`}},
+	}
+
+	var err error
+	output := captureOutput(t, func() { err = RunProcesses(procs, Options{GracePeriod: 200 * time.Millisecond}) })
+
+	exitErr, ok := err.(*ExitError)
+	if !ok || exitErr.Code != 3 {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+	if !strings.Contains(output, "killing the processes still running") {
+		t.Errorf("expected b to be killed, got:\n%s", output)
+	}
+}
+
+func TestRunProcesses_InterruptFailsWithSignalStatus(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	procs := []Process{
+		// Stops cleanly on SIGTERM
+		{Name: "web", Command: "sh", Args: []string{"-c", `trap 'exit 0' TERM; echo ready >> "$OUT"; while :; do sleep 0.05; done`}, Secrets: map[string]string{"OUT": out}},
+		{Name: "worker", Command: "sh", Args: []string{"-c", "sleep 10"}},
+	}
+
+	go func() {
+		waitForFile(t, out, "ready")
+		_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	}()
+
+	var err error
+	captureOutput(t, func() { err = RunProcesses(procs, Options{GracePeriod: time.Second}) })
+
+	exitErr, ok := err.(*ExitError)
+	if !ok || exitErr.Code != 128+int(syscall.SIGTERM) {
+		t.Fatalf("expected exit status %d after SIGTERM, got %v", 128+int(syscall.SIGTERM), err)
+	}
+}
+
+func TestRunProcesses_MaskOutput(t *testing.T) {
+	procs := []Process{
+		{Name: "web", Command: "sh", Args: []string{"-c", "echo token=$TOKEN"}, Secrets: map[string]string{"TOKEN": "s3cr3t-value"}},
+	}
+
+	output := captureOutput(t, func() { _ = RunProcesses(procs, Options{MaskOutput: true}) })
+
+	if strings.Contains(output, "s3cr3t-value") || !strings.Contains(output, "token=***TOKEN***") {
+		t.Errorf("expected the value to be masked, got:\n%s", output)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &prefixWriter{dst: &buf, mu: new(sync.Mutex), prefix: "p | "}
+	_, _ = w.Write([]byte("one\ntw"))
+	_, _ = w.Write([]byte("o\nthree"))
+	w.flush()
+
+	if got, want := buf.String(), "p | one\np | two\np | three\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
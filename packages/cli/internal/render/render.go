@@ -0,0 +1,75 @@
+// Package render fills text/template files with secrets, for tools that read
+// config files (YAML, TOML, JSON...) rather than environment variables.
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Render executes the template text with secrets as its data, so {{ .KEY }}
+// expands to a secret's value. Referencing a missing key is an error rather
+// than an empty string, to catch typos before they reach a config file; use
+// {{ get "KEY" }} for keys that may be absent.
+func Render(name, text string, secrets map[string]string) ([]byte, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(Funcs(secrets)).
+		Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, secrets); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Funcs returns the helper functions available to templates:
+//
+//	b64enc, b64dec   base64 encode/decode a value
+//	json             quote a value as a JSON string (also valid in YAML and TOML)
+//	default          use a fallback when a value is empty: {{ get "X" | default "y" }}
+//	get              look up a key, returning "" when it is missing
+//	unescape         turn literal \n into newlines (e.g. single-line PEM keys)
+func Funcs(secrets map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"b64dec": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return "", fmt.Errorf("b64dec: %w", err)
+			}
+			return string(b), nil
+		},
+		"json": func(s string) (string, error) {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(s); err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		},
+		"default": func(def, s string) string {
+			if s == "" {
+				return def
+			}
+			return s
+		},
+		"get": func(key string) string {
+			return secrets[key]
+		},
+		"unescape": func(s string) string {
+			return strings.ReplaceAll(s, `\n`, "\n")
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	secrets := map[string]string{
+		"DB_URL":   "postgres://u:p@db/app",
+		"PASSWORD": `a"b\c`,
+		"TOKEN":    "secret",
+		"EMPTY":    "",
+		"KEY":      `line1\nline2`,
+	}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"plain", "url: {{ .DB_URL }}", "url: postgres://u:p@db/app"},
+		{"json", "password: {{ .PASSWORD | json }}", `password: "a\"b\\c"`},
+		{"b64enc", "{{ .TOKEN | b64enc }}", "c2VjcmV0"},
+		{"b64dec", `{{ "c2VjcmV0" | b64dec }}`, "secret"},
+		{"default on empty", `{{ .EMPTY | default "x" }}`, "x"},
+		{"default on missing", `{{ get "NOPE" | default "info" }}`, "info"},
+		{"default keeps value", `{{ .TOKEN | default "x" }}`, "secret"},
+		{"unescape", "{{ .KEY | unescape }}", "line1\nline2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render("test", tt.tmpl, secrets)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"missing key", "{{ .NOPE }}", `no entry for key "NOPE"`},
+		{"parse error", "{{ .A ", "config.tmpl"},
+		{"bad base64", `{{ "%%%" | b64dec }}`, "b64dec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Render("config.tmpl", tt.tmpl, map[string]string{"A": "a"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import { cn } from '@/lib/utils'
 const actionLabels: Record<string, string> = {
   vault_created: 'created vault',
   vault_deleted: 'deleted vault',
+  vault_e2e_enabled: 'enabled end-to-end encryption for',
   secrets_pushed: 'pushed secrets',
   secrets_pulled: 'pulled secrets',
   secret_created: 'created',
@@ -24,6 +24,7 @@ class ActivityApiClient extends BaseApiClient {
       const categoryMap: Record<string, ActivityEvent['category']> = {
         vault_created: 'vaults',
         vault_deleted: 'vaults',
+        vault_e2e_enabled: 'vaults',
         environment_created: 'environments',
         environment_renamed: 'environments',
         environment_deleted: 'environments',
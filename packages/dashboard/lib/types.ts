@@ -94,6 +94,7 @@ export interface SecretVersion {
 export type ActivityAction =
   | 'vault_created'
   | 'vault_deleted'
+  | 'vault_e2e_enabled'
   | 'secrets_pushed'
   | 'secrets_pulled'
   | 'secret_created'